@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ondrovic/nexus-mods-scraper/pkg/nexus"
+)
+
+// authFlags holds the command-line flag values for the auth commands.
+type authFlags struct {
+	requestFlags
+}
+
+var (
+	// authCmd is a Cobra command grouping the session commands.
+	authCmd = &cobra.Command{}
+	// authStatusCmd is a Cobra command used for checking the session cookies.
+	authStatusCmd = &cobra.Command{}
+	// authOptions holds the flag values for the auth commands.
+	authOptions = authFlags{}
+	// timeNow is a variable that holds a reference to the function used to get the
+	// current time when checking cookie expiry.
+	timeNow = time.Now
+)
+
+// init initializes the auth command and its status subcommand, setting their usage,
+// description, and argument validation, and adds them to the root command.
+func init() {
+	authCmd = &cobra.Command{
+		Use:   "auth",
+		Short: "Check the session cookies",
+		Long:  "Check the session cookies saved by the extract command",
+	}
+
+	authStatusCmd = &cobra.Command{
+		Use:   "status [flags]",
+		Short: "Show the logged-in account and cookie expiry",
+		Long:  "Load the account page with the saved session cookies and show the logged-in username, whether the account is premium, and when the cookies expire",
+		Args:  cobra.NoArgs,
+		RunE:  runAuthStatus,
+	}
+
+	initAuthFlags(authStatusCmd)
+	authCmd.AddCommand(authStatusCmd)
+	RootCmd.AddCommand(authCmd)
+}
+
+// initAuthFlags registers the command-line flags for the auth status command, the
+// base URL, cookie directory and filename, rate limit, request timeout, and the
+// proxy, User-Agent, CA certificate and extra headers.
+func initAuthFlags(cmd *cobra.Command) {
+	registerRequestFlags(cmd, &authOptions.requestFlags)
+}
+
+// runAuthStatus checks the saved session cookies and prints the logged-in account
+// and the cookies' expiry. Returns an error if the cookies cannot be loaded, the
+// account page cannot be fetched, or the session is anonymous or expired.
+func runAuthStatus(cmd *cobra.Command, args []string) error {
+	scraper, err := authOptions.newScraper()
+	if err != nil {
+		return err
+	}
+
+	session, err := scraper.CheckSession(commandContext(cmd))
+	if err != nil {
+		return fmt.Errorf("error checking session: %w", err)
+	}
+	if err := saveSessionCookies(scraper, authOptions.CookieFile); err != nil {
+		return err
+	}
+
+	printSession(cmd.OutOrStdout(), session, timeNow())
+	return session.Validate(timeNow())
+}
+
+// printSession writes the account the session is logged in to, and the expiry of
+// each session cookie relative to now.
+func printSession(w io.Writer, session nexus.Session, now time.Time) {
+	switch {
+	case !session.LoggedIn:
+		fmt.Fprintln(w, "Not logged in")
+	case session.Account.Premium:
+		fmt.Fprintf(w, "Logged in as %s (premium)\n", session.Account.Username)
+	default:
+		fmt.Fprintf(w, "Logged in as %s\n", session.Account.Username)
+	}
+
+	for _, cookie := range session.Cookies {
+		switch {
+		case cookie.Expires.IsZero():
+			fmt.Fprintf(w, "Cookie %s: expiry unknown\n", cookie.Name)
+		case cookie.Expired(now):
+			fmt.Fprintf(w, "Cookie %s: expired %s\n", cookie.Name, cookie.Expires.Format(time.RFC3339))
+		default:
+			fmt.Fprintf(w, "Cookie %s: expires %s (in %s)\n", cookie.Name, cookie.Expires.Format(time.RFC3339), cookie.Expires.Sub(now).Round(time.Minute))
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ondrovic/nexus-mods-scraper/pkg/nexus"
+)
+
+// newSessionSite serves the account page only to the session with the given cookie
+// value, and refuses everyone else.
+func newSessionSite(t *testing.T, session string) *httptest.Server {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cookie, err := r.Cookie("nexusmods_session"); err != nil || cookie.Value != session {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`<div id="user-dropdown"><span class="username">Dovahkiin</span></div>`))
+	}))
+	t.Cleanup(site.Close)
+	return site
+}
+
+func TestRunAuthStatus_LoggedIn(t *testing.T) {
+	// Arrange
+	site := newSessionSite(t, "abc")
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "session-cookies.json"), []byte(`{"nexusmods_session":"abc"}`), 0644))
+	authOptions = authFlags{requestFlags{BaseUrl: site.URL, CookieDirectory: dir, CookieFile: "session-cookies.json"}}
+
+	cmd := &cobra.Command{}
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	// Act
+	err := runAuthStatus(cmd, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "Logged in as Dovahkiin\n")
+	assert.Contains(t, out.String(), "Cookie nexusmods_session: expiry unknown")
+}
+
+func TestRunAuthStatus_NotLoggedIn(t *testing.T) {
+	// Arrange
+	site := newSessionSite(t, "abc")
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "session-cookies.json"), []byte(`{"nexusmods_session":"stale"}`), 0644))
+	authOptions = authFlags{requestFlags{BaseUrl: site.URL, CookieDirectory: dir, CookieFile: "session-cookies.json"}}
+
+	cmd := &cobra.Command{}
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	// Act
+	err := runAuthStatus(cmd, nil)
+
+	// Assert
+	assert.ErrorIs(t, err, nexus.ErrNotLoggedIn)
+	assert.Contains(t, out.String(), "Not logged in")
+}
+
+func TestPrintSession(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	session := nexus.Session{
+		LoggedIn: true,
+		Account:  nexus.Account{Username: "Lydia", Premium: true},
+		Cookies: []nexus.CookieStatus{
+			{Name: "nexusmods_session", Expires: now.Add(90 * time.Minute)},
+			{Name: "nexusmods_session_refresh", Expires: now.Add(-time.Hour)},
+		},
+	}
+	var out bytes.Buffer
+
+	// Act
+	printSession(&out, session, now)
+
+	// Assert
+	assert.Equal(t, "Logged in as Lydia (premium)\n"+
+		"Cookie nexusmods_session: expires 2024-06-01T13:30:00Z (in 1h30m0s)\n"+
+		"Cookie nexusmods_session_refresh: expired 2024-06-01T11:00:00Z\n", out.String())
+}
@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/config"
+)
+
+var (
+	// configCmd is a Cobra command grouping the configuration commands.
+	configCmd = &cobra.Command{}
+	// configShowCmd is a Cobra command used for showing the effective configuration.
+	configShowCmd = &cobra.Command{}
+	// configFile is the configuration file given with the --config flag.
+	configFile string
+	// configPath is a variable that holds a reference to the function returning the
+	// path of the configuration file.
+	configPath = defaultConfigPath
+)
+
+// init initializes the config command and its show subcommand, setting their usage,
+// description, and argument validation, adds them to the root command, and registers
+// the --config flag on every command.
+func init() {
+	configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration",
+		Long:  "Inspect the configuration file, " + config.EnvPrefix + " environment variables and profile applied to each command's flags",
+	}
+
+	configShowCmd = &cobra.Command{
+		Use:   "show [command]",
+		Short: "Show the effective configuration",
+		Long:  "Show the effective value of every flag and where it comes from: flag, env, profile, config or default. Shows every command, or only the command given, such as scrape or auth status",
+		RunE:  runConfigShow,
+	}
+
+	configCmd.AddCommand(configShowCmd)
+	RootCmd.AddCommand(configCmd)
+	RootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Configuration file to read (default ~/.config/nexus-mods-scraper/config.yaml)")
+}
+
+// defaultConfigPath returns the configuration file given with --config, or by the
+// NMS_CONFIG environment variable, or the default configuration file.
+func defaultConfigPath() string {
+	if configFile != "" {
+		return configFile
+	}
+	if path := os.Getenv(config.EnvName("config")); path != "" {
+		return path
+	}
+	return config.DefaultPath()
+}
+
+// runConfigShow prints the effective flag values of the command given, or of every
+// command, and where each comes from. Returns an error if the command is unknown, or
+// the configuration file or profile cannot be read.
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	commands := settingsCommands(RootCmd)
+	if len(args) > 0 {
+		found, rest, err := RootCmd.Find(args)
+		if err != nil || len(rest) > 0 || found == RootCmd {
+			return fmt.Errorf("unknown command %q", strings.Join(args, " "))
+		}
+		commands = []*cobra.Command{found}
+	}
+
+	path := configPath()
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Config file: %s\n\n", path)
+	return printSettings(cmd.OutOrStdout(), commands, cfg)
+}
+
+// printSettings writes a table of the effective flag values of the commands and where
+// each comes from. Returns an error if a profile cannot be found or writing fails.
+func printSettings(w io.Writer, commands []*cobra.Command, cfg config.Config) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "COMMAND\tFLAG\tVALUE\tSOURCE")
+	for _, command := range commands {
+		settings, err := resolveSettings(command, cfg)
+		if err != nil {
+			return err
+		}
+		name := strings.Join(commandSection(command), " ")
+		for _, setting := range settings {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", name, setting.Name, setting.Value, setting.Source)
+		}
+	}
+	return tw.Flush()
+}
+
+// settingsCommands returns the runnable commands under cmd whose flags are set from
+// the configuration, in the order they are listed in help.
+func settingsCommands(cmd *cobra.Command) []*cobra.Command {
+	var commands []*cobra.Command
+	for _, child := range cmd.Commands() {
+		if !usesSettings(child) || child.Name() == "help" || child.Name() == "completion" {
+			continue
+		}
+		if child.Runnable() {
+			commands = append(commands, child)
+		}
+		commands = append(commands, settingsCommands(child)...)
+	}
+	return commands
+}
+
+// usesSettings reports whether the command's flags are set from the environment,
+// profile and configuration file. The profile and config commands manage those
+// themselves, so their flags are left as given.
+func usesSettings(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == profileCmd || c == configCmd {
+			return false
+		}
+	}
+	return true
+}
+
+// applySettings sets each flag of the command not given on the command line from its
+// NMS_ environment variable, the profile selected with --profile, or the
+// configuration file, in that order, before the command runs. Returns an error if
+// the configuration file or profile cannot be read, or a value is invalid.
+func applySettings(cmd *cobra.Command, args []string) error {
+	if !usesSettings(cmd) {
+		return nil
+	}
+
+	cfg, err := config.Load(configPath())
+	if err != nil {
+		return err
+	}
+	settings, err := resolveSettings(cmd, cfg)
+	if err != nil {
+		return err
+	}
+
+	for _, setting := range settings {
+		if setting.Source == config.SourceFlag || setting.Source == config.SourceDefault {
+			continue
+		}
+		if err := commandFlags(cmd).Set(setting.Name, setting.Value); err != nil {
+			return fmt.Errorf("invalid %s value for --%s: %w", setting.Source, setting.Name, err)
+		}
+	}
+	return nil
+}
+
+// resolveSettings returns the effective value of each of the command's flags and
+// where it comes from. Flags given on the command line win over the environment, which
+// wins over the profile, then the configuration file and then the flag's default.
+// Returns an error if the selected profile cannot be found.
+func resolveSettings(cmd *cobra.Command, cfg config.Config) ([]config.Setting, error) {
+	flags := commandFlags(cmd)
+	section := commandSection(cmd)
+
+	// The profile is itself a flag, so it is resolved before the values it gives
+	var profileValues map[string]string
+	if flag := flags.Lookup("profile"); flag != nil {
+		if name := resolveFlag(flag, section, cfg, nil).Value; name != "" {
+			profile, err := cfg.Profile(name)
+			if err != nil {
+				return nil, err
+			}
+			profileValues = profileFlagValues(cmd, profile)
+		}
+	}
+
+	var settings []config.Setting
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if flag.Name == "help" || flag.Name == "config" {
+			return
+		}
+		settings = append(settings, resolveFlag(flag, section, cfg, profileValues))
+	})
+	return settings, nil
+}
+
+// resolveFlag returns the effective value of the flag and where it comes from.
+func resolveFlag(flag *pflag.Flag, section []string, cfg config.Config, profileValues map[string]string) config.Setting {
+	if flag.Changed {
+		return config.Setting{Name: flag.Name, Value: flag.Value.String(), Source: config.SourceFlag}
+	}
+	if value, ok := os.LookupEnv(config.EnvName(flag.Name)); ok {
+		return config.Setting{Name: flag.Name, Value: value, Source: config.SourceEnv}
+	}
+	if value := profileValues[flag.Name]; value != "" {
+		return config.Setting{Name: flag.Name, Value: value, Source: config.SourceProfile}
+	}
+	if value, ok := cfg.Lookup(section, flag.Name); ok {
+		return config.Setting{Name: flag.Name, Value: value, Source: config.SourceConfig}
+	}
+	return config.Setting{Name: flag.Name, Value: flag.DefValue, Source: config.SourceDefault}
+}
+
+// commandFlags returns every flag of the command, including those inherited from its
+// parents.
+func commandFlags(cmd *cobra.Command) *pflag.FlagSet {
+	flags := cmd.Flags()
+	flags.AddFlagSet(cmd.InheritedFlags())
+	return flags
+}
+
+// commandSection returns the path of the command below the root, such as
+// ["auth", "status"], naming its section in the configuration file.
+func commandSection(cmd *cobra.Command) []string {
+	var section []string
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		section = append([]string{c.Name()}, section...)
+	}
+	return section
+}
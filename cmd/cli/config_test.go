@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/cli"
+)
+
+// newSettingsCommand returns a command named test, under a root with the --profile
+// flag, with flags like those of the scraping commands bound to flags.
+func newSettingsCommand(t *testing.T, flags *feedFlags, validCookies *[]string) *cobra.Command {
+	t.Helper()
+	t.Cleanup(func() { profileName = "" })
+
+	root := &cobra.Command{Use: "nexus-mods-scraper"}
+	root.PersistentFlags().StringVar(&profileName, "profile", "", "")
+	cmd := &cobra.Command{Use: "test", RunE: func(cmd *cobra.Command, args []string) error { return nil }}
+	cli.RegisterFlag(cmd, "base-url", "u", "https://nexusmods.com", "", &flags.BaseUrl)
+	cli.RegisterFlag(cmd, "cookie-directory", "d", "/default", "", &flags.CookieDirectory)
+	cli.RegisterFlag(cmd, "cookie-filename", "f", "session-cookies.json", "", &flags.CookieFile)
+	cli.RegisterFlag(cmd, "output-directory", "o", "/output", "", &flags.OutputDirectory)
+	cli.RegisterFlag(cmd, "rate-limit", "", time.Duration(0), "", &flags.RateLimit)
+	cli.RegisterFlag(cmd, "workers", "w", 4, "", &flags.Workers)
+	cli.RegisterFlag(cmd, "valid-cookie-names", "c", []string{"nexusmods_session"}, "", validCookies)
+	root.AddCommand(cmd)
+	return cmd
+}
+
+func TestApplySettings_Precedence(t *testing.T) {
+	// Arrange
+	path := useConfigFile(t)
+	require.NoError(t, os.WriteFile(path, []byte(`
+workers: 2
+cookie-directory: /top-level
+test:
+  output-directory: /config-output
+  cookie-directory: /config-cookies
+  valid-cookie-names: [a, b]
+profiles:
+  team:
+    base-url: https://profile.example
+    cookie-directory: /profile-cookies
+`), 0644))
+	t.Setenv("NMS_BASE_URL", "https://env.example")
+
+	var flags feedFlags
+	var validCookies []string
+	cmd := newSettingsCommand(t, &flags, &validCookies)
+	require.NoError(t, cmd.ParseFlags([]string{"-f", "mine.json", "--profile", "team"}))
+
+	// Act
+	err := applySettings(cmd, nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, feedFlags{
+		requestFlags: requestFlags{
+			BaseUrl:         "https://env.example",
+			CookieDirectory: "/profile-cookies",
+			CookieFile:      "mine.json",
+		},
+		OutputDirectory: "/config-output",
+		Workers:         2,
+	}, flags)
+	assert.Equal(t, []string{"a", "b"}, validCookies)
+}
+
+func TestApplySettings_ProfileFromEnv(t *testing.T) {
+	// Arrange
+	path := useConfigFile(t)
+	require.NoError(t, os.WriteFile(path, []byte("profiles:\n  team:\n    cookie-directory: /cookies\n    cookie-filename: team.json\n"), 0644))
+	t.Setenv("NMS_PROFILE", "team")
+	t.Cleanup(func() {
+		for name, value := range map[string]string{"output-directory": extractCmd.Flags().Lookup("output-directory").DefValue, "output-filename": "session-cookies.json"} {
+			extractCmd.Flags().Set(name, value)
+			extractCmd.Flags().Lookup(name).Changed = false
+		}
+		profileName = ""
+		RootCmd.PersistentFlags().Lookup("profile").Changed = false
+	})
+
+	// Act
+	err := applySettings(extractCmd, nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "/cookies", extractOptions.OutputDirectory)
+	assert.Equal(t, "team.json", extractOptions.OutputFilename)
+}
+
+func TestApplySettings_InvalidValue(t *testing.T) {
+	// Arrange
+	path := useConfigFile(t)
+	require.NoError(t, os.WriteFile(path, []byte("test:\n  workers: many\n"), 0644))
+
+	var flags feedFlags
+	var validCookies []string
+	cmd := newSettingsCommand(t, &flags, &validCookies)
+
+	// Act
+	err := applySettings(cmd, nil)
+
+	// Assert
+	assert.ErrorContains(t, err, "invalid config value for --workers")
+}
+
+func TestApplySettings_ProfileNotFound(t *testing.T) {
+	// Arrange
+	useConfigFile(t)
+
+	var flags feedFlags
+	var validCookies []string
+	cmd := newSettingsCommand(t, &flags, &validCookies)
+	require.NoError(t, cmd.ParseFlags([]string{"--profile", "team"}))
+
+	// Act
+	err := applySettings(cmd, nil)
+
+	// Assert
+	assert.EqualError(t, err, `profile "team" not found, add it with profile add`)
+}
+
+func TestApplySettings_SkipsProfileCommands(t *testing.T) {
+	// Arrange
+	path := useConfigFile(t)
+	require.NoError(t, os.WriteFile(path, []byte("base-url: https://config.example\n"), 0644))
+
+	// Act
+	err := applySettings(profileAddCmd, nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, profileOptions.BaseUrl)
+}
+
+func TestRunConfigShow(t *testing.T) {
+	// Arrange
+	path := useConfigFile(t)
+	require.NoError(t, os.WriteFile(path, []byte("auth:\n  status:\n    request-timeout: 5s\n"), 0644))
+	t.Setenv("NMS_BASE_URL", "https://env.example")
+
+	cmd := &cobra.Command{}
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	// Act
+	err := runConfigShow(cmd, []string{"auth", "status"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "Config file: "+path+"\n\nCOMMAND")
+	assert.Regexp(t, `auth status\s+base-url\s+https://env\.example\s+env\n`, out.String())
+	assert.Regexp(t, `auth status\s+cookie-filename\s+session-cookies\.json\s+default\n`, out.String())
+	assert.Regexp(t, `auth status\s+request-timeout\s+5s\s+config\n`, out.String())
+	assert.Regexp(t, `auth status\s+profile\s+default\n`, out.String())
+}
+
+func TestRunConfigShow_UnknownCommand(t *testing.T) {
+	// Arrange
+	useConfigFile(t)
+
+	// Act
+	err := runConfigShow(&cobra.Command{}, []string{"frobnicate"})
+
+	// Assert
+	assert.EqualError(t, err, `unknown command "frobnicate"`)
+}
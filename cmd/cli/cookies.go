@@ -0,0 +1,216 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/cli"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
+)
+
+// cookiesFlags holds the command-line flag values for the cookies commands.
+type cookiesFlags struct {
+	BaseUrl         string
+	CookieDirectory string
+	CookieFile      string
+	Decrypt         bool
+	Format          string
+	Output          string
+}
+
+// newPassphraseEnv is the environment variable holding the passphrase cookies rotate-key
+// encrypts the cookie file with, read instead of prompting.
+const newPassphraseEnv = "NMS_COOKIE_NEW_PASSPHRASE"
+
+var (
+	// cookiesCmd is a Cobra command grouping the cookie file commands.
+	cookiesCmd = &cobra.Command{}
+	// cookiesExportCmd is a Cobra command used for exporting the saved cookies.
+	cookiesExportCmd = &cobra.Command{}
+	// cookiesRotateKeyCmd is a Cobra command used for changing the cookie file passphrase.
+	cookiesRotateKeyCmd = &cobra.Command{}
+	// cookiesOptions holds the flag values for the cookies commands.
+	cookiesOptions = cookiesFlags{}
+	// readPassphrase is a variable that holds a reference to the function used to prompt
+	// for a passphrase without echoing it.
+	readPassphrase = promptPassphrase
+)
+
+// init initializes the cookies command and its export subcommand, setting their usage,
+// description, and argument validation, and adds them to the root command.
+func init() {
+	cookiesCmd = &cobra.Command{
+		Use:   "cookies",
+		Short: "Manage the saved cookies",
+		Long:  "Manage the session cookies saved by the extract command",
+	}
+
+	cookiesExportCmd = &cobra.Command{
+		Use:   "export [flags]",
+		Short: "Export the saved cookies for other tools",
+		Long:  "Export the saved session cookies as a Netscape cookies.txt file for curl and wget, as the JSON cookie file, or as a Cookie header value",
+		Args:  cobra.NoArgs,
+		RunE:  runCookiesExport,
+	}
+
+	cookiesRotateKeyCmd = &cobra.Command{
+		Use:   "rotate-key [flags]",
+		Short: "Encrypt the saved cookies with a new passphrase",
+		Long:  "Re-encrypt the saved session cookies with a new passphrase, read from " + newPassphraseEnv + " or prompted for. The current passphrase is read from " + httpclient.PassphraseEnv + ", and an unencrypted file is encrypted for the first time",
+		Args:  cobra.NoArgs,
+		RunE:  runCookiesRotateKey,
+	}
+
+	initCookiesExportFlags(cookiesExportCmd)
+	initCookiesRotateKeyFlags(cookiesRotateKeyCmd)
+	cookiesCmd.AddCommand(cookiesExportCmd, cookiesRotateKeyCmd)
+	RootCmd.AddCommand(cookiesCmd)
+}
+
+// initCookiesExportFlags registers the command-line flags for the cookies export
+// command, including the base URL, the cookie directory and filename, the format, and
+// the output file.
+func initCookiesExportFlags(cmd *cobra.Command) {
+	cli.RegisterFlag(cmd, "base-url", "u", "https://nexusmods.com", "Site the cookies without a domain belong to", &cookiesOptions.BaseUrl)
+	cli.RegisterFlag(cmd, "cookie-directory", "d", storage.GetDataStoragePath(), "Directory your cookie file is stored in", &cookiesOptions.CookieDirectory)
+	cli.RegisterFlag(cmd, "cookie-filename", "f", "session-cookies.json", "Filename where the cookies are stored", &cookiesOptions.CookieFile)
+	cli.RegisterFlag(cmd, "format", "", "netscape", "Export format: netscape, json or header", &cookiesOptions.Format)
+	cli.RegisterFlag(cmd, "output", "o", "", "File to write the cookies to, standard output when empty", &cookiesOptions.Output)
+}
+
+// initCookiesRotateKeyFlags registers the command-line flags for the cookies rotate-key
+// command, including the cookie directory and filename, and whether to decrypt instead.
+func initCookiesRotateKeyFlags(cmd *cobra.Command) {
+	cli.RegisterFlag(cmd, "cookie-directory", "d", storage.GetDataStoragePath(), "Directory your cookie file is stored in", &cookiesOptions.CookieDirectory)
+	cli.RegisterFlag(cmd, "cookie-filename", "f", "session-cookies.json", "Filename where the cookies are stored", &cookiesOptions.CookieFile)
+	cli.RegisterFlag(cmd, "decrypt", "", false, "Save the cookies unencrypted instead of with a new passphrase", &cookiesOptions.Decrypt)
+}
+
+// runCookiesRotateKey re-encrypts the cookie file with a new passphrase, or saves it
+// unencrypted with --decrypt. Returns an error if the new passphrase is missing or
+// not confirmed, or the file cannot be read with the current passphrase or written.
+func runCookiesRotateKey(cmd *cobra.Command, args []string) error {
+	var newPassphrase string
+	if !cookiesOptions.Decrypt {
+		var err error
+		if newPassphrase, err = newCookiePassphrase(); err != nil {
+			return err
+		}
+	}
+
+	if err := httpclient.RotateCookieKey(cookiesOptions.CookieDirectory, cookiesOptions.CookieFile, httpclient.Passphrase(), newPassphrase); err != nil {
+		return err
+	}
+
+	if cookiesOptions.Decrypt {
+		fmt.Fprintln(cmd.OutOrStdout(), "Cookie file saved unencrypted")
+		return nil
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Cookie file encrypted with the new passphrase, set %s to it\n", httpclient.PassphraseEnv)
+	return nil
+}
+
+// newCookiePassphrase returns the new passphrase from newPassphraseEnv, or prompts for
+// it twice. Returns an error if it is empty, the prompts differ, or prompting fails.
+func newCookiePassphrase() (string, error) {
+	if passphrase := os.Getenv(newPassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+
+	passphrase, err := readPassphrase("New passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", errors.New("the new passphrase must not be empty, use --decrypt to save the cookies unencrypted")
+	}
+	confirmed, err := readPassphrase("Confirm new passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if confirmed != passphrase {
+		return "", errors.New("the passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+// promptPassphrase prompts on standard error and reads a passphrase from the terminal
+// without echoing it. Returns an error if standard input is not a terminal.
+func promptPassphrase(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("cannot prompt for a passphrase without a terminal, set %s", newPassphraseEnv)
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("error reading passphrase: %w", err)
+	}
+	return string(passphrase), nil
+}
+
+// runCookiesExport reads the saved cookies and writes them in the chosen format to
+// the output file or standard output. Returns an error if the format is unknown, or
+// the cookies cannot be read or written.
+func runCookiesExport(cmd *cobra.Command, args []string) error {
+	format := strings.ToLower(cookiesOptions.Format)
+	if format != "netscape" && format != "json" && format != "header" {
+		return fmt.Errorf("unknown cookie export format %q, use netscape, json or header", cookiesOptions.Format)
+	}
+
+	cookies, err := httpclient.LoadCookies(cookiesOptions.CookieDirectory, cookiesOptions.CookieFile)
+	if err != nil {
+		return err
+	}
+
+	// Cookies saved in the old format have no domain, which cookies.txt requires
+	base, err := url.Parse(cookiesOptions.BaseUrl)
+	if err != nil {
+		return fmt.Errorf("error parsing base url: %w", err)
+	}
+	for _, cookie := range cookies {
+		if cookie.Domain == "" {
+			cookie.Domain = base.Hostname()
+		}
+	}
+
+	var out io.Writer = cmd.OutOrStdout()
+	if cookiesOptions.Output != "" {
+		// Exported cookies are credentials, so keep the file private
+		file, err := os.OpenFile(cookiesOptions.Output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return fmt.Errorf("error creating cookie export: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	switch format {
+	case "netscape":
+		err = httpclient.WriteNetscapeCookies(out, cookies)
+	case "json":
+		var data []byte
+		data, err = json.MarshalIndent(httpclient.NewCookieFile(cookies), "", "    ")
+		if err == nil {
+			_, err = fmt.Fprintln(out, string(data))
+		}
+	case "header":
+		_, err = fmt.Fprintln(out, httpclient.CookieHeader(cookies))
+	}
+	if err != nil {
+		return fmt.Errorf("error writing cookie export: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+)
+
+func TestRunCookiesExport(t *testing.T) {
+	tests := map[string]struct {
+		format   string
+		expected string
+	}{
+		"netscape": {
+			format:   "netscape",
+			expected: "# Netscape HTTP Cookie File\nnexusmods.com\tFALSE\t/\tFALSE\t0\tnexusmods_session\tabc\nnexusmods.com\tFALSE\t/\tFALSE\t0\tnexusmods_session_refresh\tdef\n",
+		},
+		"header": {
+			format:   "HEADER",
+			expected: "nexusmods_session=abc; nexusmods_session_refresh=def\n",
+		},
+		"json": {
+			format: "json",
+			expected: `{
+    "version": 2,
+    "cookies": [
+        {
+            "name": "nexusmods_session",
+            "value": "abc",
+            "domain": "nexusmods.com"
+        },
+        {
+            "name": "nexusmods_session_refresh",
+            "value": "def",
+            "domain": "nexusmods.com"
+        }
+    ]
+}
+`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "session-cookies.json"), []byte(`{"nexusmods_session":"abc","nexusmods_session_refresh":"def"}`), 0644))
+			cookiesOptions = cookiesFlags{BaseUrl: "https://nexusmods.com", CookieDirectory: dir, CookieFile: "session-cookies.json", Format: tt.format}
+
+			cmd := &cobra.Command{}
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			// Act
+			err := runCookiesExport(cmd, nil)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+}
+
+func TestRunCookiesExport_ToFile(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "session-cookies.json"), []byte(`{"nexusmods_session":"abc"}`), 0644))
+	output := filepath.Join(dir, "cookies.txt")
+	cookiesOptions = cookiesFlags{BaseUrl: "https://nexusmods.com", CookieDirectory: dir, CookieFile: "session-cookies.json", Format: "header", Output: output}
+
+	// Act
+	err := runCookiesExport(&cobra.Command{}, nil)
+
+	// Assert
+	require.NoError(t, err)
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, "nexusmods_session=abc\n", string(data))
+	info, err := os.Stat(output)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestRunCookiesExport_UnknownFormat(t *testing.T) {
+	// Arrange
+	cookiesOptions = cookiesFlags{Format: "xml"}
+
+	// Act
+	err := runCookiesExport(&cobra.Command{}, nil)
+
+	// Assert
+	assert.EqualError(t, err, `unknown cookie export format "xml", use netscape, json or header`)
+}
+
+func TestRunCookiesRotateKey(t *testing.T) {
+	tests := map[string]struct {
+		prompts  []string
+		expected string
+		err      string
+	}{
+		"prompted": {
+			prompts:  []string{"secret", "secret"},
+			expected: "Cookie file encrypted with the new passphrase, set NMS_COOKIE_PASSPHRASE to it\n",
+		},
+		"not confirmed": {
+			prompts: []string{"secret", "typo"},
+			err:     "the passphrases do not match",
+		},
+		"empty": {
+			prompts: []string{""},
+			err:     "the new passphrase must not be empty, use --decrypt to save the cookies unencrypted",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			t.Setenv(httpclient.PassphraseEnv, "")
+			t.Setenv(newPassphraseEnv, "")
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "session-cookies.json"), []byte(`{"nexusmods_session":"abc"}`), 0644))
+			cookiesOptions = cookiesFlags{CookieDirectory: dir, CookieFile: "session-cookies.json"}
+
+			originalReadPassphrase := readPassphrase
+			t.Cleanup(func() { readPassphrase = originalReadPassphrase })
+			prompts := tt.prompts
+			readPassphrase = func(prompt string) (string, error) {
+				next := prompts[0]
+				prompts = prompts[1:]
+				return next, nil
+			}
+
+			cmd := &cobra.Command{}
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			// Act
+			err := runCookiesRotateKey(cmd, nil)
+
+			// Assert
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, out.String())
+			t.Setenv(httpclient.PassphraseEnv, "secret")
+			cookies, err := httpclient.LoadCookies(dir, "session-cookies.json")
+			require.NoError(t, err)
+			require.Len(t, cookies, 1)
+			assert.Equal(t, "abc", cookies[0].Value)
+		})
+	}
+}
+
+func TestRunCookiesRotateKey_Decrypt(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	t.Setenv(httpclient.PassphraseEnv, "")
+	t.Setenv(newPassphraseEnv, "old")
+	cookiesOptions = cookiesFlags{CookieDirectory: dir, CookieFile: "session-cookies.json"}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "session-cookies.json"), []byte(`{"nexusmods_session":"abc"}`), 0644))
+	require.NoError(t, runCookiesRotateKey(&cobra.Command{}, nil))
+	t.Setenv(httpclient.PassphraseEnv, "old")
+	cookiesOptions.Decrypt = true
+
+	cmd := &cobra.Command{}
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	// Act
+	err := runCookiesRotateKey(cmd, nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Cookie file saved unencrypted\n", out.String())
+	data, err := os.ReadFile(filepath.Join(dir, "session-cookies.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"value": "abc"`)
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/cli"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/site"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
+)
+
+var (
+	// exportSiteCmd is a Cobra command used for generating a static site from saved results.
+	exportSiteCmd = &cobra.Command{}
+	// exportSiteDirectory is the output directory the saved game folders live in.
+	exportSiteDirectory string
+	// exportSiteTitle is the title shown on every page of the generated site.
+	exportSiteTitle string
+)
+
+// init initializes the export-site command, setting its usage, description, and
+// argument validation, and adds it to the root command.
+func init() {
+	exportSiteCmd = &cobra.Command{
+		Use:   "export-site <site directory> [flags]",
+		Short: "Generate a static site from saved mods",
+		Long:  "Generate a browsable static HTML site from every saved mod JSON under the output directory",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runExportSite,
+	}
+
+	initExportSiteFlags(exportSiteCmd)
+	RootCmd.AddCommand(exportSiteCmd)
+}
+
+// initExportSiteFlags registers the command-line flags for the export-site command,
+// including the output directory holding the saved results and the site title.
+func initExportSiteFlags(cmd *cobra.Command) {
+	cli.RegisterFlag(cmd, "output-directory", "o", storage.GetDataStoragePath(), "Output directory the saved results are in", &exportSiteDirectory)
+	cli.RegisterFlag(cmd, "title", "t", "Nexus Mods", "Title shown on every page of the site", &exportSiteTitle)
+}
+
+// runExportSite loads the saved results for every game under the output directory
+// and generates the static site into the given directory. Returns an error if no
+// results are found or the site cannot be generated.
+func runExportSite(cmd *cobra.Command, args []string) error {
+	siteDirectory, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+
+	gameNames, err := storage.ListSavedGames(exportSiteDirectory)
+	if err != nil {
+		return err
+	}
+
+	var games []site.Game
+	for _, name := range gameNames {
+		gameDirectory := filepath.Join(exportSiteDirectory, name)
+		if gameDirectory == siteDirectory {
+			continue
+		}
+
+		saved, err := storage.LoadSavedResults(gameDirectory, logger)
+		if err != nil {
+			return err
+		}
+		if len(saved) == 0 {
+			continue
+		}
+
+		mods := make([]types.ModInfo, 0, len(saved))
+		for _, results := range saved {
+			mods = append(mods, results.Mods)
+		}
+		games = append(games, site.Game{Name: name, Mods: mods})
+	}
+
+	if len(games) == 0 {
+		return fmt.Errorf("no saved results found in %s", exportSiteDirectory)
+	}
+
+	pages, err := site.Generate(siteDirectory, exportSiteTitle, games, utils.EnsureDirExists)
+	if err != nil {
+		return err
+	}
+
+	index := filepath.Join(siteDirectory, "index.html")
+	logger.Info("saved site", "games", len(games), "pages", pages, "path", siteDirectory)
+	fmt.Fprintf(cmd.OutOrStdout(), "Generated %d pages for %d games at %s\n", pages, len(games), colorLink(cmd.OutOrStdout(), index))
+	return nil
+}
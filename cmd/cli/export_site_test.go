@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunExportSite_GeneratesSite(t *testing.T) {
+	// Arrange
+	dataDir := t.TempDir()
+	gameDir := filepath.Join(dataDir, "skyrim")
+	require.NoError(t, os.Mkdir(gameDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(gameDir, "one 1.json"), []byte(`{"Mods":{"ModID":1,"Name":"One"}}`), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dataDir, "empty"), 0755))
+
+	siteDir := filepath.Join(t.TempDir(), "site")
+	exportSiteDirectory = dataDir
+	exportSiteTitle = "Modpack"
+
+	cmd := &cobra.Command{}
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	// Act
+	err := runExportSite(cmd, []string{siteDir})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(siteDir, "index.html"))
+	assert.FileExists(t, filepath.Join(siteDir, "skyrim", "1.html"))
+	assert.NoDirExists(t, filepath.Join(siteDir, "empty"))
+	assert.Contains(t, out.String(), "Generated 3 pages for 1 games")
+}
+
+func TestRunExportSite_NoSavedResults(t *testing.T) {
+	// Arrange
+	exportSiteDirectory = t.TempDir()
+
+	// Act
+	err := runExportSite(&cobra.Command{}, []string{filepath.Join(t.TempDir(), "site")})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no saved results found")
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/cli"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/exporters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
+)
+
+var (
+	// exportTableCmd is a Cobra command used for aggregating saved results into spreadsheets.
+	exportTableCmd = &cobra.Command{}
+	// exportTableDirectory is the output directory the saved game folders live in.
+	exportTableDirectory string
+	// exportTableOptions holds the table format and column selection for the sheets.
+	exportTableOptions = exporters.TableOptions{}
+)
+
+// init initializes the export-table command, setting its usage, description, and
+// argument validation, and adds it to the root command.
+func init() {
+	exportTableCmd = &cobra.Command{
+		Use:   "export-table <game name> [flags]",
+		Short: "Export saved mods as csv or tsv",
+		Long:  "Aggregate every saved mod JSON for a game into one mods sheet and one files sheet",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runExportTable,
+	}
+
+	initExportTableFlags(exportTableCmd)
+	RootCmd.AddCommand(exportTableCmd)
+}
+
+// initExportTableFlags registers the command-line flags for the export-table command,
+// including the output directory, table format, column selections, and the separator
+// used when flattening nested fields.
+func initExportTableFlags(cmd *cobra.Command) {
+	cli.RegisterFlag(cmd, "output-directory", "o", storage.GetDataStoragePath(), "Output directory the saved results are in", &exportTableDirectory)
+	cli.RegisterFlag(cmd, "table-format", "t", exporters.CsvTable, "Table format, csv or tsv", &exportTableOptions.Format)
+	cli.RegisterFlag(cmd, "mod-columns", "", exporters.DefaultModColumns, "Columns to include in the mods sheet", &exportTableOptions.ModColumns)
+	cli.RegisterFlag(cmd, "file-columns", "", exporters.DefaultFileColumns, "Columns to include in the files sheet", &exportTableOptions.FileColumns)
+	cli.RegisterFlag(cmd, "join-separator", "", "; ", "Separator used when joining tags, requirements and changelogs", &exportTableOptions.JoinSeparator)
+}
+
+// runExportTable loads every saved result for the game and writes them into a mods
+// sheet and a files sheet in the game's output directory. Returns an error if no
+// results are found or the sheets cannot be written.
+func runExportTable(cmd *cobra.Command, args []string) error {
+	game := strings.ToLower(args[0])
+	gameDirectory := filepath.Join(exportTableDirectory, game)
+
+	saved, err := storage.LoadSavedResults(gameDirectory, logger)
+	if err != nil {
+		return err
+	}
+	if len(saved) == 0 {
+		return fmt.Errorf("no saved results found in %s", gameDirectory)
+	}
+
+	mods := make([]types.ModInfo, 0, len(saved))
+	for _, results := range saved {
+		mods = append(mods, results.Mods)
+	}
+
+	paths, err := exporters.SaveModInfoToTables(game, mods, gameDirectory, game, exportTableOptions, utils.EnsureDirExists)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		logger.Info("saved table", "game", game, "mods", len(mods), "path", path)
+		fmt.Fprintf(cmd.OutOrStdout(), "Saved %d mods to %s\n", len(mods), colorLink(cmd.OutOrStdout(), path))
+	}
+
+	return nil
+}
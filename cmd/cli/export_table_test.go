@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/exporters"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunExportTable_AggregatesGameDirectory(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	gameDir := filepath.Join(dir, "skyrim")
+	require.NoError(t, os.Mkdir(gameDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(gameDir, "one 1.json"), []byte(`{"Mods":{"ModID":1,"Name":"One","Files":[{"name":"Main"}]}}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(gameDir, "two 2.json"), []byte(`{"Mods":{"ModID":2,"Name":"Two"}}`), 0644))
+
+	exportTableDirectory = dir
+	exportTableOptions = exporters.TableOptions{Format: exporters.CsvTable, ModColumns: []string{"mod_id", "name"}}
+
+	cmd := &cobra.Command{}
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	// Act
+	err := runExportTable(cmd, []string{"Skyrim"})
+
+	// Assert
+	assert.NoError(t, err)
+	mods, err := os.ReadFile(filepath.Join(gameDir, "skyrim-mods.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "mod_id,name\n1,One\n2,Two\n", string(mods))
+	assert.FileExists(t, filepath.Join(gameDir, "skyrim-files.csv"))
+	assert.Contains(t, out.String(), "Saved 2 mods")
+}
+
+func TestRunExportTable_NoSavedResults(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "skyrim"), 0755))
+	exportTableDirectory = dir
+
+	// Act
+	err := runExportTable(&cobra.Command{}, []string{"skyrim"})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no saved results found")
+}
@@ -1,36 +1,61 @@
 package cli
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/browserutils/kooky"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
 	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
 	"github.com/ondrovic/nexus-mods-scraper/internal/utils/cli"
 	"github.com/ondrovic/nexus-mods-scraper/internal/utils/exporters"
-	"github.com/ondrovic/nexus-mods-scraper/internal/utils/extractors"
-	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
 	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
+	"github.com/ondrovic/nexus-mods-scraper/pkg/nexus"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
+// extractFlags holds the command-line flag values for the extract command.
+type extractFlags struct {
+	BaseUrl string
+	// Browser limits extraction to the cookie stores of this browser.
+	Browser string
+	// BrowserProfile limits extraction to the cookie stores of this browser profile.
+	BrowserProfile string
+	// FromCookiesTxt is the Netscape cookies.txt file to import cookies from instead
+	// of the browsers.
+	FromCookiesTxt string
+	// FromHar is the HAR file to import cookies from instead of the browsers.
+	FromHar string
+	// ListStores lists the cookie stores instead of extracting from them.
+	ListStores      bool
+	OutputDirectory string
+	OutputFilename  string
+	ValidCookies    []string
+}
+
 var (
 	// extractCmd is a Cobra command used for extracting information within the application.
 	extractCmd = &cobra.Command{}
-	// outputFilename is a string variable that stores the name of the file to which
-	// output will be saved.
-	outputFilename string
+	// extractOptions holds the flag values for the extract command.
+	extractOptions = extractFlags{}
 )
 
 // init initializes the extract command, setting its usage, description, and argument validation.
-// It binds flags using Viper and adds the extract command to the root command for extracting
+// It registers the flags and adds the extract command to the root command for extracting
 // cookies and saving them to a JSON file.
 func init() {
 	extractCmd = &cobra.Command{
 		Use:   "extract",
 		Short: "Extract cookies",
-		Long:  "Extract cookies for https://nexusmods.com to use with the scraper, will save to json file",
+		Long:  "Extract cookies for https://nexusmods.com to use with the scraper, will save to json file. Cookies are read from the local browsers, or imported from a cookies.txt or HAR file",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Call the actual ExtractCookies function with the default store provider
@@ -39,35 +64,125 @@ func init() {
 	}
 
 	initExtractFlags(extractCmd)
-	viper.BindPFlags(extractCmd.Flags())
 	RootCmd.AddCommand(extractCmd)
 }
 
 // initExtractFlags registers the command-line flags for the extract command, including
-// options for the output directory, output filename, and valid cookie names to extract.
-// These flags are bound to the corresponding variables and fields in CliFlags.
+// options for the base URL, output directory, output filename, valid cookie names to
+// extract, the cookies.txt or HAR file to import from, and the browser and profile to
+// read. These flags are bound to the corresponding fields in extractFlags.
 func initExtractFlags(cmd *cobra.Command) {
-	cli.RegisterFlag(cmd, "output-directory", "d", storage.GetDataStoragePath(), "Output directory to save the file in", &options.OutputDirectory)
-	cli.RegisterFlag(cmd, "output-filename", "f", "session-cookies.json", "Filename to save the session cookies to", &outputFilename)
-	cli.RegisterFlag(cmd, "valid-cookie-names", "c", []string{"nexusmods_session", "nexusmods_session_refresh"}, "Names of the cookies to extract", &options.ValidCookies)
+	cli.RegisterFlag(cmd, "base-url", "u", "https://nexusmods.com", "Site to extract the cookies for", &extractOptions.BaseUrl)
+	cli.RegisterFlag(cmd, "output-directory", "d", storage.GetDataStoragePath(), "Output directory to save the file in", &extractOptions.OutputDirectory)
+	cli.RegisterFlag(cmd, "output-filename", "f", "session-cookies.json", "Filename to save the session cookies to", &extractOptions.OutputFilename)
+	cli.RegisterFlag(cmd, "valid-cookie-names", "c", []string{"nexusmods_session", "nexusmods_session_refresh"}, "Names of the cookies to extract", &extractOptions.ValidCookies)
+	cli.RegisterFlag(cmd, "from-cookies-txt", "", "", "Import the cookies from a Netscape cookies.txt file instead of the browsers", &extractOptions.FromCookiesTxt)
+	cli.RegisterFlag(cmd, "from-har", "", "", "Import the cookies from a HAR file saved from the browser's developer tools instead of the browsers", &extractOptions.FromHar)
+	cli.RegisterFlag(cmd, "browser", "", "", "Only read the cookies of this browser, such as firefox, chrome or edge", &extractOptions.Browser)
+	cli.RegisterFlag(cmd, "browser-profile", "", "", "Only read the cookies of this browser profile", &extractOptions.BrowserProfile)
+	cli.RegisterFlag(cmd, "list-stores", "", false, "List the browser cookie stores and whether they hold valid cookies, without extracting", &extractOptions.ListStores)
 }
 
 // ExtractCookies extracts cookies from the specified domain using the valid cookie names,
-// then saves them as a JSON file in the designated output directory. Returns an error
-// if cookie extraction or saving fails.
+// from the chosen browser and profile or the cookies.txt or HAR file given, then saves
+// them with their attributes as a JSON file in the designated output directory. With
+// --list-stores it lists the browser cookie stores instead. Returns an error if cookie
+// extraction, importing or saving fails.
 func ExtractCookies(cmd *cobra.Command, args []string, storeProvider func() []kooky.CookieStore) error {
-	domain := formatters.CookieDomain(options.BaseUrl)
-	sessionCookies := options.ValidCookies
+	if extractOptions.FromCookiesTxt != "" && extractOptions.FromHar != "" {
+		return errors.New("--from-cookies-txt and --from-har cannot be used together")
+	}
 
 	// Use the passed storeProvider instead of the default kooky.FindAllCookieStores
-	extractedCookies, err := extractors.CookieExtractor(domain, sessionCookies, storeProvider)
+	scraper, err := nexus.New(
+		nexus.WithBaseURL(extractOptions.BaseUrl),
+		nexus.WithValidCookies(extractOptions.ValidCookies...),
+		nexus.WithCookieStores(storeProvider),
+		nexus.WithBrowser(extractOptions.Browser),
+		nexus.WithProfile(extractOptions.BrowserProfile),
+		nexus.WithLogger(logger),
+	)
 	if err != nil {
 		return err
 	}
 
-	if err := exporters.SaveCookiesToJson(options.OutputDirectory, outputFilename, extractedCookies, os.OpenFile, utils.EnsureDirExists); err != nil {
+	if extractOptions.ListStores {
+		return printCookieStores(cmd.OutOrStdout(), scraper.CookieStores())
+	}
+
+	switch {
+	case extractOptions.FromCookiesTxt != "":
+		err = importCookies(scraper, extractOptions.FromCookiesTxt, httpclient.ParseNetscapeCookies)
+	case extractOptions.FromHar != "":
+		err = importCookies(scraper, extractOptions.FromHar, httpclient.ParseHARCookies)
+	default:
+		_, err = scraper.ExtractCookies()
+	}
+	if err != nil {
+		return err
+	}
+
+	// Save the cookies with their domain, path, expiry and flags, encrypted when a
+	// passphrase is set
+	cookieFile, err := httpclient.EncodeCookieFile(scraper.StoredCookies())
+	if err != nil {
+		return err
+	}
+	if err := exporters.SaveCookiesToJson(extractOptions.OutputDirectory, extractOptions.OutputFilename, json.RawMessage(cookieFile), os.OpenFile, utils.EnsureDirExists); err != nil {
+		return err
+	}
+	path := filepath.Join(extractOptions.OutputDirectory, extractOptions.OutputFilename)
+	logger.Info("saved cookies", "path", path)
+	fmt.Fprintf(progress, "Extracted cookies saved to %s\n", colorLink(progress, path))
+
+	return nil
+}
+
+// importCookies reads the cookies in the file at path with parse and adds the valid
+// session cookies among them to the scraper. Returns an error if the file cannot be
+// read or parsed, or holds no valid session cookies.
+func importCookies(scraper *nexus.Scraper, path string, parse func(io.Reader) ([]*http.Cookie, error)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening cookie import: %w", err)
+	}
+	defer file.Close()
+
+	cookies, err := parse(file)
+	if err != nil {
 		return err
 	}
 
+	if _, err := scraper.ImportCookies(cookies); err != nil {
+		return fmt.Errorf("error importing cookies from %s: %w", path, err)
+	}
 	return nil
 }
+
+// printCookieStores writes a table of the cookie stores, with each store's browser,
+// profile, path, and the valid cookies it holds or the error reading it. Returns an
+// error if no stores are found or writing fails.
+func printCookieStores(w io.Writer, stores []nexus.CookieStoreInfo) error {
+	if len(stores) == 0 {
+		return errors.New("no cookie stores found")
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "BROWSER\tPROFILE\tPATH\tVALID COOKIES")
+	for _, store := range stores {
+		profileName := store.Profile
+		if store.DefaultProfile {
+			profileName += " (default)"
+		}
+
+		cookies := "none"
+		switch {
+		case store.Err != nil:
+			cookies = "error: " + store.Err.Error()
+		case store.HasValidCookies():
+			cookies = strings.Join(store.Cookies, ", ")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", store.Browser, profileName, store.Path, cookies)
+	}
+	return tw.Flush()
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"bytes"
 	"errors"
 	"net/http"
 	"net/url"
@@ -15,6 +16,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type MockCookieStore struct {
@@ -120,10 +122,10 @@ func TestExtractCookies_Success(t *testing.T) {
 	}
 
 	// Set the options (these can be set globally or adjusted as necessary)
-	options.BaseUrl = "http://example.com"
-	options.ValidCookies = []string{"session"}
-	options.OutputDirectory = tempDir
-	outputFilename = "session-cookies.json"
+	extractOptions.BaseUrl = "http://example.com"
+	extractOptions.ValidCookies = []string{"session"}
+	extractOptions.OutputDirectory = tempDir
+	extractOptions.OutputFilename = "session-cookies.json"
 
 	// Act: Call ExtractCookies using the mockStoreProvider
 	cmd := &cobra.Command{}
@@ -131,7 +133,7 @@ func TestExtractCookies_Success(t *testing.T) {
 	err := ExtractCookies(cmd, args, mockStoreProvider)
 
 	// Call SaveCookiesToJson with mocked functions
-	err = exporters.SaveCookiesToJson(options.OutputDirectory, outputFilename, map[string]string{"session": "1234"}, mockOpenFile, mockEnsureDirExists)
+	err = exporters.SaveCookiesToJson(extractOptions.OutputDirectory, extractOptions.OutputFilename, map[string]string{"session": "1234"}, mockOpenFile, mockEnsureDirExists)
 
 	// Assert: Verify no error and that all expectations on the mocks are met
 	assert.NoError(t, err)
@@ -150,6 +152,140 @@ func TestExtractCookies_Success(t *testing.T) {
 	assert.JSONEq(t, expectedContent, string(fileContent), "The cookie data written to the file is not as expected")
 }
 
+func TestExtractCookies_SavesCookieAttributes(t *testing.T) {
+	// Arrange
+	expires := time.Now().Add(time.Hour).Truncate(time.Second).UTC()
+	mockStore := new(MockCookieStore)
+	mockStore.On("ReadCookies", mock.Anything).Return([]*kooky.Cookie{
+		{Cookie: http.Cookie{Name: "session", Value: "1234", Domain: ".example.com", Path: "/", Expires: expires, Secure: true, HttpOnly: true}},
+	}, nil)
+	mockStore.On("Close").Return(nil)
+
+	tempDir := t.TempDir()
+	extractOptions.BaseUrl = "http://example.com"
+	extractOptions.ValidCookies = []string{"session"}
+	extractOptions.OutputDirectory = tempDir
+	extractOptions.OutputFilename = "session-cookies.json"
+
+	// Act
+	err := ExtractCookies(&cobra.Command{}, []string{}, func() []kooky.CookieStore { return []kooky.CookieStore{mockStore} })
+
+	// Assert
+	assert.NoError(t, err)
+	fileContent, err := os.ReadFile(filepath.Join(tempDir, extractOptions.OutputFilename))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"version":2,"cookies":[{"name":"session","value":"1234","domain":".example.com","path":"/","expires":"`+expires.Format(time.RFC3339)+`","secure":true,"httpOnly":true}]}`, string(fileContent))
+}
+
+func TestExtractCookies_ImportsFiles(t *testing.T) {
+	tests := map[string]struct {
+		file    string
+		content string
+		target  *string
+	}{
+		"cookies.txt": {
+			file:    "cookies.txt",
+			content: "# Netscape HTTP Cookie File\n.example.com\tTRUE\t/\tFALSE\t0\tsession\t1234\n.other.com\tTRUE\t/\tFALSE\t0\tsession\tforeign\n",
+			target:  &extractOptions.FromCookiesTxt,
+		},
+		"har": {
+			file:    "cookies.har",
+			content: `{"log":{"entries":[{"request":{"cookies":[{"name":"tracking","value":"x"}]},"response":{"cookies":[{"name":"session","value":"1234","domain":".example.com","path":"/"}]}}]}}`,
+			target:  &extractOptions.FromHar,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			tempDir := t.TempDir()
+			importPath := filepath.Join(tempDir, tt.file)
+			require.NoError(t, os.WriteFile(importPath, []byte(tt.content), 0644))
+			extractOptions.BaseUrl = "http://example.com"
+			extractOptions.ValidCookies = []string{"session"}
+			extractOptions.OutputDirectory = tempDir
+			extractOptions.OutputFilename = "session-cookies.json"
+			*tt.target = importPath
+			defer func() { *tt.target = "" }()
+			noStores := func() []kooky.CookieStore {
+				t.Fatal("browsers are not read when importing")
+				return nil
+			}
+
+			// Act
+			err := ExtractCookies(&cobra.Command{}, []string{}, noStores)
+
+			// Assert
+			require.NoError(t, err)
+			fileContent, err := os.ReadFile(filepath.Join(tempDir, extractOptions.OutputFilename))
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"version":2,"cookies":[{"name":"session","value":"1234","domain":".example.com","path":"/"}]}`, string(fileContent))
+		})
+	}
+}
+
+func TestExtractCookies_ImportWithoutMatchingCookies(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	extractOptions.FromCookiesTxt = filepath.Join(tempDir, "cookies.txt")
+	defer func() { extractOptions.FromCookiesTxt = "" }()
+	require.NoError(t, os.WriteFile(extractOptions.FromCookiesTxt, []byte("# empty\n"), 0644))
+	extractOptions.BaseUrl = "http://example.com"
+	extractOptions.ValidCookies = []string{"session"}
+	extractOptions.OutputDirectory = tempDir
+
+	// Act
+	err := ExtractCookies(&cobra.Command{}, []string{}, nil)
+
+	// Assert
+	assert.EqualError(t, err, "error importing cookies from "+extractOptions.FromCookiesTxt+": no matching cookies found")
+}
+
+func TestExtractCookies_ImportFlagsConflict(t *testing.T) {
+	// Arrange
+	extractOptions.FromCookiesTxt, extractOptions.FromHar = "cookies.txt", "cookies.har"
+	defer func() { extractOptions.FromCookiesTxt, extractOptions.FromHar = "", "" }()
+
+	// Act
+	err := ExtractCookies(&cobra.Command{}, []string{}, nil)
+
+	// Assert
+	assert.EqualError(t, err, "--from-cookies-txt and --from-har cannot be used together")
+}
+
+func TestExtractCookies_ListStores(t *testing.T) {
+	// Arrange
+	newStore := func(browserName, profileName, path string, cookies []*kooky.Cookie) *MockCookieStore {
+		store := new(MockCookieStore)
+		store.On("Browser").Return(browserName)
+		store.On("Profile").Return(profileName)
+		store.On("IsDefaultProfile").Return(profileName == "default")
+		store.On("FilePath").Return(path)
+		store.On("ReadCookies", mock.Anything).Return(cookies, nil)
+		store.On("Close").Return(nil)
+		return store
+	}
+	firefox := newStore("firefox", "default", "/home/me/.mozilla/cookies.sqlite", []*kooky.Cookie{{Cookie: http.Cookie{Name: "session", Value: "1234"}}})
+	chrome := newStore("chrome", "Profile 1", "/home/me/.config/chrome/Cookies", nil)
+	extractOptions.BaseUrl = "http://example.com"
+	extractOptions.ValidCookies = []string{"session"}
+	extractOptions.ListStores = true
+	defer func() { extractOptions.ListStores = false }()
+
+	cmd := &cobra.Command{}
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	// Act
+	err := ExtractCookies(cmd, []string{}, func() []kooky.CookieStore { return []kooky.CookieStore{firefox, chrome} })
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "BROWSER  PROFILE            PATH                              VALID COOKIES\n"+
+		"firefox  default (default)  /home/me/.mozilla/cookies.sqlite  session\n"+
+		"chrome   Profile 1          /home/me/.config/chrome/Cookies   none\n", out.String())
+}
+
 func TestExtractCookies_ErrorInCookieExtractor(t *testing.T) {
 	// Arrange: Create a mock cookie store
 	mockStore := new(MockCookieStore)
@@ -167,10 +303,10 @@ func TestExtractCookies_ErrorInCookieExtractor(t *testing.T) {
 	mockStore.On("Close").Return(nil)                                         // Simulate successful closing
 
 	// Set the options
-	options.BaseUrl = "http://example.com"
-	options.ValidCookies = []string{"session"}
-	options.OutputDirectory = "/tmp"
-	outputFilename = "session-cookies.json"
+	extractOptions.BaseUrl = "http://example.com"
+	extractOptions.ValidCookies = []string{"session"}
+	extractOptions.OutputDirectory = "/tmp"
+	extractOptions.OutputFilename = "session-cookies.json"
 
 	// Act: Call ExtractCookies using the mockStoreProvider
 	cmd := &cobra.Command{}
@@ -0,0 +1,235 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/cli"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/feeds"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/scheduler"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
+	"github.com/ondrovic/nexus-mods-scraper/pkg/nexus"
+)
+
+// feedFlags holds the command-line flag values for the feed command.
+type feedFlags struct {
+	requestFlags
+	Concurrency     int
+	Format          string
+	Limit           int
+	Output          string
+	OutputDirectory string
+	PerHost         int
+	Timeout         time.Duration
+	Tracked         string
+	Workers         int
+}
+
+var (
+	// feedCmd is a Cobra command used for generating feeds of mod updates.
+	feedCmd = &cobra.Command{}
+	// feedOptions holds the flag values for the feed command.
+	feedOptions = feedFlags{}
+)
+
+// init initializes the feed command, setting its usage, description, and argument
+// validation, and adds it to the root command.
+func init() {
+	feedCmd = &cobra.Command{
+		Use:   "feed <game name> [flags]",
+		Short: "Generate an Atom or RSS feed of mod updates",
+		Long:  "Generate an Atom or RSS feed of new file versions and changelog entries, from saved results or a tracked list of mods",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runFeed,
+	}
+
+	initFeedFlags(feedCmd)
+	RootCmd.AddCommand(feedCmd)
+}
+
+// initFeedFlags registers the command-line flags for the feed command, including the
+// feed format and destination, the saved results directory, and the tracked list
+// along with the workers and per-host limit, the base URL, cookies, rate limit,
+// timeouts, proxy, User-Agent, CA certificate and extra headers used to scrape it.
+func initFeedFlags(cmd *cobra.Command) {
+	registerRequestFlags(cmd, &feedOptions.requestFlags)
+	cli.RegisterFlag(cmd, "concurrency", "", 2, "Number of pages of each mod requested at once", &feedOptions.Concurrency)
+	cli.RegisterFlag(cmd, "format", "t", feeds.AtomFeed, "Feed format, atom or rss", &feedOptions.Format)
+	cli.RegisterFlag(cmd, "limit", "l", 50, "Maximum number of items in the feed, 0 for no limit", &feedOptions.Limit)
+	cli.RegisterFlag(cmd, "output", "", "", "File to write the feed to, - for stdout (default <output-directory>/<game>/<game>.<format>.xml)", &feedOptions.Output)
+	cli.RegisterFlag(cmd, "output-directory", "o", storage.GetDataStoragePath(), "Output directory the saved results are in", &feedOptions.OutputDirectory)
+	cli.RegisterFlag(cmd, "per-host", "", 0, "Most requests sent to the same host at once, 0 for no limit", &feedOptions.PerHost)
+	cli.RegisterFlag(cmd, "timeout", "", time.Duration(0), "Maximum time for scraping the tracked list, 0 for no limit", &feedOptions.Timeout)
+	cli.RegisterFlag(cmd, "tracked", "", "", "File listing mod IDs to scrape for the feed, one per line, instead of using saved results", &feedOptions.Tracked)
+	cli.RegisterFlag(cmd, "workers", "w", 4, "Number of tracked mods to scrape at once", &feedOptions.Workers)
+}
+
+// runFeed gathers the mods for the game, either by scraping the tracked list or from
+// the saved results, and writes their updates as a feed. Returns an error if no mods
+// are found, scraping fails, or the feed cannot be written.
+func runFeed(cmd *cobra.Command, args []string) error {
+	game := strings.ToLower(args[0])
+	format := strings.ToLower(feedOptions.Format)
+	if format != feeds.AtomFeed && format != feeds.RssFeed {
+		return fmt.Errorf("unknown feed format %q, expected %s or %s", feedOptions.Format, feeds.AtomFeed, feeds.RssFeed)
+	}
+
+	var mods []types.ModInfo
+	if feedOptions.Tracked != "" {
+		scraped, err := scrapeTrackedMods(commandContext(cmd), game, feedOptions.Tracked, cmd.ErrOrStderr())
+		if err != nil {
+			return err
+		}
+		mods = scraped
+	} else {
+		saved, err := storage.LoadSavedResults(filepath.Join(feedOptions.OutputDirectory, game), logger)
+		if err != nil {
+			return err
+		}
+		for _, results := range saved {
+			mods = append(mods, results.Mods)
+		}
+	}
+
+	if len(mods) == 0 {
+		return fmt.Errorf("no mods found for %s", game)
+	}
+
+	items := feeds.BuildItems(feedOptions.BaseUrl, game, mods)
+	if feedOptions.Limit > 0 && len(items) > feedOptions.Limit {
+		items = items[:feedOptions.Limit]
+	}
+
+	feed := feeds.Feed{
+		Title: fmt.Sprintf("%s mod updates", game),
+		Link:  fmt.Sprintf("%s/%s/mods", strings.TrimRight(feedOptions.BaseUrl, "/"), game),
+		ID:    fmt.Sprintf("urn:nexus-mods:%s:updates", game),
+		Items: items,
+	}
+
+	if feedOptions.Output == "-" {
+		return feeds.Write(cmd.OutOrStdout(), format, feed)
+	}
+
+	output := feedOptions.Output
+	if output == "" {
+		output = filepath.Join(feedOptions.OutputDirectory, game, fmt.Sprintf("%s.%s.xml", game, format))
+	}
+	if err := utils.EnsureDirExists(filepath.Dir(output)); err != nil {
+		return err
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("error saving file: %s - %v", output, err)
+	}
+	defer file.Close()
+
+	if err := feeds.Write(file, format, feed); err != nil {
+		return err
+	}
+	logger.Info("saved feed", "game", game, "items", len(items), "path", output)
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Saved %d feed items to %s\n", len(items), colorLink(cmd.OutOrStdout(), output))
+	return nil
+}
+
+// scrapeTrackedMods reads the mod IDs listed in the tracked file and scrapes them for
+// the game on a pool of workers, writing progress to progress and stopping when ctx
+// is cancelled or the --timeout passes. Returns the scraped mods in the order they are
+// listed, or an error if the list cannot be read, the HTTP client cannot be set up, or
+// any scrape fails, naming every mod that failed.
+func scrapeTrackedMods(ctx context.Context, game, tracked string, progress io.Writer) ([]types.ModInfo, error) {
+	file, err := os.Open(tracked)
+	if err != nil {
+		return nil, fmt.Errorf("error opening tracked list: %w", err)
+	}
+	defer file.Close()
+
+	modIDs, err := readTrackedList(file)
+	if err != nil {
+		return nil, err
+	}
+
+	scraper, err := feedOptions.newScraper(nexus.WithConcurrency(feedOptions.Concurrency, feedOptions.PerHost))
+	if err != nil {
+		return nil, err
+	}
+
+	if feedOptions.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, feedOptions.Timeout)
+		defer cancel()
+	}
+
+	mods := make([]types.ModInfo, len(modIDs))
+	tasks := make([]scheduler.Task, len(modIDs))
+	for i, modID := range modIDs {
+		tasks[i] = scheduler.Task{
+			Label: fmt.Sprintf("mod %d", modID),
+			Host:  scheduler.HostOf(scraper.BaseURL()),
+			Run: func(ctx context.Context) error {
+				results, err := scraper.ScrapeMod(ctx, game, modID)
+				if err != nil {
+					return err
+				}
+				mods[i] = results.Mods
+				return nil
+			},
+		}
+	}
+
+	pool := scheduler.New(scheduler.Options{
+		Workers: feedOptions.Workers,
+		PerHost: feedOptions.PerHost,
+		OnProgress: func(p scheduler.Progress) {
+			status := "scraped"
+			if p.Err != nil {
+				status = "failed"
+			}
+			fmt.Fprintf(progress, "[%d/%d] %s %s\n", p.Completed+p.Failed, p.Total, status, p.Label)
+		},
+	})
+	err = pool.Run(ctx, tasks...)
+	if saveErr := saveSessionCookies(scraper, feedOptions.CookieFile); saveErr != nil && err == nil {
+		return nil, saveErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error scraping tracked mods: %w", err)
+	}
+
+	return mods, nil
+}
+
+// readTrackedList parses mod IDs from r, one per line, ignoring blank lines and
+// lines starting with #. Returns an error if a line is not a valid mod ID.
+func readTrackedList(r io.Reader) ([]int64, error) {
+	var modIDs []int64
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		modID, err := formatters.StrToInt(strings.Fields(line)[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid mod ID in tracked list: %q", line)
+		}
+		modIDs = append(modIDs, modID)
+	}
+
+	return modIDs, scanner.Err()
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+)
+
+func TestRunFeed_FromSavedResults(t *testing.T) {
+	// Arrange
+	dataDir := t.TempDir()
+	gameDir := filepath.Join(dataDir, "skyrim")
+	require.NoError(t, os.Mkdir(gameDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(gameDir, "one 1.json"), []byte(`{"Mods":{"ModID":1,"Name":"One","Files":[{"name":"Main","version":"1.0","uploadDate":"01 Jan 2024, 9:00AM"}]}}`), 0644))
+
+	feedOptions = feedFlags{requestFlags: requestFlags{BaseUrl: "https://nexusmods.com"}, Format: "rss", OutputDirectory: dataDir, Limit: 10}
+
+	cmd := &cobra.Command{}
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	// Act
+	err := runFeed(cmd, []string{"Skyrim"})
+
+	// Assert
+	assert.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(gameDir, "skyrim.rss.xml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "<title>One: Main 1.0</title>")
+	assert.Contains(t, out.String(), "Saved 1 feed items")
+}
+
+func TestRunFeed_FromTrackedList(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "session-cookies.json"), []byte("{}"), 0644))
+	tracked := filepath.Join(dir, "tracked.txt")
+	require.NoError(t, os.WriteFile(tracked, []byte("# mods we follow\n1234\n\n5678 armour pack\n"), 0644))
+
+	originalFetchModInfo := fetchModInfoFunc
+	fetchModInfoFunc = mockFetchModInfoConcurrent
+	defer func() { fetchModInfoFunc = originalFetchModInfo }()
+	feedOptions = feedFlags{
+		requestFlags: requestFlags{
+			BaseUrl:         "https://nexusmods.com",
+			CookieDirectory: dir,
+			CookieFile:      "session-cookies.json",
+		},
+		Format:  "atom",
+		Output:  "-",
+		Tracked: tracked,
+	}
+
+	cmd := &cobra.Command{}
+	var out, progress bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&progress)
+
+	// Act
+	err := runFeed(cmd, []string{"skyrim"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out.String(), "<?xml"))
+	assert.Contains(t, out.String(), "<title>skyrim mod updates</title>")
+	assert.Contains(t, progress.String(), "[2/2] scraped mod")
+}
+
+func TestRunFeed_TrackedListReportsEveryFailure(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "session-cookies.json"), []byte("{}"), 0644))
+	tracked := filepath.Join(dir, "tracked.txt")
+	require.NoError(t, os.WriteFile(tracked, []byte("1\n2\n3\n"), 0644))
+
+	originalFetchModInfo := fetchModInfoFunc
+	fetchModInfoFunc = func(ctx context.Context, baseUrl, game string, modId int64, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		if modId == 2 {
+			return types.Results{Mods: types.ModInfo{ModID: modId}}, nil
+		}
+		return types.Results{}, errors.New("page not found")
+	}
+	defer func() { fetchModInfoFunc = originalFetchModInfo }()
+	feedOptions = feedFlags{
+		requestFlags: requestFlags{
+			BaseUrl:         "https://nexusmods.com",
+			CookieDirectory: dir,
+			CookieFile:      "session-cookies.json",
+		},
+		Format:  "atom",
+		Output:  "-",
+		Tracked: tracked,
+		Workers: 2,
+	}
+
+	cmd := &cobra.Command{}
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+
+	// Act
+	err := runFeed(cmd, []string{"skyrim"})
+
+	// Assert
+	assert.EqualError(t, err, "error scraping tracked mods: 2 tasks failed: mod 1: page not found; mod 3: page not found")
+}
+
+func TestRunFeed_UnknownFormat(t *testing.T) {
+	// Arrange
+	feedOptions = feedFlags{Format: "json"}
+
+	// Act
+	err := runFeed(&cobra.Command{}, []string{"skyrim"})
+
+	// Assert
+	assert.EqualError(t, err, `unknown feed format "json", expected atom or rss`)
+}
+
+func TestReadTrackedList_InvalidID(t *testing.T) {
+	// Act
+	_, err := readTrackedList(strings.NewReader("12\nabc\n"))
+
+	// Assert
+	assert.EqualError(t, err, `invalid mod ID in tracked list: "abc"`)
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/logging"
+)
+
+var (
+	// logOptions holds the --verbose, --quiet, --log-format and --log-file flag values.
+	logOptions = logging.Options{}
+	// logger is the structured logger the commands log requests, extraction warnings
+	// and saves to, set up from the logging flags before each command runs.
+	logger = logging.Discard()
+	// logFile is the closer for the --log-file, closed once the command has run.
+	logFile io.Closer
+)
+
+// init registers the logging flags on every command.
+func init() {
+	flags := RootCmd.PersistentFlags()
+	flags.CountVarP(&logOptions.Verbosity, "verbose", "v", "Log more, -v for requests and saves and -vv for debug messages")
+	flags.BoolVarP(&logOptions.Quiet, "quiet", "q", false, "Only log errors")
+	flags.StringVar(&logOptions.Format, "log-format", logging.TextFormat, "Log format: "+logging.TextFormat+" or "+logging.JsonFormat)
+	flags.StringVar(&logOptions.File, "log-file", "", "Append logs to a file instead of stderr")
+}
+
+// prepareCommand sets the flags not given on the command line from the settings,
+// then sets up the output and the logger from the output and logging flags. Returns
+// an error if a setting is invalid, the log format is unknown or the log file cannot
+// be opened.
+func prepareCommand(cmd *cobra.Command, args []string) error {
+	if err := applySettings(cmd, args); err != nil {
+		return err
+	}
+	setupOutput(cmd)
+	return setupLogger(cmd)
+}
+
+// setupLogger creates the logger from the logging flags, writing to the command's
+// stderr unless a log file is given. Returns an error if the log format is unknown or
+// the log file cannot be opened.
+func setupLogger(cmd *cobra.Command) error {
+	newLogger, closer, err := logging.New(logOptions, cmd.ErrOrStderr())
+	if err != nil {
+		return err
+	}
+	logger, logFile = newLogger, closer
+	return nil
+}
+
+// closeLogFile closes the log file, if one was opened.
+func closeLogFile() error {
+	if logFile == nil {
+		return nil
+	}
+	err := logFile.Close()
+	logFile = nil
+	return err
+}
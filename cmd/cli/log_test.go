@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/logging"
+)
+
+// useLogOptions sets the logging flag values for the test, restoring the logger
+// afterwards.
+func useLogOptions(t *testing.T, opts logging.Options) {
+	t.Helper()
+	originalOptions, originalLogger := logOptions, logger
+	t.Cleanup(func() {
+		closeLogFile()
+		logOptions, logger = originalOptions, originalLogger
+	})
+	logOptions = opts
+}
+
+func TestSetupLogger_Stderr(t *testing.T) {
+	// Arrange
+	useLogOptions(t, logging.Options{Verbosity: 1, Format: logging.TextFormat})
+	cmd := &cobra.Command{}
+	var stderr bytes.Buffer
+	cmd.SetErr(&stderr)
+
+	// Act
+	err := setupLogger(cmd)
+	logger.Debug("hidden")
+	logger.Info("saved results", "path", "/mods/skyrim/mod 1.json")
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotContains(t, stderr.String(), "hidden")
+	assert.Contains(t, stderr.String(), `level=INFO msg="saved results" path="/mods/skyrim/mod 1.json"`)
+}
+
+func TestSetupLogger_File(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "scraper.log")
+	useLogOptions(t, logging.Options{Quiet: true, Format: logging.JsonFormat, File: path})
+	cmd := &cobra.Command{}
+	var stderr bytes.Buffer
+	cmd.SetErr(&stderr)
+
+	// Act
+	err := setupLogger(cmd)
+	logger.Warn("extraction warning")
+	logger.Error("command failed", "cookie", "nexusmods_session=secret")
+	require.NoError(t, closeLogFile())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, stderr.String())
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "extraction warning")
+	assert.NotContains(t, string(data), "secret")
+	assert.Contains(t, string(data), `"msg":"command failed","cookie":"[REDACTED]"`)
+}
+
+func TestPrepareCommand_InvalidLogFormat(t *testing.T) {
+	// Arrange
+	useConfigFile(t)
+	useLogOptions(t, logging.Options{Format: "xml"})
+
+	// Act
+	err := prepareCommand(&cobra.Command{}, nil)
+
+	// Assert
+	assert.EqualError(t, err, `unsupported log format "xml", use text or json`)
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/spinners"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/terminal"
+)
+
+var (
+	// noColor turns colors off, set with the --no-color flag.
+	noColor bool
+	// progress is where progress is written, the command's stderr, keeping stdout
+	// for the results.
+	progress io.Writer = os.Stderr
+)
+
+// init registers the --no-color flag on every command.
+func init() {
+	RootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Turn colors off, also set by the "+terminal.NoColorEnv+" environment variable")
+}
+
+// setupOutput writes the spinners to the command's stderr, animated only when it is
+// a terminal, and colors the results and progress only when they go to a terminal
+// and colors are not turned off with --no-color or NO_COLOR.
+func setupOutput(cmd *cobra.Command) {
+	progress = cmd.ErrOrStderr()
+	spinners.SetOutput(spinners.Output{
+		Writer:      progress,
+		Interactive: terminal.IsTerminal(progress),
+		Color:       terminal.Color(progress, noColor),
+	})
+	color.NoColor = !terminal.Color(cmd.OutOrStdout(), noColor)
+}
+
+// colorLink returns the path as a green, clickable link when output to w is colored,
+// and as is otherwise.
+func colorLink(w io.Writer, path string) string {
+	return terminal.Link(path, terminal.Color(w, noColor))
+}
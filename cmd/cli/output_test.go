@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/spinners"
+)
+
+func TestSetupOutput_NotTerminal(t *testing.T) {
+	// Arrange
+	originalProgress, originalNoColor := progress, color.NoColor
+	t.Cleanup(func() {
+		progress, color.NoColor = originalProgress, originalNoColor
+		spinners.SetOutput(spinners.Output{})
+	})
+
+	cmd := &cobra.Command{}
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+
+	// Act
+	setupOutput(cmd)
+	spinner := spinners.CreateSpinner("Saving results", "✓", "Results saved", "✗", "Failed to save results")
+	_ = spinner.Start()
+	spinner.StopMessage("Saved successfully to " + colorLink(progress, "/mods/skyrim/mod 1.json"))
+	_ = spinner.Stop()
+
+	// Assert
+	assert.True(t, color.NoColor)
+	assert.Empty(t, stdout.String(), "progress is kept off stdout")
+	assert.Equal(t, "Saving results\n✓ Saved successfully to /mods/skyrim/mod 1.json\n", stderr.String())
+}
@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/cli"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
+)
+
+// profileFlags holds the command-line flag values for the profile add command.
+type profileFlags struct {
+	BaseUrl         string
+	CookieDirectory string
+	CookieFile      string
+	OutputDirectory string
+	RateLimit       time.Duration
+}
+
+var (
+	// profileCmd is a Cobra command grouping the account profile commands.
+	profileCmd = &cobra.Command{}
+	// profileListCmd is a Cobra command used for listing the account profiles.
+	profileListCmd = &cobra.Command{}
+	// profileAddCmd is a Cobra command used for adding an account profile.
+	profileAddCmd = &cobra.Command{}
+	// profileRemoveCmd is a Cobra command used for removing an account profile.
+	profileRemoveCmd = &cobra.Command{}
+	// profileOptions holds the flag values for the profile add command.
+	profileOptions = profileFlags{}
+	// profileName is the account profile selected with the --profile flag.
+	profileName string
+	// profileNamePattern matches the profile names allowed in cookie filenames and
+	// configuration keys.
+	profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// init initializes the profile command and its list, add and remove subcommands,
+// setting their usage, description, and argument validation, adds them to the root
+// command, and registers the --profile flag on every command.
+func init() {
+	profileCmd = &cobra.Command{
+		Use:   "profile",
+		Short: "Manage the account profiles",
+		Long:  "Manage the named account profiles selected with --profile, each with its own cookie file, base URL, rate limit and output directory",
+	}
+
+	profileListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the account profiles",
+		Args:  cobra.NoArgs,
+		RunE:  runProfileList,
+	}
+
+	profileAddCmd = &cobra.Command{
+		Use:   "add <name> [flags]",
+		Short: "Add an account profile",
+		Long:  "Add a named account profile, with its own cookie file unless one is given",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runProfileAdd,
+	}
+
+	profileRemoveCmd = &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove an account profile",
+		Long:  "Remove a named account profile, leaving its cookie file and saved results in place",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runProfileRemove,
+	}
+
+	initProfileAddFlags(profileAddCmd)
+	profileCmd.AddCommand(profileListCmd, profileAddCmd, profileRemoveCmd)
+	RootCmd.AddCommand(profileCmd)
+	RootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Named account profile to use, see the profile command")
+}
+
+// initProfileAddFlags registers the command-line flags for the profile add command,
+// including the base URL, cookie directory and filename, output directory, and rate
+// limit used with the profile.
+func initProfileAddFlags(cmd *cobra.Command) {
+	cli.RegisterFlag(cmd, "base-url", "u", "", "Base url for the mods, the command's default when empty", &profileOptions.BaseUrl)
+	cli.RegisterFlag(cmd, "cookie-directory", "d", storage.GetDataStoragePath(), "Directory the profile's cookie file is stored in", &profileOptions.CookieDirectory)
+	cli.RegisterFlag(cmd, "cookie-filename", "f", "", "Filename where the profile's cookies are stored (default session-cookies-<name>.json)", &profileOptions.CookieFile)
+	cli.RegisterFlag(cmd, "output-directory", "o", "", "Output directory to save files, the command's default when empty", &profileOptions.OutputDirectory)
+	cli.RegisterFlag(cmd, "rate-limit", "", time.Duration(0), "Minimum time between page requests, 0 for no limit", &profileOptions.RateLimit)
+}
+
+// runProfileList prints the account profiles. Returns an error if the configuration
+// file cannot be read.
+func runProfileList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(configPath())
+	if err != nil {
+		return err
+	}
+	return printProfiles(cmd.OutOrStdout(), cfg)
+}
+
+// runProfileAdd adds the named profile to the configuration file, with a cookie file
+// of its own unless one is given. Returns an error if the name is not made of
+// letters, digits, dashes and underscores, the profile already exists, or the
+// configuration file cannot be read or written.
+func runProfileAdd(cmd *cobra.Command, args []string) error {
+	if !profileNamePattern.MatchString(args[0]) {
+		return fmt.Errorf("invalid profile name %q, use letters, digits, dashes and underscores", args[0])
+	}
+
+	path := configPath()
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+
+	profile := config.Profile{
+		BaseUrl:         profileOptions.BaseUrl,
+		CookieDirectory: profileOptions.CookieDirectory,
+		CookieFile:      profileOptions.CookieFile,
+		OutputDirectory: profileOptions.OutputDirectory,
+		RateLimit:       profileOptions.RateLimit,
+	}
+	if profile.CookieFile == "" {
+		profile.CookieFile = fmt.Sprintf("session-cookies-%s.json", args[0])
+	}
+	if err := cfg.AddProfile(args[0], profile); err != nil {
+		return err
+	}
+	if err := config.Save(path, cfg); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Added profile %s, extract its cookies with extract --profile %s\n", args[0], args[0])
+	return nil
+}
+
+// runProfileRemove removes the named profile from the configuration file. Returns an
+// error if the profile does not exist or the configuration file cannot be read or
+// written.
+func runProfileRemove(cmd *cobra.Command, args []string) error {
+	path := configPath()
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	if err := cfg.RemoveProfile(args[0]); err != nil {
+		return err
+	}
+	if err := config.Save(path, cfg); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Removed profile %s\n", args[0])
+	return nil
+}
+
+// printProfiles writes a table of the profiles, with each profile's name, base URL,
+// cookie file, output directory and rate limit. Returns an error if there are no
+// profiles or writing fails.
+func printProfiles(w io.Writer, cfg config.Config) error {
+	if len(cfg.Profiles) == 0 {
+		return errors.New("no profiles found, add one with profile add")
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tBASE URL\tCOOKIE FILE\tOUTPUT DIRECTORY\tRATE LIMIT")
+	for _, name := range cfg.ProfileNames() {
+		profile := cfg.Profiles[name]
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			name,
+			orDefault(profile.BaseUrl),
+			orDefault(filepath.Join(profile.CookieDirectory, profile.CookieFile)),
+			orDefault(profile.OutputDirectory),
+			profile.RateLimit,
+		)
+	}
+	return tw.Flush()
+}
+
+// orDefault returns the value, or "(default)" when it is empty.
+func orDefault(value string) string {
+	if value == "" {
+		return "(default)"
+	}
+	return value
+}
+
+// profileFlagValues returns the values the profile gives the command's flags, keyed
+// by flag name. The extract command writes the cookie file through its output flags.
+func profileFlagValues(cmd *cobra.Command, profile config.Profile) map[string]string {
+	values := map[string]string{
+		"base-url":         profile.BaseUrl,
+		"cookie-directory": profile.CookieDirectory,
+		"cookie-filename":  profile.CookieFile,
+		"output-directory": profile.OutputDirectory,
+	}
+	if profile.RateLimit > 0 {
+		values["rate-limit"] = profile.RateLimit.String()
+	}
+
+	if cmd == extractCmd {
+		values = map[string]string{
+			"base-url":         profile.BaseUrl,
+			"output-directory": profile.CookieDirectory,
+			"output-filename":  profile.CookieFile,
+		}
+	}
+	return values
+}
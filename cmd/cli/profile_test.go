@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/config"
+)
+
+// useConfigFile points the commands at a configuration file in a temporary directory
+// for the test.
+func useConfigFile(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	originalConfigPath := configPath
+	t.Cleanup(func() { configPath = originalConfigPath })
+	configPath = func() string { return path }
+	return path
+}
+
+func TestRunProfileAdd(t *testing.T) {
+	// Arrange
+	path := useConfigFile(t)
+	profileOptions = profileFlags{CookieDirectory: "/cookies", RateLimit: 2 * time.Second}
+
+	cmd := &cobra.Command{}
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	// Act
+	err := runProfileAdd(cmd, []string{"team"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Added profile team, extract its cookies with extract --profile team\n", out.String())
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, config.Profile{CookieDirectory: "/cookies", CookieFile: "session-cookies-team.json", RateLimit: 2 * time.Second}, cfg.Profiles["team"])
+
+	assert.EqualError(t, runProfileAdd(cmd, []string{"team"}), `profile "team" already exists, remove it first`)
+}
+
+func TestRunProfileAdd_InvalidName(t *testing.T) {
+	for _, name := range []string{"", "../team", "team/other", `team\other`, "..", "my team"} {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			path := useConfigFile(t)
+			profileOptions = profileFlags{}
+
+			// Act
+			err := runProfileAdd(&cobra.Command{}, []string{name})
+
+			// Assert
+			assert.EqualError(t, err, fmt.Sprintf("invalid profile name %q, use letters, digits, dashes and underscores", name))
+			assert.NoFileExists(t, path)
+		})
+	}
+}
+
+func TestRunProfileList(t *testing.T) {
+	// Arrange
+	path := useConfigFile(t)
+	require.NoError(t, config.Save(path, config.Config{Profiles: map[string]config.Profile{
+		"team":     {BaseUrl: "https://team.example", CookieDirectory: "/cookies", CookieFile: "team.json", RateLimit: time.Second},
+		"personal": {CookieDirectory: "/cookies", CookieFile: "personal.json", OutputDirectory: "/mods"},
+	}}))
+
+	cmd := &cobra.Command{}
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	// Act
+	err := runProfileList(cmd, nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, ""+
+		"NAME      BASE URL              COOKIE FILE             OUTPUT DIRECTORY  RATE LIMIT\n"+
+		"personal  (default)             /cookies/personal.json  /mods             0s\n"+
+		"team      https://team.example  /cookies/team.json      (default)         1s\n", out.String())
+}
+
+func TestRunProfileList_Empty(t *testing.T) {
+	// Arrange
+	useConfigFile(t)
+
+	// Act
+	err := runProfileList(&cobra.Command{}, nil)
+
+	// Assert
+	assert.EqualError(t, err, "no profiles found, add one with profile add")
+}
+
+func TestRunProfileRemove(t *testing.T) {
+	// Arrange
+	path := useConfigFile(t)
+	require.NoError(t, config.Save(path, config.Config{Profiles: map[string]config.Profile{"team": {}}}))
+
+	cmd := &cobra.Command{}
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	// Act
+	err := runProfileRemove(cmd, []string{"team"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Removed profile team\n", out.String())
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Profiles)
+	assert.EqualError(t, runProfileRemove(cmd, []string{"team"}), `profile "team" not found`)
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/cli"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
+	"github.com/ondrovic/nexus-mods-scraper/pkg/nexus"
+)
+
+// requestFlags holds the command-line flag values shared by the commands that send
+// requests to the site: the base URL, the cookie file, the rate limit and request
+// timeout, and the proxy, User-Agent, CA certificate and extra headers.
+type requestFlags struct {
+	BaseUrl         string
+	CACert          string
+	CookieDirectory string
+	CookieFile      string
+	Headers         []string
+	Proxy           string
+	RateLimit       time.Duration
+	RequestTimeout  time.Duration
+	UserAgent       string
+}
+
+// registerRequestFlags registers the request flags on the command, binding them to
+// the fields of flags.
+func registerRequestFlags(cmd *cobra.Command, flags *requestFlags) {
+	cli.RegisterFlag(cmd, "base-url", "u", "https://nexusmods.com", "Base url for the mods", &flags.BaseUrl)
+	cli.RegisterFlag(cmd, "cookie-directory", "d", storage.GetDataStoragePath(), "Directory your cookie file is stored in", &flags.CookieDirectory)
+	cli.RegisterFlag(cmd, "cookie-filename", "f", "session-cookies.json", "Filename where the cookies are stored", &flags.CookieFile)
+	cli.RegisterFlag(cmd, "rate-limit", "", time.Duration(0), "Minimum time between page requests, 0 for no limit", &flags.RateLimit)
+	cli.RegisterFlag(cmd, "request-timeout", "", 30*time.Second, "Maximum time for a single page request, 0 for no limit", &flags.RequestTimeout)
+	cli.RegisterFlag(cmd, "ca-cert", "", "", "PEM file of CA certificates to trust along with the system's", &flags.CACert)
+	cli.RegisterFlag(cmd, "header", "", []string{}, "Extra header to send with every request, as \"Name: value\", repeatable", &flags.Headers)
+	cli.RegisterFlag(cmd, "proxy", "", "", "http, https or socks5 proxy URL, HTTPS_PROXY and HTTP_PROXY when empty", &flags.Proxy)
+	cli.RegisterFlag(cmd, "user-agent", "", httpclient.DefaultUserAgent, "User-Agent to send with every request", &flags.UserAgent)
+}
+
+// transportOptions returns the proxy, User-Agent, CA certificate and header options
+// of the request flags.
+func (f requestFlags) transportOptions() httpclient.TransportOptions {
+	return httpclient.TransportOptions{
+		Proxy:     f.Proxy,
+		UserAgent: f.UserAgent,
+		CACert:    f.CACert,
+		Headers:   f.Headers,
+	}
+}
+
+// newScraper creates a scraper from the request flags, using the cookie file and
+// limiting requests to the rate limit, along with any extra options. Returns an error
+// if the transport options are invalid or the cookie file cannot be loaded.
+func (f requestFlags) newScraper(extraOpts ...nexus.Option) (*nexus.Scraper, error) {
+	opts := append([]nexus.Option{nexus.WithRateLimit(f.RateLimit)}, extraOpts...)
+	return newScraper(f.BaseUrl, f.CookieDirectory, f.CookieFile, f.RequestTimeout, f.transportOptions(), fetchModInfoFunc, fetchDocumentFunc, opts...)
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterRequestFlags(t *testing.T) {
+	// Arrange
+	cmd := &cobra.Command{}
+	var flags requestFlags
+
+	// Act
+	registerRequestFlags(cmd, &flags)
+	err := cmd.ParseFlags([]string{"-u", "https://example.com", "--rate-limit", "2s", "--header", "Accept-Language: en"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com", flags.BaseUrl)
+	assert.Equal(t, "2s", flags.RateLimit.String())
+	assert.Equal(t, []string{"Accept-Language: en"}, flags.transportOptions().Headers)
+	for _, command := range []*cobra.Command{feedCmd, serveCmd, authStatusCmd} {
+		for _, name := range []string{"base-url", "cookie-directory", "cookie-filename", "rate-limit", "request-timeout", "proxy", "user-agent", "ca-cert", "header"} {
+			assert.NotNil(t, command.Flags().Lookup(name), "%s --%s", command.Name(), name)
+		}
+	}
+}
@@ -1,22 +1,39 @@
 package cli
 
 import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/spf13/cobra"
 )
 
 // RootCmd is the main Cobra command for the scraper CLI tool, providing a short
 // description and setting up the command's usage for scraping Nexus Mods and returning
-// the information in JSON format.
+// the information in JSON format. Before any command runs, the flags not given on the
+// command line are set from the environment, the profile selected with --profile and
+// the configuration file, and the logger is set up.
 var RootCmd = &cobra.Command{
-	Use:   "nexus-mods-scraper",
-	Short: "A CLI tool to scrape https://nexusmods.com mods and return the information in JSON format",
+	Use:               "nexus-mods-scraper",
+	Short:             "A CLI tool to scrape https://nexusmods.com mods and return the information in JSON format",
+	PersistentPreRunE: prepareCommand,
 }
 
 // Execute runs the RootCmd command, handling any errors that occur during its execution.
+// The command's context is cancelled on Ctrl-C or SIGTERM so in-flight requests abort.
+// Failures are logged to the log file, which is closed once the command has run.
 // Returns an error if the command fails to execute.
 func Execute() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	defer closeLogFile()
 
-	if err := RootCmd.Execute(); err != nil {
+	if err := RootCmd.ExecuteContext(ctx); err != nil {
+		// The error is printed to stderr, so it is only logged when logs go to a file
+		if logOptions.File != "" {
+			logger.Error("command failed", "error", err)
+		}
 		return err
 	}
 
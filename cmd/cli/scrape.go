@@ -1,12 +1,15 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"os"
+	"text/template"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
-	"github.com/savioxavier/termlink"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 
 	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
 	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
@@ -15,8 +18,12 @@ import (
 	"github.com/ondrovic/nexus-mods-scraper/internal/utils/cli"
 	"github.com/ondrovic/nexus-mods-scraper/internal/utils/exporters"
 	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/notifiers"
 	"github.com/ondrovic/nexus-mods-scraper/internal/utils/spinners"
 	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/stores"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/templates"
+	"github.com/ondrovic/nexus-mods-scraper/pkg/nexus"
 
 	"path/filepath"
 	"strings"
@@ -31,12 +38,13 @@ var (
 	// concurrently fetching mod information.
 	fetchModInfoFunc = fetchers.FetchModInfoConcurrent
 	// fetchDocumentFunc is a variable that holds a reference to the function used for
-	// fetching HTML documents from a given URL.
-	fetchDocumentFunc = fetchers.FetchDocument
+	// fetching HTML documents from a given URL. When nil the scraper fetches documents
+	// with its own cookie-backed HTTP client.
+	fetchDocumentFunc func(ctx context.Context, targetURL string) (*goquery.Document, error)
 )
 
 // init initializes the scrape command with usage, description, and argument validation.
-// It binds flags using Viper and adds the command to the root command for execution.
+// It registers the flags and adds the command to the root command for execution.
 func init() {
 	scrapeCmd = &cobra.Command{
 		Use:   "scrape <game name> <mod id> [flags]",
@@ -47,28 +55,56 @@ func init() {
 	}
 
 	initScrapeFlags(scrapeCmd)
-	viper.BindPFlags(scrapeCmd.Flags())
 	RootCmd.AddCommand(scrapeCmd)
 }
 
 // initScrapeFlags registers the command-line flags for the scrape command, including
-// options for the base URL, cookie directory, cookie filename, result display and save
-// options, output directory, and valid cookie names. It binds these flags to the
-// corresponding fields in the CliFlags struct.
+// options for the base URL, session check, cookie directory, cookie filename, result
+// display and save options and their formats, saved pages to scrape offline, change
+// notifications, output directory, page concurrency and per-host limit, rate limit,
+// proxy, User-Agent, CA certificate and extra headers, request cassettes, timeouts,
+// results store, spreadsheet table options, and valid cookie names. It binds these
+// flags to the corresponding fields in the CliFlags struct.
 func initScrapeFlags(cmd *cobra.Command) {
 	cli.RegisterFlag(cmd, "base-url", "u", "https://nexusmods.com", "Base url for the mods", &options.BaseUrl)
+	cli.RegisterFlag(cmd, "concurrency", "", 2, "Number of pages of the mod requested at once", &options.Concurrency)
+	cli.RegisterFlag(cmd, "check-session", "", true, "Check the session cookies are logged in before scraping", &options.CheckSession)
 	cli.RegisterFlag(cmd, "cookie-directory", "d", storage.GetDataStoragePath(), "Directory your cookie file is stored in", &options.CookieDirectory)
 	cli.RegisterFlag(cmd, "cookie-filename", "f", "session-cookies.json", "Filename where the cookies are stored", &options.CookieFile)
 	cli.RegisterFlag(cmd, "display-results", "r", false, "Do you want to display the results in the terminal?", &options.DisplayResults)
+	cli.RegisterFlag(cmd, "format", "", formatters.JsonFormat, fmt.Sprintf("Output format for displayed results: %s", strings.Join(formatters.FormatNames(), ", ")), &options.Format)
+	cli.RegisterFlag(cmd, "save-format", "", formatters.JsonFormat, fmt.Sprintf("Output format for saved results: %s", strings.Join(formatters.FormatNames(), ", ")), &options.SaveFormat)
+	cli.RegisterFlag(cmd, "from-html", "", "", "Scrape a saved mod page instead of fetching it, no cookies or network needed", &options.FromHtml)
+	cli.RegisterFlag(cmd, "files-html", "", "", "Saved files tab page to use with --from-html", &options.FilesHtml)
+	cli.RegisterFlag(cmd, "from-dir", "", "", "Scrape saved pages from a directory holding <mod id>.html and <mod id>-files.html", &options.FromDir)
+	cli.RegisterFlag(cmd, "notify", "", []string{}, "Notify sinks of new versions or changelogs: webhook=<url>, discord=<url>, slack=<url> or command=<command>", &options.Notify)
+	cli.RegisterFlag(cmd, "notify-dry-run", "", false, "Print notifications instead of sending them", &options.NotifyDryRun)
+	cli.RegisterFlag(cmd, "notify-retries", "", 3, "Number of times to retry a failed notification", &options.NotifyRetries)
+	cli.RegisterFlag(cmd, "notify-template", "", "", "text/template file or inline template for notification messages", &options.NotifyTemplate)
 	cli.RegisterFlag(cmd, "save-results", "s", false, "Do you want to save the results to a JSON file?", &options.SaveResults)
 	cli.RegisterFlag(cmd, "output-directory", "o", storage.GetDataStoragePath(), "Output directory to save files", &options.OutputDirectory)
+	cli.RegisterFlag(cmd, "ca-cert", "", "", "PEM file of CA certificates to trust along with the system's", &options.CACert)
+	cli.RegisterFlag(cmd, "header", "", []string{}, "Extra header to send with every request, as \"Name: value\", repeatable", &options.Headers)
+	cli.RegisterFlag(cmd, "per-host", "", 0, "Most requests sent to the same host at once, 0 for no limit", &options.PerHost)
+	cli.RegisterFlag(cmd, "proxy", "", "", "http, https or socks5 proxy URL, HTTPS_PROXY and HTTP_PROXY when empty", &options.Proxy)
+	cli.RegisterFlag(cmd, "rate-limit", "", time.Duration(0), "Minimum time between page requests, 0 for no limit", &options.RateLimit)
+	cli.RegisterFlag(cmd, "record", "", "", "Record every request and response into a cassette directory, with cookies scrubbed", &options.Record)
+	cli.RegisterFlag(cmd, "replay", "", "", "Replay the requests recorded in a cassette directory instead of using the network", &options.Replay)
+	cli.RegisterFlag(cmd, "request-timeout", "", 30*time.Second, "Maximum time for a single page request, 0 for no limit", &options.RequestTimeout)
+	cli.RegisterFlag(cmd, "timeout", "", time.Duration(0), "Maximum time for the whole scrape, 0 for no limit", &options.Timeout)
+	cli.RegisterFlag(cmd, "store", "", stores.JsonStore, "Where to save results: json or sqlite:<path>", &options.Store)
+	cli.RegisterFlag(cmd, "table-format", "", "", "Also save mods and files sheets as csv or tsv", &options.TableFormat)
+	cli.RegisterFlag(cmd, "template", "", "", fmt.Sprintf("Render results through a text/template file or example (%s)", strings.Join(templates.Examples(), ", ")), &options.Template)
+	cli.RegisterFlag(cmd, "mod-columns", "", exporters.DefaultModColumns, "Columns to include in the mods sheet", &options.ModColumns)
+	cli.RegisterFlag(cmd, "file-columns", "", exporters.DefaultFileColumns, "Columns to include in the files sheet", &options.FileColumns)
+	cli.RegisterFlag(cmd, "join-separator", "", "; ", "Separator used when joining tags, requirements and changelogs in the sheets", &options.JoinSeparator)
+	cli.RegisterFlag(cmd, "user-agent", "", httpclient.DefaultUserAgent, "User-Agent to send with every request", &options.UserAgent)
 	cli.RegisterFlag(cmd, "valid-cookie-names", "c", []string{"nexusmods_session", "nexusmods_session_refresh"}, "Names of the cookies to extract", &options.ValidCookies)
 }
 
 // run executes the scrape command, validating that either display or save results
-// options are enabled. It parses the mod ID and game name from the arguments, reads
-// the configuration values from Viper, and then calls the scrapeMod function with
-// the populated CliFlags.
+// options are enabled. It parses the mod ID and game name from the arguments, checks
+// the flag values, and then calls the scrapeMod function with the populated CliFlags.
 func run(cmd *cobra.Command, args []string) error {
 	if !options.DisplayResults && !options.SaveResults {
 		return fmt.Errorf("at least one of --display-results (-r) or --save-results (-s) must be enabled")
@@ -77,46 +113,133 @@ func run(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-
-	scraper := types.CliFlags{
-		BaseUrl:         viper.GetString("base-url"),
-		CookieDirectory: viper.GetString("cookie-directory"),
-		CookieFile:      viper.GetString("cookie-filename"),
-		DisplayResults:  viper.GetBool("display-results"),
-		GameName:        args[0],
-		ModID:           modID,
-		SaveResults:     viper.GetBool("save-results"),
-		OutputDirectory: viper.GetString("output-directory"),
-		ValidCookies:    viper.GetStringSlice("valid-cookie-names"),
+	if options.FromHtml != "" && options.FromDir != "" {
+		return fmt.Errorf("only one of --from-html or --from-dir can be used")
+	}
+	if options.FilesHtml != "" && options.FromHtml == "" {
+		return fmt.Errorf("--files-html requires --from-html")
+	}
+	if options.Record != "" && options.Replay != "" {
+		return fmt.Errorf("only one of --record or --replay can be used")
+	}
+	if (options.Record != "" || options.Replay != "") && (options.FromHtml != "" || options.FromDir != "") {
+		return fmt.Errorf("--record and --replay cannot be used with saved pages")
+	}
+	for _, name := range []string{options.Format, options.SaveFormat} {
+		if _, err := formatters.LookupFormat(name); err != nil {
+			return err
+		}
+	}
+	spec, err := stores.ParseStoreSpec(options.Store)
+	if err != nil {
+		return err
+	}
+	// Changes are found by loading the mod as last saved in the save format
+	if len(options.Notify) > 0 && spec.Kind != stores.SqliteStore {
+		if format, _ := formatters.LookupFormat(options.SaveFormat); format.Parse == nil {
+			return fmt.Errorf("--notify cannot load results saved as %s, use another --save-format or a sqlite --store", format.Name)
+		}
+	}
+	if options.NotifyRetries < 0 {
+		return fmt.Errorf("--notify-retries must not be negative")
+	}
+	if tableFormat := options.TableFormat; tableFormat != "" {
+		if _, err := exporters.TableExtension(tableFormat); err != nil {
+			return err
+		}
+	}
+	for _, spec := range options.Notify {
+		if _, err := notifiers.ParseSink(spec, nil); err != nil {
+			return err
+		}
+	}
+	if _, err := loadNotifyTemplate(options.NotifyTemplate); err != nil {
+		return err
 	}
 
-	return scrapeMod(scraper, fetchModInfoFunc, fetchDocumentFunc)
+	// The flags hold the effective values, with the environment, profile and
+	// configuration file applied before the command runs
+	scraper := options
+	scraper.GameName = args[0]
+	scraper.ModID = modID
+
+	return scrapeMod(commandContext(cmd), scraper, fetchModInfoFunc, fetchDocumentFunc)
 }
 
 // scrapeMod orchestrates the process of scraping mod information, including setting up
 // the HTTP client, scraping mod info, displaying results, and saving results based on
 // the provided command-line flags. It uses spinners to indicate progress throughout the
 // operations and accepts functions for fetching mod info and documents, returning an error
-// if any step fails.
+// if any step fails. Requests are aborted when ctx is cancelled or the --timeout passes.
+// When saved pages are given they are read instead, without cookies or requests, and
+// a cassette records the requests or replays them without the network. Cookies the
+// site sets are saved back to the cookie file.
 func scrapeMod(
+	ctx context.Context,
 	sc types.CliFlags,
-	fetchModInfoFunc func(baseUrl, game string, modId int64, concurrentFetch func(tasks ...func() error) error, fetchDocument func(targetURL string) (*goquery.Document, error)) (types.Results, error),
-	fetchDocumentFunc func(targetURL string) (*goquery.Document, error),
+	fetchModInfoFunc func(ctx context.Context, baseUrl, game string, modId int64, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error),
+	fetchDocumentFunc func(ctx context.Context, targetURL string) (*goquery.Document, error),
 ) error {
+	if sc.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sc.Timeout)
+		defer cancel()
+	}
+
+	// The output template is loaded once, before scraping, for both display and saving
+	var tmpl *templates.Template
+	if sc.Template != "" {
+		loaded, err := templates.Load(sc.Template)
+		if err != nil {
+			return err
+		}
+		tmpl = loaded
+	}
+
 	// Create and start the main spinner for HTTP client setup
 	httpSpinner := spinners.CreateSpinner("Setting up HTTP client", "✓", "HTTP client setup complete", "✗", "HTTP client setup failed")
 	if err := httpSpinner.Start(); err != nil {
 		return fmt.Errorf("failed to start spinner: %w", err)
 	}
 
+	// Offline mode reads saved pages and replaying reads a cassette, so no cookies are needed
+	cookieFile := sc.CookieFile
+	checkSession := sc.CheckSession
+	if offlineFetch := offlineDocumentFetcher(sc); offlineFetch != nil {
+		fetchDocumentFunc = offlineFetch
+		cookieFile = ""
+		checkSession = false
+	}
+	extraOpts := []nexus.Option{nexus.WithRateLimit(sc.RateLimit), nexus.WithConcurrency(sc.Concurrency, sc.PerHost)}
+	transport, err := cassetteTransport(sc)
+	if err != nil {
+		httpSpinner.StopFailMessage(fmt.Sprintf("Error setting up HTTP client: %v", err))
+		httpSpinner.StopFail()
+		return err
+	}
+	if transport != nil {
+		extraOpts = append(extraOpts, nexus.WithHTTPClient(&http.Client{Transport: transport}))
+		if sc.Replay != "" {
+			cookieFile = ""
+		}
+	}
+
 	// HTTP Client Setup
-	if err := httpclient.InitClient(sc.BaseUrl, sc.CookieDirectory, sc.CookieFile); err != nil {
+	scraper, err := newScraper(sc.BaseUrl, sc.CookieDirectory, cookieFile, sc.RequestTimeout, transportOptions(sc), fetchModInfoFunc, fetchDocumentFunc, extraOpts...)
+	if err != nil {
 		httpSpinner.StopFailMessage(fmt.Sprintf("Error setting up HTTP client: %v", err))
 		httpSpinner.StopFail()
 		return err
 	}
 	httpSpinner.Stop()
 
+	// Session Check
+	if checkSession {
+		if err := checkScrapeSession(ctx, scraper); err != nil {
+			return err
+		}
+	}
+
 	// Create and start the spinner for scraping mod info
 	scrapeSpinner := spinners.CreateSpinner(fmt.Sprintf("Scraping modID: %d for game: %s", sc.ModID, sc.GameName), "✓", "Mod scraping complete", "✗", "Mod scraping failed")
 	if err := scrapeSpinner.Start(); err != nil {
@@ -124,14 +247,29 @@ func scrapeMod(
 	}
 
 	// Scrape Mod Info
-	results, err := fetchModInfoFunc(sc.BaseUrl, sc.GameName, sc.ModID, utils.ConcurrentFetch, fetchDocumentFunc)
+	results, err := scraper.ScrapeMod(ctx, sc.GameName, sc.ModID)
+	if saveErr := saveSessionCookies(scraper, cookieFile); saveErr != nil && err == nil {
+		err = saveErr
+	}
 	if err != nil {
+		if ctx.Err() != nil {
+			err = fmt.Errorf("scrape aborted: %w", ctx.Err())
+		}
 		scrapeSpinner.StopFailMessage(fmt.Sprintf("Error scraping mod: %v", err))
 		scrapeSpinner.StopFail()
 		return err
 	}
 	scrapeSpinner.Stop()
 
+	// Load the previous snapshot before saving replaces it
+	var previous types.ModInfo
+	var hasPrevious bool
+	if len(sc.Notify) > 0 {
+		if previous, hasPrevious, err = loadPreviousResults(sc); err != nil {
+			return err
+		}
+	}
+
 	// Display Results
 	if sc.DisplayResults {
 		displaySpinner := spinners.CreateSpinner("Displaying results", "✓", "Results displayed", "✗", "Failed to display results")
@@ -141,8 +279,8 @@ func scrapeMod(
 		displaySpinner.Stop() // Temporarily stop spinner for clean output
 
 		// Print the results
-		if err := exporters.DisplayResults(sc, results, formatters.FormatResultsAsJson); err != nil {
-			fmt.Println("Error displaying results:", err)
+		if err := displayResults(sc, tmpl, results); err != nil {
+			fmt.Fprintln(progress, "Error displaying results:", err)
 			displaySpinner.StopFail()
 			return err
 		}
@@ -156,24 +294,325 @@ func scrapeMod(
 			return fmt.Errorf("failed to start save spinner: %w", err)
 		}
 
-		outputGameDirectory := filepath.Join(sc.OutputDirectory, strings.ToLower(sc.GameName))
-		if err := utils.EnsureDirExists(outputGameDirectory); err != nil {
-			saveSpinner.StopFailMessage(fmt.Sprintf("Error creating directory: %v", err))
+		items, err := saveResults(sc, tmpl, results)
+		if err != nil {
+			saveSpinner.StopFailMessage(fmt.Sprintf("Error saving results: %v", err))
 			saveSpinner.StopFail()
 			return err
 		}
 
-		outputFilename := fmt.Sprintf("%s %d", strings.ToLower(results.Mods.Name), results.Mods.ModID)
-		if item, err := exporters.SaveModInfoToJson(sc, results, outputGameDirectory, outputFilename, utils.EnsureDirExists); err != nil {
-			saveSpinner.StopFailMessage(fmt.Sprintf("Error saving results: %v", err))
-			saveSpinner.StopFail()
-			return err
-		} else {
-			// saveSpinner.StopMessage(fmt.Sprintf("Saved successfully to %s", item))
-			saveSpinner.StopMessage(fmt.Sprintf("Saved successfully to %s", termlink.ColorLink(item, item, "green")))
+		links := make([]string, 0, len(items))
+		for _, item := range items {
+			logger.Info("saved results", "game", sc.GameName, "mod_id", sc.ModID, "path", item)
+			links = append(links, colorLink(progress, item))
 		}
+		saveSpinner.StopMessage(fmt.Sprintf("Saved successfully to %s", strings.Join(links, ", ")))
 		saveSpinner.Stop()
 	}
 
+	// Notify Changes
+	if hasPrevious {
+		if err := notifyChanges(ctx, sc, previous, results); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+// newScraper creates a scraper for the base URL using the cookies saved in the cookie
+// file, when one is given, sending requests through the transport built from the
+// transport options, logging to the command's logger, and limiting each page request
+// to requestTimeout, scraping with fetchModInfoFunc and, when it is not nil, fetching
+// documents with fetchDocumentFunc.
+// Any extra options are applied last. Returns an error if the transport options are
+// invalid or the cookie file cannot be loaded.
+func newScraper(
+	baseUrl, cookieDirectory, cookieFile string,
+	requestTimeout time.Duration,
+	transport httpclient.TransportOptions,
+	fetchModInfoFunc nexus.ModFetcher,
+	fetchDocumentFunc nexus.DocumentFetcher,
+	extraOpts ...nexus.Option,
+) (*nexus.Scraper, error) {
+	transport.Logger = logger
+	roundTripper, err := httpclient.NewTransport(transport)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []nexus.Option{
+		nexus.WithBaseURL(baseUrl),
+		nexus.WithLogger(logger),
+		nexus.WithHTTPClient(&http.Client{Transport: roundTripper}),
+		nexus.WithRequestTimeout(requestTimeout),
+		nexus.WithModFetcher(fetchModInfoFunc),
+	}
+	if cookieFile != "" {
+		opts = append(opts, nexus.WithCookieFile(cookieDirectory, cookieFile), nexus.WithSessionRefresh("", nil))
+	}
+	if fetchDocumentFunc != nil {
+		opts = append(opts, nexus.WithDocumentFetcher(fetchDocumentFunc))
+	}
+
+	return nexus.New(append(opts, extraOpts...)...)
+}
+
+// saveSessionCookies writes the cookies the site set during the run back to the
+// cookie file, so the next run starts from the latest session. Does nothing when no
+// cookie file is used. Returns an error if the cookie file cannot be written.
+func saveSessionCookies(scraper *nexus.Scraper, cookieFile string) error {
+	if cookieFile == "" {
+		return nil
+	}
+	if err := scraper.SaveCookies(); err != nil {
+		return fmt.Errorf("error saving session cookies: %w", err)
+	}
+	return nil
+}
+
+// offlineDocumentFetcher returns a fetcher reading the saved pages given by the
+// --from-html and --files-html or --from-dir flags, or nil when none are set.
+func offlineDocumentFetcher(sc types.CliFlags) nexus.DocumentFetcher {
+	switch {
+	case sc.FromHtml != "":
+		return fetchers.FileDocumentFetcher(sc.FromHtml, sc.FilesHtml)
+	case sc.FromDir != "":
+		return fetchers.DirDocumentFetcher(sc.FromDir)
+	default:
+		return nil
+	}
+}
+
+// checkScrapeSession confirms the scraper's session cookies are logged in before
+// scraping, so an anonymous or expired session fails early with a clear message.
+// Returns an error if the session cannot be checked or cannot be used.
+func checkScrapeSession(ctx context.Context, scraper *nexus.Scraper) error {
+	sessionSpinner := spinners.CreateSpinner("Checking session", "✓", "Session is logged in", "✗", "Session check failed")
+	if err := sessionSpinner.Start(); err != nil {
+		return fmt.Errorf("failed to start spinner: %w", err)
+	}
+
+	session, err := scraper.CheckSession(ctx)
+	if err == nil {
+		err = session.Validate(timeNow())
+	}
+	if err != nil {
+		sessionSpinner.StopFailMessage(fmt.Sprintf("Error checking session: %v", err))
+		sessionSpinner.StopFail()
+		return err
+	}
+
+	sessionSpinner.StopMessage(fmt.Sprintf("Logged in as %s", session.Account.Username))
+	sessionSpinner.Stop()
+	return nil
+}
+
+// cassetteTransport returns a transport recording requests into the --record
+// cassette, sending them through the configured transport, or replaying them from the
+// --replay cassette, or nil when neither is set. Returns an error if the transport
+// options are invalid.
+func cassetteTransport(sc types.CliFlags) (http.RoundTripper, error) {
+	switch {
+	case sc.Replay != "":
+		return httpclient.NewLoggingTransport(httpclient.NewReplayer(sc.Replay), logger), nil
+	case sc.Record != "":
+		next, err := httpclient.NewTransport(transportOptions(sc))
+		if err != nil {
+			return nil, err
+		}
+		return httpclient.NewRecorder(sc.Record, next, httpclient.HeaderNames(sc.Headers)...), nil
+	default:
+		return nil, nil
+	}
+}
+
+// transportOptions returns the proxy, User-Agent, CA certificate and header options
+// of the scrape flags, logging requests to the command's logger.
+func transportOptions(sc types.CliFlags) httpclient.TransportOptions {
+	return httpclient.TransportOptions{
+		Proxy:     sc.Proxy,
+		UserAgent: sc.UserAgent,
+		CACert:    sc.CACert,
+		Headers:   sc.Headers,
+		Logger:    logger,
+	}
+}
+
+// displayResults prints the results to the terminal, rendered through the output
+// template when one is loaded or in the selected output format otherwise. Returns an
+// error if the format is unknown or rendering fails.
+func displayResults(sc types.CliFlags, tmpl *templates.Template, results types.Results) error {
+	if tmpl == nil {
+		return exporters.DisplayResults(sc, results)
+	}
+
+	rendered, err := tmpl.Render(results)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(rendered)
+	return nil
+}
+
+// saveResults persists the results to the store selected by the --store flag,
+// either a file in the save format in the game's output directory or a SQLite
+// database, and writes the rendered output template and mods and files sheets
+// next to it when they are set. Returns the locations the results were written to,
+// or an error if saving fails.
+func saveResults(sc types.CliFlags, tmpl *templates.Template, results types.Results) ([]string, error) {
+	spec, err := stores.ParseStoreSpec(sc.Store)
+	if err != nil {
+		return nil, err
+	}
+
+	outputGameDirectory := filepath.Join(sc.OutputDirectory, strings.ToLower(sc.GameName))
+	outputFilename := fmt.Sprintf("%s %d", strings.ToLower(results.Mods.Name), results.Mods.ModID)
+
+	var items []string
+	if spec.Kind == stores.SqliteStore {
+		item, err := stores.SaveModInfoToSqlite(spec.Path, sc.GameName, results, utils.EnsureDirExists)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	} else {
+		if err := utils.EnsureDirExists(outputGameDirectory); err != nil {
+			return nil, fmt.Errorf("error creating directory: %w", err)
+		}
+
+		item, err := exporters.SaveModInfo(sc, results, outputGameDirectory, outputFilename, utils.EnsureDirExists)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if tmpl != nil {
+		item, err := exporters.SaveModInfoToTemplate(results, tmpl, outputGameDirectory, outputFilename, utils.EnsureDirExists)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if sc.TableFormat != "" {
+		tableOptions := exporters.TableOptions{
+			Format:        sc.TableFormat,
+			ModColumns:    sc.ModColumns,
+			FileColumns:   sc.FileColumns,
+			JoinSeparator: sc.JoinSeparator,
+		}
+
+		sheets, err := exporters.SaveModInfoToTables(strings.ToLower(sc.GameName), []types.ModInfo{results.Mods}, outputGameDirectory, outputFilename, tableOptions, utils.EnsureDirExists)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, sheets...)
+	}
+
+	return items, nil
+}
+
+// loadPreviousResults returns the mod as it was last saved to the store selected by
+// the --store flag. The boolean is false when the mod has not been saved before.
+func loadPreviousResults(sc types.CliFlags) (types.ModInfo, bool, error) {
+	spec, err := stores.ParseStoreSpec(sc.Store)
+	if err != nil {
+		return types.ModInfo{}, false, err
+	}
+
+	if spec.Kind == stores.SqliteStore {
+		return stores.LoadModInfoFromSqlite(spec.Path, sc.GameName, sc.ModID)
+	}
+
+	saved, ok, err := storage.LoadSavedMod(filepath.Join(sc.OutputDirectory, strings.ToLower(sc.GameName)), sc.ModID, sc.SaveFormat, logger)
+	return saved.Mods, ok, err
+}
+
+// notifyClient returns the HTTP client notifications are sent with, going through the
+// transport built from the proxy, User-Agent, CA certificate and header flags and
+// limited to the request timeout. Returns an error if the transport options are
+// invalid.
+func notifyClient(sc types.CliFlags) (*http.Client, error) {
+	transport, err := httpclient.NewTransport(transportOptions(sc))
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{Transport: transport, Timeout: sc.RequestTimeout}, nil
+}
+
+// loadNotifyTemplate parses the notification message template, read from the file
+// at nameOrSource when it exists and used as inline template text otherwise.
+func loadNotifyTemplate(nameOrSource string) (*template.Template, error) {
+	if data, err := os.ReadFile(nameOrSource); err == nil {
+		nameOrSource = string(data)
+	}
+
+	return notifiers.ParseMessageTemplate(nameOrSource)
+}
+
+// notifyChanges compares the scraped mod with its previous snapshot and, when a new
+// version or changelog was found, sends a notification to every --notify sink.
+// Returns an error if a sink cannot be created or a notification fails.
+func notifyChanges(ctx context.Context, sc types.CliFlags, previous types.ModInfo, results types.Results) error {
+	url := fmt.Sprintf("%s/%s/mods/%d", strings.TrimRight(sc.BaseUrl, "/"), sc.GameName, sc.ModID)
+	event, changed := notifiers.DetectChanges(strings.ToLower(sc.GameName), url, previous, results.Mods)
+	if !changed {
+		return nil
+	}
+
+	tmpl, err := loadNotifyTemplate(sc.NotifyTemplate)
+	if err != nil {
+		return err
+	}
+
+	notifier := &notifiers.Notifier{
+		Template: tmpl,
+		Retries:  sc.NotifyRetries,
+		Backoff:  time.Second,
+		DryRun:   sc.NotifyDryRun,
+		Out:      progress,
+	}
+	client, err := notifyClient(sc)
+	if err != nil {
+		return err
+	}
+	for _, spec := range sc.Notify {
+		sink, err := notifiers.ParseSink(spec, client)
+		if err != nil {
+			return err
+		}
+		notifier.Sinks = append(notifier.Sinks, sink)
+	}
+
+	notifySpinner := spinners.CreateSpinner("Sending notifications", "✓", "Notifications sent", "✗", "Failed to send notifications")
+	if err := notifySpinner.Start(); err != nil {
+		return fmt.Errorf("failed to start notify spinner: %w", err)
+	}
+
+	if sc.NotifyDryRun {
+		notifySpinner.Stop() // Stop before printing so the messages are not overwritten
+		return notifier.Notify(ctx, event)
+	}
+
+	if err := notifier.Notify(ctx, event); err != nil {
+		notifySpinner.StopFailMessage(fmt.Sprintf("Error sending notifications: %v", err))
+		notifySpinner.StopFail()
+		return err
+	}
+	notifySpinner.Stop()
+
+	return nil
+}
+
+// commandContext returns the command's context, which is cancelled on Ctrl-C, or a
+// background context when the command was run without one.
+func commandContext(cmd *cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
@@ -1,17 +1,23 @@
 package cli
 
 import (
+	"context"
+	"encoding/json"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
 	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/pkg/nexus"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -42,13 +48,13 @@ func (m *Mocker) RoundTrip(req *http.Request) (*http.Response, error) {
 	return args.Get(0).(*http.Response), args.Error(1)
 }
 
-var mockFetchDocument = func(_ string) (*goquery.Document, error) {
+var mockFetchDocument = func(_ context.Context, _ string) (*goquery.Document, error) {
 	html := `<html><body>Mocked HTML content</body></html>`
 	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
 	return doc, nil
 }
 
-var mockFetchModInfoConcurrent = func(baseUrl, game string, modId int64, concurrentFetch func(tasks ...func() error) error, fetchDocument func(targetURL string) (*goquery.Document, error)) (types.Results, error) {
+var mockFetchModInfoConcurrent = func(ctx context.Context, baseUrl, game string, modId int64, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
 	return types.Results{
 		Mods: types.ModInfo{
 			Name:  "Mocked Mod",
@@ -117,7 +123,35 @@ func TestRun_InvalidModID(t *testing.T) {
 	assert.EqualError(t, err, "strconv.ParseInt: parsing \"toast\": invalid syntax")
 
 	// Optionally, you can also assert the `DisplayResults` is set to true
-	assert.True(t, viper.GetBool("display-results"))
+	assert.True(t, options.DisplayResults)
+}
+
+func TestRun_NotifyWithUnloadableSaveFormat(t *testing.T) {
+	// Arrange
+	mockCmd := &cobra.Command{Use: "scrape", RunE: run}
+	initScrapeFlags(mockCmd)
+	t.Cleanup(func() { initScrapeFlags(&cobra.Command{}) })
+	mockCmd.SetArgs([]string{"game", "1234", "-s", "--save-format", "markdown", "--notify", "slack=https://example.com/hook"})
+
+	// Act
+	err := mockCmd.Execute()
+
+	// Assert
+	assert.EqualError(t, err, "--notify cannot load results saved as markdown, use another --save-format or a sqlite --store")
+}
+
+func TestRun_NegativeNotifyRetries(t *testing.T) {
+	// Arrange
+	mockCmd := &cobra.Command{Use: "scrape", RunE: run}
+	initScrapeFlags(mockCmd)
+	t.Cleanup(func() { initScrapeFlags(&cobra.Command{}) })
+	mockCmd.SetArgs([]string{"game", "1234", "-s", "--notify", "slack=https://example.com/hook", "--notify-retries", "-1"})
+
+	// Act
+	err := mockCmd.Execute()
+
+	// Assert
+	assert.EqualError(t, err, "--notify-retries must not be negative")
 }
 
 func TestScrapeMod_WithMockedFunctions(t *testing.T) {
@@ -147,8 +181,390 @@ func TestScrapeMod_WithMockedFunctions(t *testing.T) {
 	}
 
 	// Act
-	err = scrapeMod(sc, mockFetchModInfoConcurrent, mockFetchDocument)
+	err = scrapeMod(context.Background(), sc, mockFetchModInfoConcurrent, mockFetchDocument)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestScrapeMod_SqliteStore(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "session-cookies.json"), []byte("{}"), 0644))
+	dbPath := filepath.Join(tempDir, "db", "mods.db")
+
+	sc := types.CliFlags{
+		BaseUrl:         "https://somesite.com",
+		CookieDirectory: tempDir,
+		CookieFile:      "session-cookies.json",
+		GameName:        "game",
+		ModID:           1234,
+		SaveResults:     true,
+		OutputDirectory: tempDir,
+		Store:           "sqlite:" + dbPath,
+	}
+
+	// Act
+	err := scrapeMod(context.Background(), sc, mockFetchModInfoConcurrent, mockFetchDocument)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.FileExists(t, dbPath)
+	assert.NoFileExists(t, filepath.Join(tempDir, "game", "mocked mod 1234.json"))
+}
+
+func TestScrapeMod_FromSavedHtml(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	modPage := filepath.Join(tempDir, "mod.html")
+	filesPage := filepath.Join(tempDir, "files.html")
+	require.NoError(t, os.WriteFile(modPage, []byte(`<html><body><div id="pagetitle"><h1>Saved Mod</h1></div></body></html>`), 0644))
+	require.NoError(t, os.WriteFile(filesPage, []byte(`<div class="file-expander-header"><p>Main</p><div class="stat-version"><div class="stat">3.0</div></div></div>`), 0644))
+
+	// No cookie file exists, offline mode must not need one
+	sc := types.CliFlags{
+		BaseUrl:         "https://somesite.com",
+		CookieDirectory: filepath.Join(tempDir, "missing"),
+		CookieFile:      "session-cookies.json",
+		GameName:        "game",
+		ModID:           1234,
+		SaveResults:     true,
+		OutputDirectory: tempDir,
+		FromHtml:        modPage,
+		FilesHtml:       filesPage,
+	}
+
+	// Act
+	err := scrapeMod(context.Background(), sc, fetchers.FetchModInfoConcurrent, nil)
+
+	// Assert
+	require.NoError(t, err)
+	saved, err := os.ReadFile(filepath.Join(tempDir, "game", "saved mod 1234.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(saved), `"LatestVersion": "3.0"`)
+}
+
+func TestScrapeMod_FromDir(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "1234.html"), []byte(`<html><body><div id="pagetitle"><h1>Archived Mod</h1></div></body></html>`), 0644))
+
+	sc := types.CliFlags{
+		BaseUrl:         "https://somesite.com",
+		CookieDirectory: filepath.Join(tempDir, "missing"),
+		CookieFile:      "session-cookies.json",
+		GameName:        "game",
+		ModID:           1234,
+		SaveResults:     true,
+		OutputDirectory: tempDir,
+		FromDir:         tempDir,
+	}
+
+	// Act
+	err := scrapeMod(context.Background(), sc, fetchers.FetchModInfoConcurrent, nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(tempDir, "game", "archived mod 1234.json"))
+}
+
+func TestScrapeMod_RecordThenReplay(t *testing.T) {
+	// Arrange
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("tab") == "files" {
+			w.Write([]byte(`<div class="file-expander-header"><p>Main</p><div class="stat-version"><div class="stat">4.2</div></div></div>`))
+			return
+		}
+		w.Write([]byte(`<html><body><div id="pagetitle"><h1>Recorded Mod</h1></div></body></html>`))
+	}))
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "session-cookies.json"), []byte(`{"nexusmods_session":"secret"}`), 0644))
+	cassette := filepath.Join(tempDir, "cassette")
+
+	sc := types.CliFlags{
+		BaseUrl:         site.URL,
+		CookieDirectory: tempDir,
+		CookieFile:      "session-cookies.json",
+		GameName:        "game",
+		ModID:           1234,
+		SaveResults:     true,
+		OutputDirectory: filepath.Join(tempDir, "recorded"),
+		Record:          cassette,
+	}
+
+	// Act
+	recordErr := scrapeMod(context.Background(), sc, fetchers.FetchModInfoConcurrent, nil)
+	site.Close()
+
+	sc.Record = ""
+	sc.Replay = cassette
+	sc.CookieDirectory = filepath.Join(tempDir, "missing")
+	sc.OutputDirectory = filepath.Join(tempDir, "replayed")
+	replayErr := scrapeMod(context.Background(), sc, fetchers.FetchModInfoConcurrent, nil)
+
+	sc.ModID = 5678
+	unmatchedErr := scrapeMod(context.Background(), sc, fetchers.FetchModInfoConcurrent, nil)
+
+	// Assert
+	require.NoError(t, recordErr)
+	require.NoError(t, replayErr)
+	replayed, err := os.ReadFile(filepath.Join(tempDir, "replayed", "game", "recorded mod 1234.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(replayed), `"LatestVersion": "4.2"`)
+	assert.ErrorContains(t, unmatchedErr, "no recorded response for GET "+site.URL+"/game/mods/5678")
+
+	entries, err := os.ReadDir(cassette)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(cassette, entry.Name()))
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "secret")
+	}
+}
+
+func TestScrapeMod_SessionCheckFailsEarly(t *testing.T) {
+	// Arrange
+	site := newSessionSite(t, "abc")
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "session-cookies.json"), []byte(`{"nexusmods_session":"expired"}`), 0644))
+	scraped := false
+	fetchMod := func(ctx context.Context, baseUrl, game string, modId int64, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		scraped = true
+		return types.Results{}, nil
+	}
+
+	sc := types.CliFlags{
+		BaseUrl:         site.URL,
+		CheckSession:    true,
+		CookieDirectory: tempDir,
+		CookieFile:      "session-cookies.json",
+		GameName:        "game",
+		ModID:           1234,
+		DisplayResults:  true,
+	}
+
+	// Act
+	err := scrapeMod(context.Background(), sc, fetchMod, nil)
+
+	// Assert
+	assert.ErrorIs(t, err, nexus.ErrNotLoggedIn)
+	assert.False(t, scraped)
+}
+
+func TestScrapeMod_SavesCookiesSetBySite(t *testing.T) {
+	// Arrange
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "nexusmods_session", Value: "rotated", Path: "/", HttpOnly: true})
+		w.Write([]byte(`<html><body></body></html>`))
+	}))
+	defer site.Close()
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "session-cookies.json"), []byte(`{"nexusmods_session":"abc"}`), 0644))
+	fetchMod := func(ctx context.Context, baseUrl, game string, modId int64, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		_, err := fetchDocument(ctx, baseUrl+"/game/mods/1234")
+		return types.Results{}, err
+	}
+
+	sc := types.CliFlags{
+		BaseUrl:         site.URL,
+		CookieDirectory: tempDir,
+		CookieFile:      "session-cookies.json",
+		GameName:        "game",
+		ModID:           1234,
+		DisplayResults:  true,
+	}
+
+	// Act
+	err := scrapeMod(context.Background(), sc, fetchMod, nil)
+
+	// Assert
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(tempDir, "session-cookies.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":2,"cookies":[{"name":"nexusmods_session","value":"rotated","domain":"127.0.0.1","path":"/","httpOnly":true}]}`, string(data))
+}
+
+func TestScrapeMod_Timeout(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "session-cookies.json"), []byte("{}"), 0644))
+
+	sc := types.CliFlags{
+		BaseUrl:         "https://somesite.com",
+		CookieDirectory: tempDir,
+		CookieFile:      "session-cookies.json",
+		GameName:        "game",
+		ModID:           1234,
+		DisplayResults:  true,
+		Timeout:         20 * time.Millisecond,
+	}
+	fetchUntilCancelled := func(ctx context.Context, baseUrl, game string, modId int64, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		<-ctx.Done()
+		return types.Results{}, ctx.Err()
+	}
+
+	// Act
+	err := scrapeMod(context.Background(), sc, fetchUntilCancelled, mockFetchDocument)
+
+	// Assert
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "scrape aborted")
+}
+
+func TestScrapeMod_SavesTables(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "session-cookies.json"), []byte("{}"), 0644))
+
+	sc := types.CliFlags{
+		BaseUrl:         "https://somesite.com",
+		CookieDirectory: tempDir,
+		CookieFile:      "session-cookies.json",
+		GameName:        "Game",
+		ModID:           1234,
+		SaveResults:     true,
+		OutputDirectory: tempDir,
+		TableFormat:     "csv",
+	}
+
+	// Act
+	err := scrapeMod(context.Background(), sc, mockFetchModInfoConcurrent, mockFetchDocument)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(tempDir, "game", "mocked mod 1234.json"))
+	assert.FileExists(t, filepath.Join(tempDir, "game", "mocked mod 1234-mods.csv"))
+	assert.FileExists(t, filepath.Join(tempDir, "game", "mocked mod 1234-files.csv"))
+}
+
+func TestScrapeMod_SavesTablesWithJoinSeparator(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "session-cookies.json"), []byte("{}"), 0644))
+
+	sc := types.CliFlags{
+		BaseUrl:         "https://somesite.com",
+		CookieDirectory: tempDir,
+		CookieFile:      "session-cookies.json",
+		GameName:        "Game",
+		ModID:           1234,
+		SaveResults:     true,
+		OutputDirectory: tempDir,
+		TableFormat:     "csv",
+		ModColumns:      []string{"mod_id", "tags"},
+		JoinSeparator:   " | ",
+	}
+	fetchModInfo := func(ctx context.Context, baseUrl, game string, modId int64, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId, Tags: []string{"Tag1", "Tag2"}}}, nil
+	}
+
+	// Act
+	err := scrapeMod(context.Background(), sc, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	assert.NoError(t, err)
+	sheet, err := os.ReadFile(filepath.Join(tempDir, "game", "mocked mod 1234-mods.csv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(sheet), "1234,Tag1 | Tag2")
+}
+
+func TestScrapeMod_Template(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "session-cookies.json"), []byte("{}"), 0644))
+
+	sc := types.CliFlags{
+		BaseUrl:         "https://somesite.com",
+		CookieDirectory: tempDir,
+		CookieFile:      "session-cookies.json",
+		DisplayResults:  true,
+		GameName:        "game",
+		ModID:           1234,
+		SaveResults:     true,
+		OutputDirectory: tempDir,
+		Template:        "bbcode",
+	}
+
+	// Act
+	err := scrapeMod(context.Background(), sc, mockFetchModInfoConcurrent, mockFetchDocument)
+
+	// Assert
+	assert.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(tempDir, "game", "mocked mod 1234.bbcode"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "[b]Mocked Mod[/b]")
+}
+
+func TestScrapeMod_UnknownTemplate(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	sc := types.CliFlags{
+		BaseUrl:         "https://somesite.com",
+		CookieDirectory: tempDir,
+		DisplayResults:  true,
+		GameName:        "game",
+		ModID:           1234,
+		OutputDirectory: tempDir,
+		Template:        filepath.Join(tempDir, "missing.tmpl"),
+	}
+	scraped := false
+	fetchMod := func(ctx context.Context, baseUrl, game string, modId int64, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		scraped = true
+		return types.Results{}, nil
+	}
+
+	// Act
+	err := scrapeMod(context.Background(), sc, fetchMod, mockFetchDocument)
+
+	// Assert
+	assert.Error(t, err)
+	assert.False(t, scraped, "the template is loaded before scraping")
+}
+
+func TestScrapeMod_NotifiesOnNewVersion(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "session-cookies.json"), []byte("{}"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(tempDir, "game"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "game", "mocked mod 1234.json"), []byte(`{"Mods":{"ModID":1234,"Name":"Mocked Mod","LatestVersion":"1.0"}}`), 0644))
+
+	var payloads []map[string]string
+	var headers []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Get("X-Test"))
+		var payload map[string]string
+		data, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(data, &payload))
+		payloads = append(payloads, payload)
+	}))
+	defer server.Close()
+
+	fetchNewVersion := func(ctx context.Context, baseUrl, game string, modId int64, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId, LatestVersion: "1.1"}}, nil
+	}
+
+	sc := types.CliFlags{
+		BaseUrl:         "https://somesite.com",
+		CookieDirectory: tempDir,
+		CookieFile:      "session-cookies.json",
+		GameName:        "game",
+		ModID:           1234,
+		SaveResults:     true,
+		OutputDirectory: tempDir,
+		Notify:          []string{"slack=" + server.URL},
+		NotifyTemplate:  "{{ .Name }} {{ .PreviousVersion }} -> {{ .LatestVersion }}",
+		Headers:         []string{"X-Test: configured"},
+		RequestTimeout:  time.Minute,
+	}
+
+	// Act
+	err := scrapeMod(context.Background(), sc, fetchNewVersion, mockFetchDocument)
+	errAgain := scrapeMod(context.Background(), sc, fetchNewVersion, mockFetchDocument)
 
 	// Assert
 	assert.NoError(t, err)
+	assert.NoError(t, errAgain)
+	require.Len(t, payloads, 1, "the second scrape finds no changes")
+	assert.Equal(t, "Mocked Mod 1.0 -> 1.1", payloads[0]["text"])
+	assert.Equal(t, []string{"configured"}, headers, "notifications use the configured client")
 }
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/server"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/cli"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
+	"github.com/ondrovic/nexus-mods-scraper/pkg/nexus"
+)
+
+// serveFlags holds the command-line flag values for the serve command.
+type serveFlags struct {
+	requestFlags
+	Addr            string
+	CacheSize       int
+	CacheTTL        time.Duration
+	Concurrency     int
+	MaxBatch        int
+	OutputDirectory string
+	PerHost         int
+	Workers         int
+}
+
+var (
+	// serveCmd is a Cobra command used for serving scraped mods over HTTP.
+	serveCmd = &cobra.Command{}
+	// serveOptions holds the flag values for the serve command.
+	serveOptions = serveFlags{}
+	// listenAndServeFunc is a variable that holds a reference to the function used
+	// to run the HTTP server until it stops.
+	listenAndServeFunc = func(srv *http.Server) error { return srv.ListenAndServe() }
+)
+
+// init initializes the serve command, setting its usage, description, and argument
+// validation, and adds it to the root command.
+func init() {
+	serveCmd = &cobra.Command{
+		Use:   "serve [flags]",
+		Short: "Serve scraped mods over a local REST API",
+		Long:  "Serve scraped and saved mods over a local REST API, caching scrapes in memory",
+		Args:  cobra.NoArgs,
+		RunE:  runServe,
+	}
+
+	initServeFlags(serveCmd)
+	RootCmd.AddCommand(serveCmd)
+}
+
+// initServeFlags registers the command-line flags for the serve command, including
+// the listen address, cache TTL and size, batch limit, workers and per-host limit,
+// the directory holding saved results, and the base URL, cookies, rate limit, request
+// timeout, proxy, User-Agent, CA certificate and extra headers used to scrape.
+func initServeFlags(cmd *cobra.Command) {
+	registerRequestFlags(cmd, &serveOptions.requestFlags)
+	cli.RegisterFlag(cmd, "addr", "a", ":8080", "Address to listen on", &serveOptions.Addr)
+	cli.RegisterFlag(cmd, "cache-size", "", 1000, "Most scraped mods kept in memory, evicting the expired and then those expiring soonest", &serveOptions.CacheSize)
+	cli.RegisterFlag(cmd, "cache-ttl", "", 10*time.Minute, "How long scraped mods are served from memory, 0 to disable", &serveOptions.CacheTTL)
+	cli.RegisterFlag(cmd, "concurrency", "", 2, "Number of pages of each mod requested at once", &serveOptions.Concurrency)
+	cli.RegisterFlag(cmd, "max-batch", "", 50, "Maximum number of mods in a POST /scrape request", &serveOptions.MaxBatch)
+	cli.RegisterFlag(cmd, "output-directory", "o", storage.GetDataStoragePath(), "Output directory the saved results are in", &serveOptions.OutputDirectory)
+	cli.RegisterFlag(cmd, "per-host", "", 0, "Most requests sent to the same host at once, 0 for no limit", &serveOptions.PerHost)
+	cli.RegisterFlag(cmd, "workers", "w", 4, "Number of mods in a POST /scrape request scraped at once", &serveOptions.Workers)
+}
+
+// runServe sets up the cookie-backed scraper and serves the REST API until the
+// server stops or the command is interrupted, which shuts it down gracefully and
+// saves the session cookies. Returns an error if the client cannot be set up, the
+// server fails, or the cookies cannot be saved.
+func runServe(cmd *cobra.Command, args []string) error {
+	scraper, err := serveOptions.newScraper(nexus.WithConcurrency(serveOptions.Concurrency, serveOptions.PerHost))
+	if err != nil {
+		return err
+	}
+
+	api := server.New(server.Options{
+		OutputDirectory: serveOptions.OutputDirectory,
+		CacheTTL:        serveOptions.CacheTTL,
+		CacheSize:       serveOptions.CacheSize,
+		MaxBatch:        serveOptions.MaxBatch,
+		Workers:         serveOptions.Workers,
+		PerHost:         serveOptions.PerHost,
+		Logger:          logger,
+	}, scraper)
+
+	srv := &http.Server{
+		Addr:              serveOptions.Addr,
+		Handler:           api.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	stop := context.AfterFunc(commandContext(cmd), func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	})
+	defer stop()
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Serving on %s\n", serveOptions.Addr)
+	if err := listenAndServeFunc(srv); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("error serving: %w", err)
+	}
+
+	return saveSessionCookies(scraper, serveOptions.CookieFile)
+}
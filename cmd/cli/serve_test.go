@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunServe_ServesApi(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "session-cookies.json"), []byte("{}"), 0644))
+
+	originalFetchModInfo := fetchModInfoFunc
+	originalListenAndServe := listenAndServeFunc
+	fetchModInfoFunc = mockFetchModInfoConcurrent
+	defer func() {
+		fetchModInfoFunc = originalFetchModInfo
+		listenAndServeFunc = originalListenAndServe
+	}()
+
+	var rec *httptest.ResponseRecorder
+	listenAndServeFunc = func(srv *http.Server) error {
+		assert.Equal(t, ":9090", srv.Addr)
+		rec = httptest.NewRecorder()
+		srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/games/skyrim/mods/1234", nil))
+		return http.ErrServerClosed
+	}
+	serveOptions = serveFlags{
+		requestFlags: requestFlags{
+			BaseUrl:         "https://nexusmods.com",
+			CookieDirectory: dir,
+			CookieFile:      "session-cookies.json",
+		},
+		Addr:            ":9090",
+		CacheTTL:        time.Minute,
+		OutputDirectory: dir,
+	}
+
+	cmd := &cobra.Command{}
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	// Act
+	err := runServe(cmd, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "Serving on :9090")
+	require.NotNil(t, rec)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"Name":"Mocked Mod"`)
+}
+
+func TestRunServe_ListenError(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "session-cookies.json"), []byte("{}"), 0644))
+
+	originalListenAndServe := listenAndServeFunc
+	defer func() { listenAndServeFunc = originalListenAndServe }()
+	listenAndServeFunc = func(srv *http.Server) error { return errors.New("address in use") }
+	serveOptions = serveFlags{requestFlags: requestFlags{BaseUrl: "https://nexusmods.com", CookieDirectory: dir, CookieFile: "session-cookies.json"}, Addr: ":9090"}
+
+	// Act
+	err := runServe(&cobra.Command{}, nil)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "address in use")
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/templates"
+)
+
+var (
+	// templatesCmd is a Cobra command used for listing and printing the example templates.
+	templatesCmd = &cobra.Command{}
+)
+
+// init initializes the templates command, setting its usage, description, and argument
+// validation, and adds it to the root command.
+func init() {
+	templatesCmd = &cobra.Command{
+		Use:   "templates [example name]",
+		Short: "List example output templates",
+		Long:  "List the embedded example templates usable with scrape --template, or print the source of one to use as a starting point",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  runTemplates,
+	}
+
+	RootCmd.AddCommand(templatesCmd)
+}
+
+// runTemplates prints the names of the example templates, or the source of the named
+// example when one is given. Returns an error if the example does not exist.
+func runTemplates(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		for _, name := range templates.Examples() {
+			fmt.Fprintln(cmd.OutOrStdout(), name)
+		}
+		return nil
+	}
+
+	source, err := templates.ExampleSource(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(cmd.OutOrStdout(), source)
+	return nil
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunTemplates_ListsExamples(t *testing.T) {
+	// Arrange
+	cmd := &cobra.Command{}
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	// Act
+	err := runTemplates(cmd, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "bbcode\nhtml-card\nmarkdown-changelog\n", out.String())
+}
+
+func TestRunTemplates_PrintsExample(t *testing.T) {
+	// Arrange
+	cmd := &cobra.Command{}
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	// Act
+	err := runTemplates(cmd, []string{"bbcode"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "[b]{{ .Mods.Name }}[/b]")
+}
+
+func TestRunTemplates_UnknownExample(t *testing.T) {
+	// Act
+	err := runTemplates(&cobra.Command{}, []string{"wiki"})
+
+	// Assert
+	assert.Error(t, err)
+}
@@ -1,24 +1,32 @@
 package fetchers
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
 
-	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
 	"github.com/ondrovic/nexus-mods-scraper/internal/types"
 	"github.com/ondrovic/nexus-mods-scraper/internal/utils/extractors"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/logging"
 
 	"github.com/PuerkitoBio/goquery"
 )
 
+// ModTaskLabels names the tasks FetchModInfoConcurrent runs through concurrentFetch,
+// in the order they are given.
+var ModTaskLabels = []string{"mod page", "files tab"}
+
 // FetchModInfoConcurrent retrieves mod information and file details concurrently
 // for a specified mod ID and game. It validates URLs and uses provided functions
-// for concurrent fetching of mod info and file info extraction. The results are populated
-// in the Results struct, and an error is returned if any fetching or extraction step fails.
-func FetchModInfoConcurrent(baseUrl, game string, modId int64, concurrentFetch func(tasks ...func() error) error, fetchDocument func(targetURL string) (*goquery.Document, error)) (types.Results, error) {
+// for concurrent fetching of mod info and file info extraction. Each fetch builds its
+// own partial result, and the two are merged into the Results struct once both finish.
+// An error is returned if any fetching or extraction step fails or ctx is cancelled,
+// which also cancels the other fetch. Fields that could not be extracted are logged as
+// warnings to the logger carried by ctx.
+func FetchModInfoConcurrent(ctx context.Context, baseUrl, game string, modId int64, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
 	modUrl := fmt.Sprintf("%s/%s/mods/%d", baseUrl, game, modId)
 
 	// Validate the initial URL
@@ -26,26 +34,27 @@ func FetchModInfoConcurrent(baseUrl, game string, modId int64, concurrentFetch f
 		return types.Results{}, err
 	}
 
-	var results types.Results
+	// Each task only writes its own partial result, so neither can overwrite the other
+	var mod types.ModInfo
+	var files []types.File
 
 	// Function to handle mod info fetch
-	err := concurrentFetch(
-		func() error {
-			doc, err := fetchDocument(modUrl)
+	err := concurrentFetch(ctx,
+		func(ctx context.Context) error {
+			doc, err := fetchDocument(ctx, modUrl)
 			if err != nil {
 				return err
 			}
 
 			if extractors.IsAdultContent(doc, modId) {
-				return fmt.Errorf("adult content detected, cookies not working")
+				return fmt.Errorf("mod %d is hidden as adult content, the session is not logged in or does not show adult content; check it with auth status", modId)
 			}
 
-			results.Mods = extractors.ExtractModInfo(doc)
-			results.Mods.ModID = modId
-			results.Mods.LastChecked = time.Now()
+			mod = extractors.ExtractModInfo(doc)
+			logWarnings(ctx, modUrl, extractors.CheckModInfo(doc, mod))
 			return nil
 		},
-		func() error {
+		func(ctx context.Context) error {
 			filesTabURL := fmt.Sprintf("%s?tab=files", modUrl)
 
 			// Validate files tab URL
@@ -53,16 +62,13 @@ func FetchModInfoConcurrent(baseUrl, game string, modId int64, concurrentFetch f
 				return err
 			}
 
-			filesDoc, err := fetchDocument(filesTabURL)
+			filesDoc, err := fetchDocument(ctx, filesTabURL)
 			if err != nil {
 				return err
 			}
 
-			results.Mods.Files = extractors.ExtractFileInfo(filesDoc)
-			if len(results.Mods.Files) > 0 {
-				results.Mods.LatestVersion = results.Mods.Files[0].Version
-			}
-
+			files = extractors.ExtractFileInfo(filesDoc)
+			logWarnings(ctx, filesTabURL, extractors.CheckFileInfo(filesDoc, files))
 			return nil
 		},
 	)
@@ -71,33 +77,69 @@ func FetchModInfoConcurrent(baseUrl, game string, modId int64, concurrentFetch f
 		return types.Results{}, err
 	}
 
-	return results, nil
+	return types.Results{Mods: mergeModInfo(mod, files, modId, time.Now())}, nil
+}
+
+// logWarnings logs each field that could not be extracted from the page at pageURL
+// to the logger carried by ctx.
+func logWarnings(ctx context.Context, pageURL string, warnings []extractors.Warning) {
+	logger := logging.FromContext(ctx)
+	for _, warning := range warnings {
+		logger.Warn("extraction warning", "url", pageURL, "field", warning.Field, "selector", warning.Selector, "reason", warning.Reason)
+	}
 }
 
-// FetchDocument sends an HTTP GET request to the target URL, manually attaches cookies
-// from the HTTP client's cookie jar, and returns the response as a parsed goquery document.
-// It ensures a successful 200 OK status before parsing and returns an error if the request
-// or document parsing fails.
-func FetchDocument(targetURL string) (*goquery.Document, error) {
+// mergeModInfo combines the information scraped from a mod's page with the files
+// from its files tab, setting the mod ID, the check time, and the latest version from
+// the first file. The result depends only on its arguments, not on which fetch
+// finished first.
+func mergeModInfo(mod types.ModInfo, files []types.File, modId int64, checked time.Time) types.ModInfo {
+	mod.ModID = modId
+	mod.LastChecked = checked
+	mod.Files = files
+	if len(files) > 0 {
+		mod.LatestVersion = files[0].Version
+	}
+	return mod
+}
+
+// StatusError is returned when a page responds with a status other than 200 OK.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+// Error returns the URL and the status it responded with.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("failed to fetch document: %s returned %d", e.URL, e.StatusCode)
+}
+
+// FetchDocumentWithClient sends an HTTP GET request to the target URL with the given
+// client, manually attaching cookies from its cookie jar, and returns the response as
+// a parsed goquery document whose Url is the page reached after any redirects. The
+// request is cancelled when ctx is done. Returns an error if the request fails, the
+// status is not 200 OK, or the document cannot be parsed.
+func FetchDocumentWithClient(ctx context.Context, client *http.Client, targetURL string) (*goquery.Document, error) {
 	// Create a new HTTP GET request
-	req, err := http.NewRequest("GET", targetURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", targetURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	// Manually retrieve cookies for the domain
-	u, _ := url.Parse(targetURL)
-	cookies := httpclient.Client.(*http.Client).Jar.Cookies(u)
-
-	// Build the Cookie header string manually from the cookies
-	var cookieHeader []string
-	for _, cookie := range cookies {
-		cookieHeader = append(cookieHeader, fmt.Sprintf("%s=%s", cookie.Name, cookie.Value))
+	if client.Jar != nil {
+		u, _ := url.Parse(targetURL)
+		cookies := client.Jar.Cookies(u)
+
+		// Build the Cookie header string manually from the cookies
+		var cookieHeader []string
+		for _, cookie := range cookies {
+			cookieHeader = append(cookieHeader, fmt.Sprintf("%s=%s", cookie.Name, cookie.Value))
+		}
+		req.Header.Set("Cookie", strings.Join(cookieHeader, "; "))
 	}
-	req.Header.Set("Cookie", strings.Join(cookieHeader, "; "))
 
-	// Use the global httpclient.Client to make the request
-	resp, err := httpclient.Client.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -106,7 +148,7 @@ func FetchDocument(targetURL string) (*goquery.Document, error) {
 
 	// Ensure we received a 200 OK response
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch document: %s returned %d", targetURL, resp.StatusCode)
+		return nil, &StatusError{URL: targetURL, StatusCode: resp.StatusCode}
 	}
 
 	// Parse the response body into a goquery document
@@ -114,6 +156,9 @@ func FetchDocument(targetURL string) (*goquery.Document, error) {
 	if err != nil {
 		return nil, err
 	}
+	if resp.Request != nil {
+		doc.Url = resp.Request.URL
+	}
 
 	// Return the goquery document
 	return doc, nil
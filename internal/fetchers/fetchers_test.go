@@ -1,15 +1,23 @@
 package fetchers
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"github.com/PuerkitoBio/goquery"
-	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/logging"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"io"
+	"log/slog"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 )
 
 type Mocker struct {
@@ -35,16 +43,16 @@ func (m *Mocker) RoundTrip(req *http.Request) (*http.Response, error) {
 	return args.Get(0).(*http.Response), args.Error(1)
 }
 
-var mockFetchDocument = func(_ string) (*goquery.Document, error) {
+var mockFetchDocument = func(_ context.Context, _ string) (*goquery.Document, error) {
 	html := `<html><body>Mocked HTML content</body></html>`
 	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
 	return doc, nil
 }
 
-var mockConcurrentFetch = func(tasks ...func() error) error {
+var mockConcurrentFetch = func(ctx context.Context, tasks ...func(ctx context.Context) error) error {
 	// Mock behavior: run all tasks sequentially without concurrency for simplicity in testing
 	for _, task := range tasks {
-		if err := task(); err != nil {
+		if err := task(ctx); err != nil {
 			return err
 		}
 	}
@@ -53,27 +61,8 @@ var mockConcurrentFetch = func(tasks ...func() error) error {
 
 func TestFetchModInfoConcurrent_Success(t *testing.T) {
 	// Arrange
-	mockClient := new(Mocker)
-	httpclient.Client = mockClient
-
-	// Mock cookie jar
-	mockJar := new(Mocker)
-	mockClient.On("Jar").Return(mockJar)
-
-	// Mock cookies being returned
-	mockJar.On("Cookies", mock.Anything).Return([]*http.Cookie{
-		{Name: "session", Value: "1234"},
-	})
-
-	// Mock the HTTP response for the mod info fetch
-	mockResponse := &http.Response{
-		StatusCode: 200,
-		Body:       io.NopCloser(strings.NewReader(`<html><h1>Mocked HTML content</h1></html>`)),
-	}
-	mockClient.On("Do", mock.Anything).Return(mockResponse, nil)
-
 	// Act
-	results, err := FetchModInfoConcurrent("https://example.com", "game", 12345, mockConcurrentFetch, mockFetchDocument)
+	results, err := FetchModInfoConcurrent(context.Background(), "https://example.com", "game", 12345, mockConcurrentFetch, mockFetchDocument)
 
 	// Assert
 	assert.NoError(t, err)
@@ -81,7 +70,7 @@ func TestFetchModInfoConcurrent_Success(t *testing.T) {
 
 }
 
-func TestFetchDocument_Success(t *testing.T) {
+func TestFetchDocumentWithClient_Success(t *testing.T) {
 	// Arrange
 	targetURL := "https://example.com"
 
@@ -90,7 +79,7 @@ func TestFetchDocument_Success(t *testing.T) {
 	mockJar := new(Mocker)       // Mock for handling cookies
 
 	// Create a real http.Client with a mocked Transport layer and Jar
-	httpclient.Client = &http.Client{
+	client := &http.Client{
 		Jar:       mockJar,
 		Transport: mockTransport, // mockTransport simulates the transport layer
 	}
@@ -109,7 +98,7 @@ func TestFetchDocument_Success(t *testing.T) {
 	mockTransport.On("RoundTrip", mock.Anything).Return(mockResponse, nil)
 
 	// Act
-	doc, err := FetchDocument(targetURL)
+	doc, err := FetchDocumentWithClient(context.Background(), client, targetURL)
 
 	// Assert
 	assert.NoError(t, err) // Ensure no error occurred
@@ -124,15 +113,120 @@ func TestFetchDocument_Success(t *testing.T) {
 	mockTransport.AssertCalled(t, "RoundTrip", mock.Anything) // Ensure RoundTrip was called
 }
 
-func TestFetchDocument_RequestError(t *testing.T) {
+func TestFetchDocumentWithClient_RequestError(t *testing.T) {
 	// Arrange
 	targetURL := "://invalid-url"
 
 	// Act
-	doc, err := FetchDocument(targetURL)
+	doc, err := FetchDocumentWithClient(context.Background(), http.DefaultClient, targetURL)
 
 	// Assert
 	assert.Nil(t, doc)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "missing protocol scheme")
 }
+
+func TestFetchModInfoConcurrent_CancelsOtherFetch(t *testing.T) {
+	// Arrange
+	fetchDocument := func(ctx context.Context, targetURL string) (*goquery.Document, error) {
+		if strings.Contains(targetURL, "tab=files") {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return nil, errors.New("mod page failed")
+	}
+
+	// Act
+	concurrentFetch := utils.NewConcurrentFetch(utils.FetchOptions{Labels: ModTaskLabels})
+	_, err := FetchModInfoConcurrent(context.Background(), "https://example.com", "game", 12345, concurrentFetch, fetchDocument)
+
+	// Assert
+	assert.EqualError(t, err, "mod page: mod page failed")
+}
+
+func TestFetchModInfoConcurrent_MergeIsStable(t *testing.T) {
+	// Arrange
+	modPage := `<html><body><div id="pagetitle"><h1>Test Mod</h1></div></body></html>`
+	filesPage := `<html><body>
+<div class="file-expander-header"><p>Main File</p>
+<div class="stat-version"><div class="stat">1.2</div></div>
+<div class="stat-filesize"><div class="stat">10MB</div></div>
+</div></body></html>`
+
+	// Alternate which page is slower so both completion orders are exercised
+	fetchDocument := func(run int) func(ctx context.Context, targetURL string) (*goquery.Document, error) {
+		return func(ctx context.Context, targetURL string) (*goquery.Document, error) {
+			isFiles := strings.Contains(targetURL, "tab=files")
+			if isFiles == (run%2 == 0) {
+				time.Sleep(time.Duration(run%3) * time.Microsecond)
+			}
+			if isFiles {
+				return goquery.NewDocumentFromReader(strings.NewReader(filesPage))
+			}
+			return goquery.NewDocumentFromReader(strings.NewReader(modPage))
+		}
+	}
+
+	for run := 0; run < 2000; run++ {
+		// Act
+		results, err := FetchModInfoConcurrent(context.Background(), "https://example.com", "game", 12345, utils.ConcurrentFetch, fetchDocument(run))
+
+		// Assert
+		if !assert.NoError(t, err) {
+			return
+		}
+		if !assert.Equal(t, "Test Mod", results.Mods.Name, "run %d", run) ||
+			!assert.Equal(t, int64(12345), results.Mods.ModID, "run %d", run) ||
+			!assert.Equal(t, "1.2", results.Mods.LatestVersion, "run %d", run) ||
+			!assert.Equal(t, []types.File{{Name: "Main File", Version: "1.2", FileSize: "10MB"}}, results.Mods.Files, "run %d", run) {
+			return
+		}
+	}
+}
+
+func TestMergeModInfo(t *testing.T) {
+	// Arrange
+	checked := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	page := types.ModInfo{Name: "Test Mod", LatestVersion: "stale"}
+	files := []types.File{{Name: "Main File", Version: "2.0"}, {Name: "Old File", Version: "1.0"}}
+
+	// Act
+	merged := mergeModInfo(page, files, 42, checked)
+	withoutFiles := mergeModInfo(page, nil, 42, checked)
+
+	// Assert
+	assert.Equal(t, types.ModInfo{Name: "Test Mod", ModID: 42, LastChecked: checked, LatestVersion: "2.0", Files: files}, merged)
+	assert.Equal(t, "stale", withoutFiles.LatestVersion)
+	assert.Nil(t, withoutFiles.Files)
+}
+
+func TestFetchDocument_CancelledContext(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// Act
+	doc, err := FetchDocumentWithClient(ctx, server.Client(), server.URL)
+
+	// Assert
+	assert.Nil(t, doc)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestFetchModInfoConcurrent_LogsExtractionWarnings(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+	ctx := logging.NewContext(context.Background(), slog.New(slog.NewTextHandler(&out, nil)))
+
+	// Act
+	_, err := FetchModInfoConcurrent(ctx, "https://somesite.com", "game", 1, mockConcurrentFetch, mockFetchDocument)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), `level=WARN msg="extraction warning" url=https://somesite.com/game/mods/1 field=name selector="#pagetitle > h1" reason="selector matched nothing"`)
+	assert.Contains(t, out.String(), `msg="extraction warning" url="https://somesite.com/game/mods/1?tab=files" field=files selector=.file-expander-header reason="selector matched nothing"`)
+}
@@ -0,0 +1,78 @@
+package fetchers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// FileDocumentFetcher returns a document fetcher that reads saved pages instead of
+// making requests, serving modPage for a mod's page and filesPage for its files tab.
+// When filesPage is empty the files tab is an empty page, so the mod has no files.
+func FileDocumentFetcher(modPage, filesPage string) func(ctx context.Context, targetURL string) (*goquery.Document, error) {
+	return func(ctx context.Context, targetURL string) (*goquery.Document, error) {
+		u, err := url.Parse(targetURL)
+		if err != nil {
+			return nil, err
+		}
+
+		if isFilesTab(u) {
+			return readDocument(ctx, filesPage, true)
+		}
+		return readDocument(ctx, modPage, false)
+	}
+}
+
+// DirDocumentFetcher returns a document fetcher that reads saved pages from dir
+// instead of making requests. A mod's page is read from <mod id>.html and its files
+// tab from <mod id>-files.html, which may be missing when the mod has no files.
+func DirDocumentFetcher(dir string) func(ctx context.Context, targetURL string) (*goquery.Document, error) {
+	return func(ctx context.Context, targetURL string) (*goquery.Document, error) {
+		u, err := url.Parse(targetURL)
+		if err != nil {
+			return nil, err
+		}
+
+		modId := path.Base(strings.TrimRight(u.Path, "/"))
+		if isFilesTab(u) {
+			return readDocument(ctx, filepath.Join(dir, modId+"-files.html"), true)
+		}
+		return readDocument(ctx, filepath.Join(dir, modId+".html"), false)
+	}
+}
+
+// isFilesTab reports whether the URL is a mod's files tab.
+func isFilesTab(u *url.URL) bool {
+	return u.Query().Get("tab") == "files"
+}
+
+// readDocument parses the saved page at name. An optional page that is not set or
+// does not exist is read as an empty document. Returns an error if ctx is cancelled
+// or the page cannot be read.
+func readDocument(ctx context.Context, name string, optional bool) (*goquery.Document, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if name == "" && optional {
+		return goquery.NewDocumentFromReader(strings.NewReader(""))
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		if optional && errors.Is(err, os.ErrNotExist) {
+			return goquery.NewDocumentFromReader(strings.NewReader(""))
+		}
+		return nil, fmt.Errorf("error reading saved page: %w", err)
+	}
+	defer file.Close()
+
+	return goquery.NewDocumentFromReader(file)
+}
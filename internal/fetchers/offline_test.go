@@ -0,0 +1,98 @@
+package fetchers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const savedModPage = `<html><body><div id="pagetitle"><h1>Saved Mod</h1></div></body></html>`
+
+const savedFilesPage = `<html><body>
+<div class="file-expander-header"><p>Main File</p>
+<div class="stat-version"><div class="stat">2.1</div></div>
+<div class="stat-filesize"><div class="stat">5MB</div></div>
+</div></body></html>`
+
+func TestFileDocumentFetcher(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	modPage := filepath.Join(dir, "mod.html")
+	filesPage := filepath.Join(dir, "files.html")
+	require.NoError(t, os.WriteFile(modPage, []byte(savedModPage), 0644))
+	require.NoError(t, os.WriteFile(filesPage, []byte(savedFilesPage), 0644))
+
+	// Act
+	results, err := FetchModInfoConcurrent(context.Background(), "https://nexusmods.com", "skyrim", 1234, utils.ConcurrentFetch, FileDocumentFetcher(modPage, filesPage))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Saved Mod", results.Mods.Name)
+	assert.Equal(t, "2.1", results.Mods.LatestVersion)
+	assert.Equal(t, []types.File{{Name: "Main File", Version: "2.1", FileSize: "5MB"}}, results.Mods.Files)
+}
+
+func TestFileDocumentFetcher_WithoutFilesPage(t *testing.T) {
+	// Arrange
+	modPage := filepath.Join(t.TempDir(), "mod.html")
+	require.NoError(t, os.WriteFile(modPage, []byte(savedModPage), 0644))
+
+	// Act
+	results, err := FetchModInfoConcurrent(context.Background(), "https://nexusmods.com", "skyrim", 1234, utils.ConcurrentFetch, FileDocumentFetcher(modPage, ""))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Saved Mod", results.Mods.Name)
+	assert.Empty(t, results.Mods.Files)
+}
+
+func TestFileDocumentFetcher_MissingModPage(t *testing.T) {
+	// Arrange
+	fetch := FileDocumentFetcher(filepath.Join(t.TempDir(), "missing.html"), "")
+
+	// Act
+	doc, err := fetch(context.Background(), "https://nexusmods.com/skyrim/mods/1234")
+
+	// Assert
+	assert.Nil(t, doc)
+	assert.ErrorContains(t, err, "error reading saved page")
+}
+
+func TestDirDocumentFetcher(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "1234.html"), []byte(savedModPage), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "1234-files.html"), []byte(savedFilesPage), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "5678.html"), []byte(savedModPage), 0644))
+
+	// Act
+	withFiles, err := FetchModInfoConcurrent(context.Background(), "https://nexusmods.com", "skyrim", 1234, utils.ConcurrentFetch, DirDocumentFetcher(dir))
+	require.NoError(t, err)
+	withoutFiles, err := FetchModInfoConcurrent(context.Background(), "https://nexusmods.com", "skyrim", 5678, utils.ConcurrentFetch, DirDocumentFetcher(dir))
+	require.NoError(t, err)
+	_, missingErr := FetchModInfoConcurrent(context.Background(), "https://nexusmods.com", "skyrim", 9999, utils.ConcurrentFetch, DirDocumentFetcher(dir))
+
+	// Assert
+	assert.Equal(t, "2.1", withFiles.Mods.LatestVersion)
+	assert.Equal(t, int64(5678), withoutFiles.Mods.ModID)
+	assert.Empty(t, withoutFiles.Mods.Files)
+	assert.ErrorContains(t, missingErr, "9999.html")
+}
+
+func TestDirDocumentFetcher_CancelledContext(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	_, err := DirDocumentFetcher(t.TempDir())(ctx, "https://nexusmods.com/skyrim/mods/1234")
+
+	// Assert
+	assert.ErrorIs(t, err, context.Canceled)
+}
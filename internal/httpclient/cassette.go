@@ -0,0 +1,228 @@
+package httpclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// scrubbedHeaders are left out of cassettes so recorded sessions cannot be reused.
+var scrubbedHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization", "Set-Cookie"}
+
+// cassetteFileMode keeps recorded interactions readable only by their owner.
+const cassetteFileMode = 0600
+
+// scrubbedFields mark the JSON fields whose values are replaced in recorded bodies,
+// matching any key that contains one of them, such as access_token or sessionId.
+var scrubbedFields = []string{"token", "session", "refresh", "password", "secret"}
+
+// scrubbedValue replaces the values of scrubbed JSON fields.
+const scrubbedValue = "[scrubbed]"
+
+// Interaction is a recorded request and the response it received.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the part of a request saved in a cassette.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+}
+
+// RecordedResponse is the part of a response saved in a cassette.
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// Recorder is an http.RoundTripper that sends requests with the next transport and
+// saves every request and response into a cassette directory, with cookies,
+// credentials and token-like JSON fields scrubbed.
+type Recorder struct {
+	Dir  string
+	Next http.RoundTripper
+	// Scrub names extra headers left out of the cassette, such as the headers given
+	// with --header, which can hold API keys.
+	Scrub []string
+}
+
+// NewRecorder creates a Recorder saving to dir, sending requests with next or with
+// http.DefaultTransport when next is nil, and leaving the scrub headers out along
+// with the cookies and credentials.
+func NewRecorder(dir string, next http.RoundTripper, scrub ...string) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{Dir: dir, Next: next, Scrub: scrub}
+}
+
+// RoundTrip sends the request and records it with its response. Returns an error if
+// the request fails or the interaction cannot be saved.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.Next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: scrubHeader(req.Header, r.Scrub),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     scrubHeader(resp.Header, r.Scrub),
+			Body:       string(scrubBody(body)),
+		},
+	}
+
+	if err := os.MkdirAll(r.Dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("error creating cassette directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(r.Dir, cassetteFilename(req.Method, req.URL.String())), data, cassetteFileMode); err != nil {
+		return nil, fmt.Errorf("error saving cassette: %w", err)
+	}
+
+	return resp, nil
+}
+
+// Replayer is an http.RoundTripper that serves the responses recorded in a cassette
+// directory without making requests.
+type Replayer struct {
+	Dir string
+}
+
+// NewReplayer creates a Replayer serving the cassette in dir.
+func NewReplayer(dir string) *Replayer {
+	return &Replayer{Dir: dir}
+}
+
+// RoundTrip returns the recorded response for the request's method and URL. Returns
+// an error naming the request when the cassette has no recording of it.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	data, err := os.ReadFile(filepath.Join(r.Dir, cassetteFilename(req.Method, req.URL.String())))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no recorded response for %s %s in cassette %s", req.Method, req.URL, r.Dir)
+		}
+		return nil, fmt.Errorf("error reading cassette: %w", err)
+	}
+
+	var interaction Interaction
+	if err := json.Unmarshal(data, &interaction); err != nil {
+		return nil, fmt.Errorf("error decoding cassette: %w", err)
+	}
+
+	header := interaction.Response.Header
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+		StatusCode:    interaction.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+		ContentLength: int64(len(interaction.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// cassetteFilename names the file an interaction is saved in, from its method and URL.
+func cassetteFilename(method, url string) string {
+	sum := sha256.Sum256([]byte(method + " " + url))
+	return fmt.Sprintf("%s-%x.json", strings.ToLower(method), sum[:8])
+}
+
+// scrubHeader returns a copy of header without cookies, credentials or the extra
+// headers named in scrub.
+func scrubHeader(header http.Header, scrub []string) http.Header {
+	scrubbed := header.Clone()
+	for _, name := range append(scrubbedHeaders, scrub...) {
+		scrubbed.Del(name)
+	}
+	if len(scrubbed) == 0 {
+		return nil
+	}
+	return scrubbed
+}
+
+// scrubBody returns body with the values of token-like fields replaced when it is a
+// JSON document, and unchanged otherwise.
+func scrubBody(body []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil || decoder.More() {
+		return body
+	}
+	if !scrubJson(doc) {
+		return body
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return body
+	}
+	return data
+}
+
+// scrubJson replaces the values of token-like fields in the decoded JSON value, at
+// any depth. Reports whether anything was replaced.
+func scrubJson(value interface{}) bool {
+	scrubbed := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if isScrubbedField(key) {
+				v[key] = scrubbedValue
+				scrubbed = true
+				continue
+			}
+			scrubbed = scrubJson(field) || scrubbed
+		}
+	case []interface{}:
+		for _, item := range v {
+			scrubbed = scrubJson(item) || scrubbed
+		}
+	}
+	return scrubbed
+}
+
+// isScrubbedField reports whether the JSON key names a token-like field.
+func isScrubbedField(key string) bool {
+	key = strings.ToLower(key)
+	for _, field := range scrubbedFields {
+		if strings.Contains(key, field) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder_RecordsAndReplays(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "nexusmods_session", Value: "refreshed"})
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html>mod page</html>"))
+	}))
+	dir := filepath.Join(t.TempDir(), "cassette")
+	recording := &http.Client{Transport: NewRecorder(dir, nil)}
+	req, err := http.NewRequest("GET", server.URL+"/skyrim/mods/1", nil)
+	require.NoError(t, err)
+	req.Header.Set("Cookie", "nexusmods_session=secret")
+
+	// Act
+	resp, err := recording.Do(req)
+	require.NoError(t, err)
+	recorded, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	server.Close()
+
+	replaying := &http.Client{Transport: NewReplayer(dir)}
+	replayed, err := replaying.Get(server.URL + "/skyrim/mods/1")
+	require.NoError(t, err)
+	replayedBody, err := io.ReadAll(replayed.Body)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, "<html>mod page</html>", string(recorded))
+	assert.Equal(t, http.StatusOK, replayed.StatusCode)
+	assert.Equal(t, "<html>mod page</html>", string(replayedBody))
+	assert.Equal(t, "text/html", replayed.Header.Get("Content-Type"))
+	assert.Empty(t, replayed.Header.Get("Set-Cookie"))
+}
+
+func TestRecorder_ScrubsCookies(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "nexusmods_session", Value: "refreshed"})
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	req, err := http.NewRequest("GET", server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Cookie", "nexusmods_session=secret")
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Set("Proxy-Authorization", "Basic proxy-credentials")
+	req.Header.Set("X-Api-Key", "api-key")
+
+	// Act
+	_, err = (&http.Client{Transport: NewRecorder(dir, nil, HeaderNames([]string{"x-api-key: api-key"})...)}).Do(req)
+
+	// Assert
+	require.NoError(t, err)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	data, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret")
+	assert.NotContains(t, string(data), "refreshed")
+	assert.NotContains(t, string(data), "Bearer")
+	assert.NotContains(t, string(data), "proxy-credentials")
+	assert.NotContains(t, string(data), "api-key")
+	info, err := entries[0].Info()
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	assert.Contains(t, string(data), `"url": "`+server.URL+`"`)
+}
+
+func TestRecorder_ScrubsJsonBodies(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"user":{"name":"player","sessionId":"abc123"},"access_token":"tok456","items":[{"Refresh_Token":"ref789","count":12345678901234567890}]}`))
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+
+	// Act
+	resp, err := (&http.Client{Transport: NewRecorder(dir, nil)}).Get(server.URL)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Contains(t, string(body), "tok456")
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	data, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	require.NoError(t, err)
+	var interaction Interaction
+	require.NoError(t, json.Unmarshal(data, &interaction))
+	assert.JSONEq(t, `{"user":{"name":"player","sessionId":"[scrubbed]"},"access_token":"[scrubbed]","items":[{"Refresh_Token":"[scrubbed]","count":12345678901234567890}]}`, interaction.Response.Body)
+}
+
+func TestScrubBody_LeavesOtherBodies(t *testing.T) {
+	// Arrange
+	html := []byte(`<html><body>token</body></html>`)
+	plain := []byte(`{"name": "player", "count": 1}`)
+
+	// Act
+	scrubbedHtml := scrubBody(html)
+	scrubbedPlain := scrubBody(plain)
+
+	// Assert
+	assert.Equal(t, html, scrubbedHtml)
+	assert.Equal(t, plain, scrubbedPlain)
+}
+
+func TestReplayer_UnmatchedRequest(t *testing.T) {
+	// Arrange
+	client := &http.Client{Transport: NewReplayer(t.TempDir())}
+
+	// Act
+	_, err := client.Get("https://nexusmods.com/skyrim/mods/404")
+
+	// Assert
+	assert.ErrorContains(t, err, "no recorded response for GET https://nexusmods.com/skyrim/mods/404")
+}
@@ -0,0 +1,349 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// CookieFileVersion is the version of the cookie file format written by SaveCookies.
+	CookieFileVersion = 2
+	// cookieFileMode keeps cookie files readable only by their owner, as they hold
+	// logged-in sessions.
+	cookieFileMode = 0600
+)
+
+// CookieFile is the saved cookie file, holding every cookie with its attributes.
+// Cookie files written before version 2 hold a plain map of names to values, which
+// are still read.
+type CookieFile struct {
+	Version int            `json:"version"`
+	Cookies []StoredCookie `json:"cookies"`
+}
+
+// StoredCookie is a cookie as saved in the cookie file.
+type StoredCookie struct {
+	Name     string     `json:"name"`
+	Value    string     `json:"value"`
+	Domain   string     `json:"domain,omitempty"`
+	Path     string     `json:"path,omitempty"`
+	Expires  *time.Time `json:"expires,omitempty"`
+	Secure   bool       `json:"secure,omitempty"`
+	HttpOnly bool       `json:"httpOnly,omitempty"`
+	SameSite string     `json:"sameSite,omitempty"`
+}
+
+// NewCookieFile returns the cookie file holding the cookies, leaving out any that
+// have expired.
+func NewCookieFile(cookies []*http.Cookie) CookieFile {
+	return newCookieFile(cookies, time.Now())
+}
+
+// newCookieFile returns the cookie file holding the cookies not expired at now,
+// sorted by domain, path and name so saved files are stable.
+func newCookieFile(cookies []*http.Cookie, now time.Time) CookieFile {
+	file := CookieFile{Version: CookieFileVersion, Cookies: []StoredCookie{}}
+	for _, cookie := range cookies {
+		if cookieExpired(cookie, now) {
+			continue
+		}
+		stored := StoredCookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			Secure:   cookie.Secure,
+			HttpOnly: cookie.HttpOnly,
+			SameSite: sameSiteName(cookie.SameSite),
+		}
+		if !cookie.Expires.IsZero() {
+			expires := cookie.Expires.UTC()
+			stored.Expires = &expires
+		}
+		file.Cookies = append(file.Cookies, stored)
+	}
+
+	sort.SliceStable(file.Cookies, func(i, j int) bool {
+		a, b := file.Cookies[i], file.Cookies[j]
+		if a.Domain != b.Domain {
+			return a.Domain < b.Domain
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Name < b.Name
+	})
+	return file
+}
+
+// HTTPCookies returns the file's cookies as HTTP cookies, leaving out any that
+// have expired.
+func (f CookieFile) HTTPCookies() []*http.Cookie {
+	return f.httpCookies(time.Now())
+}
+
+// httpCookies returns the file's cookies not expired at now as HTTP cookies.
+func (f CookieFile) httpCookies(now time.Time) []*http.Cookie {
+	var cookies []*http.Cookie
+	for _, stored := range f.Cookies {
+		cookie := &http.Cookie{
+			Name:     stored.Name,
+			Value:    stored.Value,
+			Domain:   stored.Domain,
+			Path:     stored.Path,
+			Secure:   stored.Secure,
+			HttpOnly: stored.HttpOnly,
+			SameSite: sameSiteMode(stored.SameSite),
+		}
+		if stored.Expires != nil {
+			cookie.Expires = *stored.Expires
+		}
+		if cookieExpired(cookie, now) {
+			continue
+		}
+		cookies = append(cookies, cookie)
+	}
+	return cookies
+}
+
+// ParseCookieFile decodes a cookie file in either format, decrypting it with the
+// Passphrase when it is encrypted, and returns a version 2 file. Returns an error if
+// the data is neither format or cannot be decrypted.
+func ParseCookieFile(data []byte) (CookieFile, error) {
+	return parseCookieFile(data, Passphrase())
+}
+
+// parseCookieFile decodes a cookie file in either format, decrypting it with the
+// passphrase when it is encrypted.
+func parseCookieFile(data []byte, passphrase string) (CookieFile, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return CookieFile{}, fmt.Errorf("error decoding JSON: %w", err)
+	}
+
+	if _, ok := fields["encryption"]; ok {
+		plaintext, err := decrypt(data, passphrase)
+		if err != nil {
+			return CookieFile{}, err
+		}
+		return parseCookieFile(plaintext, "")
+	}
+
+	// A version 2 file holds a list of cookies, where the old map only holds strings
+	if list, ok := fields["cookies"]; ok && bytes.HasPrefix(bytes.TrimSpace(list), []byte("[")) {
+		var file CookieFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return CookieFile{}, fmt.Errorf("error decoding JSON: %w", err)
+		}
+		file.Version = CookieFileVersion
+		return file, nil
+	}
+
+	var values map[string]string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return CookieFile{}, fmt.Errorf("error decoding JSON: %w", err)
+	}
+	file := CookieFile{Version: CookieFileVersion}
+	for name, value := range values {
+		file.Cookies = append(file.Cookies, StoredCookie{Name: name, Value: value})
+	}
+	sort.Slice(file.Cookies, func(i, j int) bool { return file.Cookies[i].Name < file.Cookies[j].Name })
+	return file, nil
+}
+
+// LoadCookies reads the cookies saved by the extract command from the cookie file
+// in dir, in either format and decrypting it when encrypted, leaving out any that
+// have expired. Returns an error if the file cannot be opened, decrypted or decoded.
+func LoadCookies(dir, filename string) ([]*http.Cookie, error) {
+	data, err := os.ReadFile(filepath.Join(dir, filename))
+	if err != nil {
+		return nil, fmt.Errorf("error opening cookie file: %w", err)
+	}
+
+	file, err := ParseCookieFile(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return file.HTTPCookies(), nil
+}
+
+// SaveCookies writes the cookies into the cookie file in dir, replacing saved
+// cookies with the same name, domain and path and keeping the others. Expired
+// cookies are pruned, so a cookie the site deleted is removed from the file. The
+// file is replaced atomically, so a failed write never leaves it half written, is
+// readable only by its owner, and is encrypted when a Passphrase is set. Returns an
+// error if the file cannot be read or written.
+func SaveCookies(dir, filename string, cookies []*http.Cookie) error {
+	cookieFilePath := filepath.Join(dir, filename)
+
+	var saved []*http.Cookie
+	if data, err := os.ReadFile(cookieFilePath); err == nil {
+		file, err := ParseCookieFile(data)
+		if err != nil {
+			return err
+		}
+		saved = file.httpCookies(time.Time{})
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("error opening cookie file: %w", err)
+	}
+
+	merged := saved[:0]
+	for _, old := range saved {
+		if !replacedBy(old, cookies) {
+			merged = append(merged, old)
+		}
+	}
+	merged = append(merged, cookies...)
+
+	data, err := EncodeCookieFile(merged)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(cookieFilePath, data, cookieFileMode)
+}
+
+// EncodeCookieFile returns the cookie file holding the cookies as JSON, leaving out
+// any that have expired, and encrypted with the Passphrase when one is set. Returns
+// an error if encoding or encryption fails.
+func EncodeCookieFile(cookies []*http.Cookie) ([]byte, error) {
+	return encodeCookieFile(NewCookieFile(cookies), Passphrase())
+}
+
+// encodeCookieFile returns the cookie file as JSON, encrypted with the passphrase
+// unless it is empty.
+func encodeCookieFile(file CookieFile, passphrase string) ([]byte, error) {
+	data, err := json.MarshalIndent(file, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	if passphrase == "" {
+		return data, nil
+	}
+	return encrypt(data, passphrase)
+}
+
+// RotateCookieKey re-encrypts the cookie file in dir, reading it with oldPassphrase
+// and writing it with newPassphrase, where an empty passphrase means unencrypted.
+// The file is replaced atomically. Returns an error if the file cannot be read with
+// oldPassphrase or cannot be written.
+func RotateCookieKey(dir, filename, oldPassphrase, newPassphrase string) error {
+	cookieFilePath := filepath.Join(dir, filename)
+	data, err := os.ReadFile(cookieFilePath)
+	if err != nil {
+		return fmt.Errorf("error opening cookie file: %w", err)
+	}
+
+	file, err := parseCookieFile(data, oldPassphrase)
+	if err != nil {
+		return err
+	}
+
+	rotated, err := encodeCookieFile(file, newPassphrase)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(cookieFilePath, rotated, cookieFileMode)
+}
+
+// replacedBy reports whether one of the cookies replaces the saved cookie, having
+// the same name, domain and path. Cookies saved in the old format have no domain
+// or path, and are replaced by any cookie with their name.
+func replacedBy(saved *http.Cookie, cookies []*http.Cookie) bool {
+	for _, cookie := range cookies {
+		if cookie.Name != saved.Name {
+			continue
+		}
+		if saved.Domain == "" && saved.Path == "" {
+			return true
+		}
+		if cookieDomain(cookie.Domain) == cookieDomain(saved.Domain) && cookiePath(cookie.Path) == cookiePath(saved.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// cookieExpired reports whether the cookie has expired at now, or has been deleted.
+func cookieExpired(cookie *http.Cookie, now time.Time) bool {
+	if cookie.MaxAge < 0 {
+		return true
+	}
+	return !cookie.Expires.IsZero() && !now.IsZero() && !cookie.Expires.After(now)
+}
+
+// cookieDomain returns the domain in the form used to compare cookies.
+func cookieDomain(domain string) string {
+	return strings.TrimPrefix(strings.ToLower(domain), ".")
+}
+
+// cookiePath returns the path in the form used to compare cookies.
+func cookiePath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// sameSiteName returns the SameSite attribute as saved in the cookie file.
+func sameSiteName(mode http.SameSite) string {
+	switch mode {
+	case http.SameSiteLaxMode:
+		return "lax"
+	case http.SameSiteStrictMode:
+		return "strict"
+	case http.SameSiteNoneMode:
+		return "none"
+	default:
+		return ""
+	}
+}
+
+// sameSiteMode returns the SameSite attribute saved in the cookie file.
+func sameSiteMode(name string) http.SameSite {
+	switch strings.ToLower(name) {
+	case "lax":
+		return http.SameSiteLaxMode
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return 0
+	}
+}
+
+// writeFileAtomic writes data to a temporary file next to name and renames it over
+// name, so readers see either the old or the new contents.
+func writeFileAtomic(name string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), name)
+}
@@ -0,0 +1,170 @@
+package httpclient
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCookieFile_OldFormat(t *testing.T) {
+	// Act
+	file, err := ParseCookieFile([]byte(`{"nexusmods_session_refresh":"r","nexusmods_session":"s"}`))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, CookieFile{Version: CookieFileVersion, Cookies: []StoredCookie{
+		{Name: "nexusmods_session", Value: "s"},
+		{Name: "nexusmods_session_refresh", Value: "r"},
+	}}, file)
+}
+
+func TestParseCookieFile_OldFormatCookieNamedCookies(t *testing.T) {
+	// Act
+	file, err := ParseCookieFile([]byte(`{"cookies":"value"}`))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []StoredCookie{{Name: "cookies", Value: "value"}}, file.Cookies)
+}
+
+func TestParseCookieFile_Invalid(t *testing.T) {
+	// Act
+	_, err := ParseCookieFile([]byte(`{"cookies":[1]}`))
+
+	// Assert
+	assert.ErrorContains(t, err, "error decoding JSON")
+}
+
+func TestLoadCookies(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cookies.json"), []byte(`{"session":"1234"}`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "invalid.json"), []byte("invalid json content"), 0600))
+
+	// Act
+	cookies, err := LoadCookies(dir, "cookies.json")
+	_, missingErr := LoadCookies(dir, "nonexistent.json")
+	_, invalidErr := LoadCookies(dir, "invalid.json")
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "session", cookies[0].Name)
+	assert.Equal(t, "1234", cookies[0].Value)
+	assert.ErrorContains(t, missingErr, "error opening cookie file")
+	assert.ErrorContains(t, invalidErr, "error decoding JSON")
+}
+
+func TestCookieFile_RoundTrip(t *testing.T) {
+	// Arrange
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	cookies := []*http.Cookie{{
+		Name: "nexusmods_session", Value: "s", Domain: ".nexusmods.com", Path: "/",
+		Expires: expires, Secure: true, HttpOnly: true, SameSite: http.SameSiteLaxMode,
+	}}
+
+	// Act
+	roundTripped := NewCookieFile(cookies).HTTPCookies()
+
+	// Assert
+	assert.Equal(t, cookies, roundTripped)
+}
+
+func TestCookieFile_PrunesExpired(t *testing.T) {
+	// Arrange
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cookies := []*http.Cookie{
+		{Name: "expired", Value: "1", Expires: now.Add(-time.Minute)},
+		{Name: "deleted", Value: "2", MaxAge: -1},
+		{Name: "session", Value: "3"},
+		{Name: "valid", Value: "4", Expires: now.Add(time.Minute)},
+	}
+
+	// Act
+	file := newCookieFile(cookies, now)
+
+	// Assert
+	require.Len(t, file.Cookies, 2)
+	assert.Equal(t, "session", file.Cookies[0].Name)
+	assert.Equal(t, "valid", file.Cookies[1].Name)
+	assert.Len(t, file.httpCookies(now.Add(time.Hour)), 1)
+}
+
+func TestSaveCookies_ReplacesOldFormat(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "session-cookies.json"), []byte(`{"nexusmods_session":"stale","nexusmods_session_refresh":"r"}`), 0644))
+
+	// Act
+	err := SaveCookies(dir, "session-cookies.json", []*http.Cookie{
+		{Name: SessionCookie, Value: "fresh", Domain: "nexusmods.com", Path: "/"},
+		{Name: RefreshCookie, Value: "r", Domain: "nexusmods.com", Path: "/", Expires: time.Unix(0, 0)},
+	})
+
+	// Assert
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(dir, "session-cookies.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":2,"cookies":[{"name":"nexusmods_session","value":"fresh","domain":"nexusmods.com","path":"/"}]}`, string(data))
+}
+
+func TestSaveCookies_EncryptsWithPassphrase(t *testing.T) {
+	// Arrange
+	original := Passphrase
+	t.Cleanup(func() { Passphrase = original })
+	Passphrase = func() string { return "secret" }
+	dir := t.TempDir()
+
+	// Act
+	err := SaveCookies(dir, "session-cookies.json", []*http.Cookie{{Name: SessionCookie, Value: "abc", Domain: "nexusmods.com", Path: "/"}})
+
+	// Assert
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(dir, "session-cookies.json"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "abc")
+	info, err := os.Stat(filepath.Join(dir, "session-cookies.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	cookies, err := LoadCookies(dir, "session-cookies.json")
+	require.NoError(t, err)
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "abc", cookies[0].Value)
+
+	Passphrase = func() string { return "" }
+	_, err = LoadCookies(dir, "session-cookies.json")
+	assert.ErrorIs(t, err, ErrPassphraseRequired)
+}
+
+func TestRotateCookieKey(t *testing.T) {
+	// Arrange
+	original := Passphrase
+	t.Cleanup(func() { Passphrase = original })
+	Passphrase = func() string { return "" }
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "session-cookies.json"), []byte(`{"nexusmods_session":"abc"}`), 0644))
+
+	// Act
+	err := RotateCookieKey(dir, "session-cookies.json", "", "first")
+	require.NoError(t, err)
+	err = RotateCookieKey(dir, "session-cookies.json", "first", "second")
+
+	// Assert
+	require.NoError(t, err)
+	assert.ErrorIs(t, RotateCookieKey(dir, "session-cookies.json", "first", ""), ErrWrongPassphrase)
+
+	Passphrase = func() string { return "second" }
+	cookies, err := LoadCookies(dir, "session-cookies.json")
+	require.NoError(t, err)
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "abc", cookies[0].Value)
+	info, err := os.Stat(filepath.Join(dir, "session-cookies.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
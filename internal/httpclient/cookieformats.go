@@ -0,0 +1,186 @@
+package httpclient
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// netscapeHttpOnlyPrefix marks HttpOnly cookies in a Netscape cookies.txt file.
+const netscapeHttpOnlyPrefix = "#HttpOnly_"
+
+// ParseNetscapeCookies reads cookies from a Netscape cookies.txt file, as written by
+// curl, wget and browser extensions. Each line holds the domain, whether subdomains
+// are included, the path, whether the cookie is secure, the expiry as a Unix time
+// (0 for session cookies), the name and the value, separated by tabs. Returns an
+// error if a line does not have these fields.
+func ParseNetscapeCookies(r io.Reader) ([]*http.Cookie, error) {
+	var cookies []*http.Cookie
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		httpOnly := strings.HasPrefix(line, netscapeHttpOnlyPrefix)
+		if httpOnly {
+			line = strings.TrimPrefix(line, netscapeHttpOnlyPrefix)
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("invalid cookies.txt line %d: expected 7 tab-separated fields, got %d", lineNumber, len(fields))
+		}
+		expiry, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cookies.txt line %d: invalid expiry %q", lineNumber, fields[4])
+		}
+
+		cookie := &http.Cookie{
+			Name:     fields[5],
+			Value:    fields[6],
+			Domain:   fields[0],
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			HttpOnly: httpOnly,
+		}
+		if !strings.EqualFold(fields[1], "TRUE") {
+			cookie.Domain = strings.TrimPrefix(cookie.Domain, ".")
+		}
+		if expiry > 0 {
+			cookie.Expires = time.Unix(expiry, 0).UTC()
+		}
+		cookies = append(cookies, cookie)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading cookies.txt: %w", err)
+	}
+
+	return cookies, nil
+}
+
+// WriteNetscapeCookies writes the cookies as a Netscape cookies.txt file, which
+// curl reads with --cookie. Cookies with a domain starting with a dot include its
+// subdomains. Returns an error if writing fails.
+func WriteNetscapeCookies(w io.Writer, cookies []*http.Cookie) error {
+	var b strings.Builder
+	b.WriteString("# Netscape HTTP Cookie File\n")
+	for _, cookie := range cookies {
+		if cookie.HttpOnly {
+			b.WriteString(netscapeHttpOnlyPrefix)
+		}
+		var expiry int64
+		if !cookie.Expires.IsZero() {
+			expiry = cookie.Expires.Unix()
+		}
+		fmt.Fprintf(&b, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			cookie.Domain,
+			netscapeBool(strings.HasPrefix(cookie.Domain, ".")),
+			cookiePath(cookie.Path),
+			netscapeBool(cookie.Secure),
+			expiry,
+			cookie.Name,
+			cookie.Value,
+		)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// netscapeBool returns a flag as written in a Netscape cookies.txt file.
+func netscapeBool(value bool) string {
+	if value {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// harLog is the part of an HTTP Archive holding the cookies sent and received.
+type harLog struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				Cookies []harCookie `json:"cookies"`
+			} `json:"request"`
+			Response struct {
+				Cookies []harCookie `json:"cookies"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// harCookie is a cookie as recorded in an HTTP Archive.
+type harCookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path"`
+	Domain   string `json:"domain"`
+	Expires  string `json:"expires"`
+	HttpOnly bool   `json:"httpOnly"`
+	Secure   bool   `json:"secure"`
+}
+
+// ParseHARCookies reads the cookies sent and received in an HTTP Archive (HAR) file,
+// as saved from a browser's developer tools. Cookies sent in requests have no domain,
+// so they apply to whichever site they are loaded for. When a cookie name appears
+// several times, the last one recorded is kept, so cookies set by responses replace
+// those sent earlier. Returns an error if the file is not valid HAR JSON.
+func ParseHARCookies(r io.Reader) ([]*http.Cookie, error) {
+	var har harLog
+	if err := json.NewDecoder(r).Decode(&har); err != nil {
+		return nil, fmt.Errorf("error decoding HAR: %w", err)
+	}
+
+	var names []string
+	found := map[string]*http.Cookie{}
+	add := func(recorded harCookie) {
+		cookie := &http.Cookie{
+			Name:     recorded.Name,
+			Value:    recorded.Value,
+			Domain:   recorded.Domain,
+			Path:     recorded.Path,
+			Secure:   recorded.Secure,
+			HttpOnly: recorded.HttpOnly,
+		}
+		if expires, err := time.Parse(time.RFC3339, recorded.Expires); err == nil {
+			cookie.Expires = expires.UTC()
+		}
+
+		if _, ok := found[cookie.Name]; !ok {
+			names = append(names, cookie.Name)
+		}
+		found[cookie.Name] = cookie
+	}
+
+	for _, entry := range har.Log.Entries {
+		for _, cookie := range entry.Request.Cookies {
+			add(cookie)
+		}
+		for _, cookie := range entry.Response.Cookies {
+			add(cookie)
+		}
+	}
+
+	cookies := make([]*http.Cookie, 0, len(names))
+	for _, name := range names {
+		cookies = append(cookies, found[name])
+	}
+	return cookies, nil
+}
+
+// CookieHeader returns the cookies as the value of a Cookie request header, such as
+// "nexusmods_session=abc; nexusmods_session_refresh=def", for curl and other tools.
+func CookieHeader(cookies []*http.Cookie) string {
+	pairs := make([]string, 0, len(cookies))
+	for _, cookie := range cookies {
+		pairs = append(pairs, cookie.Name+"="+cookie.Value)
+	}
+	return strings.Join(pairs, "; ")
+}
@@ -0,0 +1,86 @@
+package httpclient
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const cookiesTxt = "# Netscape HTTP Cookie File\n" +
+	"\n" +
+	"#HttpOnly_.nexusmods.com\tTRUE\t/\tTRUE\t1893456000\tnexusmods_session\tabc\n" +
+	"nexusmods.com\tFALSE\t/\tFALSE\t0\tnexusmods_session_refresh\tdef\r\n"
+
+func TestParseNetscapeCookies(t *testing.T) {
+	// Act
+	cookies, err := ParseNetscapeCookies(strings.NewReader(cookiesTxt))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []*http.Cookie{
+		{Name: "nexusmods_session", Value: "abc", Domain: ".nexusmods.com", Path: "/", Expires: time.Unix(1893456000, 0).UTC(), Secure: true, HttpOnly: true},
+		{Name: "nexusmods_session_refresh", Value: "def", Domain: "nexusmods.com", Path: "/"},
+	}, cookies)
+}
+
+func TestParseNetscapeCookies_InvalidLine(t *testing.T) {
+	// Act
+	_, err := ParseNetscapeCookies(strings.NewReader("nexusmods.com\tFALSE\t/\n"))
+
+	// Assert
+	assert.EqualError(t, err, "invalid cookies.txt line 1: expected 7 tab-separated fields, got 3")
+}
+
+func TestWriteNetscapeCookies_RoundTrip(t *testing.T) {
+	// Arrange
+	cookies, err := ParseNetscapeCookies(strings.NewReader(cookiesTxt))
+	require.NoError(t, err)
+	var buf bytes.Buffer
+
+	// Act
+	err = WriteNetscapeCookies(&buf, cookies)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, strings.ReplaceAll(strings.Replace(cookiesTxt, "\n\n", "\n", 1), "\r", ""), buf.String())
+}
+
+func TestParseHARCookies(t *testing.T) {
+	// Arrange
+	har := `{"log":{"entries":[
+		{"request":{"url":"https://www.nexusmods.com/skyrim/mods/1","cookies":[{"name":"nexusmods_session","value":"old"}]},
+		 "response":{"cookies":[{"name":"nexusmods_session","value":"new","domain":".nexusmods.com","path":"/","expires":"2030-01-01T00:00:00.000Z","httpOnly":true,"secure":true}]}},
+		{"request":{"url":"https://www.nexusmods.com/","cookies":[{"name":"nexusmods_session_refresh","value":"r"}]},"response":{"cookies":[]}}
+	]}}`
+
+	// Act
+	cookies, err := ParseHARCookies(strings.NewReader(har))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []*http.Cookie{
+		{Name: "nexusmods_session", Value: "new", Domain: ".nexusmods.com", Path: "/", Expires: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), Secure: true, HttpOnly: true},
+		{Name: "nexusmods_session_refresh", Value: "r"},
+	}, cookies)
+}
+
+func TestParseHARCookies_Invalid(t *testing.T) {
+	// Act
+	_, err := ParseHARCookies(strings.NewReader("not json"))
+
+	// Assert
+	assert.ErrorContains(t, err, "error decoding HAR")
+}
+
+func TestCookieHeader(t *testing.T) {
+	// Act
+	header := CookieHeader([]*http.Cookie{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}})
+
+	// Assert
+	assert.Equal(t, "a=1; b=2", header)
+}
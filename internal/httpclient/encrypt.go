@@ -0,0 +1,131 @@
+package httpclient
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// PassphraseEnv is the environment variable holding the passphrase the cookie file is
+// encrypted with. When it is set, cookie files are written encrypted.
+const PassphraseEnv = "NMS_COOKIE_PASSPHRASE"
+
+const (
+	// encryptionCipher names the cipher encrypted cookie files use.
+	encryptionCipher = "aes-256-gcm"
+	// encryptionKDF names the function deriving the key from the passphrase.
+	encryptionKDF = "scrypt"
+	// scryptN, scryptR and scryptP are the scrypt cost parameters for new files.
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+	// keyLength is the AES-256 key length in bytes.
+	keyLength = 32
+	// saltLength is the length of the random salt in bytes.
+	saltLength = 16
+)
+
+var (
+	// ErrPassphraseRequired is returned when an encrypted cookie file is read without
+	// a passphrase.
+	ErrPassphraseRequired = fmt.Errorf("cookie file is encrypted, set %s to its passphrase", PassphraseEnv)
+	// ErrWrongPassphrase is returned when an encrypted cookie file cannot be decrypted.
+	ErrWrongPassphrase = errors.New("cannot decrypt cookie file, the passphrase is wrong or the file is damaged")
+
+	// Passphrase returns the passphrase cookie files are encrypted with, or an empty
+	// string to leave them unencrypted. It reads PassphraseEnv, and can be replaced
+	// in tests.
+	Passphrase = func() string {
+		return os.Getenv(PassphraseEnv)
+	}
+)
+
+// encryptedFile is a cookie file encrypted with a key derived from a passphrase, with
+// the parameters needed to derive the key again. Byte fields are saved as base64.
+type encryptedFile struct {
+	Encryption encryption `json:"encryption"`
+	Ciphertext []byte     `json:"ciphertext"`
+}
+
+// encryption holds the cipher and key derivation parameters of an encrypted file.
+type encryption struct {
+	Cipher string `json:"cipher"`
+	KDF    string `json:"kdf"`
+	N      int    `json:"n"`
+	R      int    `json:"r"`
+	P      int    `json:"p"`
+	Salt   []byte `json:"salt"`
+	Nonce  []byte `json:"nonce"`
+}
+
+// encrypt seals the plaintext with AES-256-GCM under a key derived from the
+// passphrase with scrypt and a random salt, returning the encrypted file as JSON.
+func encrypt(plaintext []byte, passphrase string) ([]byte, error) {
+	params := encryption{Cipher: encryptionCipher, KDF: encryptionKDF, N: scryptN, R: scryptR, P: scryptP}
+	params.Salt = make([]byte, saltLength)
+	if _, err := rand.Read(params.Salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(passphrase, params)
+	if err != nil {
+		return nil, err
+	}
+	params.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(params.Nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := encryptedFile{Encryption: params, Ciphertext: aead.Seal(nil, params.Nonce, plaintext, nil)}
+	return json.MarshalIndent(sealed, "", "    ")
+}
+
+// decrypt opens an encrypted file with the passphrase. Returns ErrPassphraseRequired
+// without a passphrase, or ErrWrongPassphrase if the file cannot be opened with it.
+func decrypt(data []byte, passphrase string) ([]byte, error) {
+	var sealed encryptedFile
+	if err := json.Unmarshal(data, &sealed); err != nil {
+		return nil, fmt.Errorf("error decoding JSON: %w", err)
+	}
+	if sealed.Encryption.Cipher != encryptionCipher || sealed.Encryption.KDF != encryptionKDF {
+		return nil, fmt.Errorf("unsupported cookie file encryption %s with %s", sealed.Encryption.Cipher, sealed.Encryption.KDF)
+	}
+	if passphrase == "" {
+		return nil, ErrPassphraseRequired
+	}
+
+	aead, err := newAEAD(passphrase, sealed.Encryption)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed.Encryption.Nonce) != aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	plaintext, err := aead.Open(nil, sealed.Encryption.Nonce, sealed.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	return plaintext, nil
+}
+
+// newAEAD derives the key from the passphrase with the file's scrypt parameters and
+// returns the AES-GCM cipher using it.
+func newAEAD(passphrase string, params encryption) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), params.Salt, params.N, params.R, params.P, keyLength)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving cookie file key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,55 @@
+package httpclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncrypt_RoundTrip(t *testing.T) {
+	// Arrange
+	plaintext := []byte(`{"version":2,"cookies":[]}`)
+
+	// Act
+	sealed, err := encrypt(plaintext, "secret")
+	require.NoError(t, err)
+	opened, err := decrypt(sealed, "secret")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+	assert.NotContains(t, string(sealed), "cookies")
+}
+
+func TestDecrypt_WrongPassphrase(t *testing.T) {
+	// Arrange
+	sealed, err := encrypt([]byte("{}"), "secret")
+	require.NoError(t, err)
+
+	// Act
+	_, err = decrypt(sealed, "guess")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+}
+
+func TestDecrypt_PassphraseRequired(t *testing.T) {
+	// Arrange
+	sealed, err := encrypt([]byte("{}"), "secret")
+	require.NoError(t, err)
+
+	// Act
+	_, err = decrypt(sealed, "")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrPassphraseRequired)
+}
+
+func TestDecrypt_UnsupportedEncryption(t *testing.T) {
+	// Act
+	_, err := decrypt([]byte(`{"encryption":{"cipher":"chacha20","kdf":"argon2id"},"ciphertext":""}`), "secret")
+
+	// Assert
+	assert.EqualError(t, err, "unsupported cookie file encryption chacha20 with argon2id")
+}
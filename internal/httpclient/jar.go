@@ -0,0 +1,70 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Jar is a cookie jar that also remembers each cookie's domain, path, expiry and
+// flags, which cookiejar.Jar does not return, so the cookies set by responses can
+// be saved to the cookie file.
+type Jar struct {
+	*cookiejar.Jar
+
+	mu      sync.Mutex
+	cookies map[string]*http.Cookie
+}
+
+// NewJar creates an empty Jar. Returns an error if the cookie jar cannot be created.
+func NewJar() (*Jar, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Jar{Jar: jar, cookies: map[string]*http.Cookie{}}, nil
+}
+
+// SetCookies stores the cookies received from u, remembering their attributes.
+// A cookie without a domain is stored for u's host, and a deleted cookie is
+// remembered as expired so saving removes it.
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.Jar.SetCookies(u, cookies)
+
+	now := time.Now()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, cookie := range cookies {
+		stored := *cookie
+		if stored.Domain == "" {
+			stored.Domain = u.Hostname()
+		}
+		stored.Path = cookiePath(stored.Path)
+		switch {
+		case stored.MaxAge < 0:
+			stored.Expires = time.Unix(0, 0)
+		case stored.MaxAge > 0:
+			stored.Expires = now.Add(time.Duration(stored.MaxAge) * time.Second)
+		}
+		stored.MaxAge = 0
+		stored.Raw, stored.RawExpires, stored.Unparsed = "", "", nil
+
+		j.cookies[cookieDomain(stored.Domain)+";"+stored.Path+";"+stored.Name] = &stored
+	}
+}
+
+// All returns every cookie the jar has been given with its attributes, including
+// expired and deleted ones, so saving them removes those from the cookie file.
+func (j *Jar) All() []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	cookies := make([]*http.Cookie, 0, len(j.cookies))
+	for _, cookie := range j.cookies {
+		copied := *cookie
+		cookies = append(cookies, &copied)
+	}
+	return cookies
+}
@@ -0,0 +1,51 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJar_RemembersResponseCookies(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "nexusmods_session", Value: "fresh", Path: "/", MaxAge: 3600, Secure: true, HttpOnly: true})
+		http.SetCookie(w, &http.Cookie{Name: "tracking", Value: "", MaxAge: -1})
+	}))
+	defer server.Close()
+	jar, err := NewJar()
+	require.NoError(t, err)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	jar.SetCookies(u, []*http.Cookie{{Name: "tracking", Value: "old"}})
+
+	// Act
+	_, err = (&http.Client{Jar: jar}).Get(server.URL)
+
+	// Assert
+	require.NoError(t, err)
+	cookies := map[string]*http.Cookie{}
+	for _, cookie := range jar.All() {
+		cookies[cookie.Name] = cookie
+	}
+	require.Len(t, cookies, 2)
+
+	session := cookies["nexusmods_session"]
+	assert.Equal(t, "fresh", session.Value)
+	assert.Equal(t, "127.0.0.1", session.Domain)
+	assert.Equal(t, "/", session.Path)
+	assert.True(t, session.Secure)
+	assert.True(t, session.HttpOnly)
+	assert.Zero(t, session.MaxAge)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), session.Expires, time.Minute)
+
+	assert.True(t, cookies["tracking"].Expires.Before(time.Now()), "deleted cookies are kept as expired")
+	sent := jar.Cookies(u)
+	require.Len(t, sent, 1, "deleted cookies are no longer sent")
+	assert.Equal(t, "nexusmods_session", sent[0].Name)
+}
@@ -0,0 +1,85 @@
+package httpclient
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// LoggingTransport is an http.RoundTripper that logs every request it sends with
+// the next transport: its URL, status, duration, the bytes read from the response
+// body, and whether the response was replayed from a cassette instead of fetched.
+type LoggingTransport struct {
+	Next   http.RoundTripper
+	Logger *slog.Logger
+}
+
+// NewLoggingTransport creates a LoggingTransport logging to logger, sending requests
+// with next or with http.DefaultTransport when next is nil.
+func NewLoggingTransport(next http.RoundTripper, logger *slog.Logger) *LoggingTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &LoggingTransport{Next: next, Logger: logger}
+}
+
+// RoundTrip sends the request, logging it once its response body has been read or
+// closed, or straight away when the request fails.
+func (t *LoggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	_, cacheHit := t.Next.(*Replayer)
+
+	resp, err := t.Next.RoundTrip(req)
+	if err != nil {
+		t.Logger.Warn("request failed",
+			"method", req.Method,
+			"url", req.URL.String(),
+			"duration", time.Since(start),
+			"cache_hit", cacheHit,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	resp.Body = &loggedBody{
+		ReadCloser: resp.Body,
+		log: func(bytes int64) {
+			t.Logger.Info("request",
+				"method", req.Method,
+				"url", req.URL.String(),
+				"status", resp.StatusCode,
+				"duration", time.Since(start),
+				"bytes", bytes,
+				"cache_hit", cacheHit,
+			)
+		},
+	}
+	return resp, nil
+}
+
+// loggedBody counts the bytes read from a response body and logs the request the
+// first time the body reaches its end or is closed.
+type loggedBody struct {
+	io.ReadCloser
+	bytes int64
+	once  sync.Once
+	log   func(bytes int64)
+}
+
+// Read reads from the body, logging the request at its end.
+func (b *loggedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.bytes += int64(n)
+	if err == io.EOF {
+		b.once.Do(func() { b.log(b.bytes) })
+	}
+	return n, err
+}
+
+// Close closes the body, logging the request if it was not read to its end.
+func (b *loggedBody) Close() error {
+	b.once.Do(func() { b.log(b.bytes) })
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,127 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roundTripFunc adapts a function to an http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// decodeRecords decodes the JSON log records written to out.
+func decodeRecords(t *testing.T, out *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	decoder := json.NewDecoder(out)
+	for decoder.More() {
+		var record map[string]any
+		require.NoError(t, decoder.Decode(&record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLoggingTransport_LogsRequest(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "<html>mod page</html>")
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	transport := NewLoggingTransport(nil, slog.New(slog.NewJSONHandler(&out, nil)))
+
+	// Act
+	resp, body := get(t, transport, server.URL+"/skyrim/mods/1")
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "<html>mod page</html>", body)
+	records := decodeRecords(t, &out)
+	require.Len(t, records, 1)
+	assert.Equal(t, "request", records[0]["msg"])
+	assert.Equal(t, "GET", records[0]["method"])
+	assert.Equal(t, server.URL+"/skyrim/mods/1", records[0]["url"])
+	assert.Equal(t, float64(http.StatusOK), records[0]["status"])
+	assert.Equal(t, float64(len(body)), records[0]["bytes"])
+	assert.Equal(t, false, records[0]["cache_hit"])
+	assert.Contains(t, records[0], "duration")
+}
+
+func TestLoggingTransport_Replay(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	interaction := Interaction{
+		Request:  RecordedRequest{Method: "GET", URL: "https://nexusmods.com/skyrim/mods/1"},
+		Response: RecordedResponse{StatusCode: http.StatusOK, Body: "recorded"},
+	}
+	data, err := json.Marshal(interaction)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, cassetteFilename("GET", interaction.Request.URL)), data, 0644))
+
+	var out bytes.Buffer
+	transport := NewLoggingTransport(NewReplayer(dir), slog.New(slog.NewJSONHandler(&out, nil)))
+
+	// Act
+	get(t, transport, interaction.Request.URL)
+
+	// Assert
+	records := decodeRecords(t, &out)
+	require.Len(t, records, 1)
+	assert.Equal(t, true, records[0]["cache_hit"])
+	assert.Equal(t, float64(len("recorded")), records[0]["bytes"])
+}
+
+func TestLoggingTransport_Failure(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+	failing := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+	transport := NewLoggingTransport(failing, slog.New(slog.NewJSONHandler(&out, nil)))
+
+	// Act
+	_, err := (&http.Client{Transport: transport}).Get("https://nexusmods.com/")
+
+	// Assert
+	assert.ErrorContains(t, err, "connection refused")
+	records := decodeRecords(t, &out)
+	require.Len(t, records, 1)
+	assert.Equal(t, "request failed", records[0]["msg"])
+	assert.Equal(t, "WARN", records[0]["level"])
+	assert.Equal(t, "connection refused", records[0]["error"])
+}
+
+func TestNewTransport_Logger(t *testing.T) {
+	// Arrange
+	clearProxyEnv(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	transport, err := NewTransport(TransportOptions{Logger: slog.New(slog.NewJSONHandler(&out, nil))})
+	require.NoError(t, err)
+
+	// Act
+	get(t, transport, server.URL)
+
+	// Assert
+	records := decodeRecords(t, &out)
+	require.Len(t, records, 1)
+	assert.Equal(t, server.URL, records[0]["url"])
+}
@@ -0,0 +1,258 @@
+package httpclient
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRefreshPath is the endpoint exchanging the refresh cookie for a new session.
+	DefaultRefreshPath = "/users/session/refresh"
+	// SessionCookie is the cookie holding the logged-in session.
+	SessionCookie = "nexusmods_session"
+	// RefreshCookie is the longer-lived cookie used to renew the session.
+	RefreshCookie = "nexusmods_session_refresh"
+)
+
+// ErrRefreshFailed is returned when the session cannot be renewed with the refresh
+// cookie, and the cookies must be extracted from a browser again.
+var ErrRefreshFailed = errors.New("session refresh failed, log in through a browser and run extract again")
+
+// SessionRefresher is an http.RoundTripper that renews an expired session. Before a
+// request it renews a session known to have expired, and when a response shows the
+// session has expired it renews it and retries the request once. Renewing sends the
+// refresh cookie to the refresh endpoint, adds the returned cookies to the jar, and
+// passes them to OnRefresh so they can be saved.
+type SessionRefresher struct {
+	// BaseURL is the site the session cookies belong to.
+	BaseURL string
+	// RefreshPath is the refresh endpoint on the base URL, defaulting to DefaultRefreshPath.
+	RefreshPath string
+	// Jar holds the session cookies and receives the renewed ones.
+	Jar http.CookieJar
+	// Next sends the requests, defaulting to http.DefaultTransport.
+	Next http.RoundTripper
+	// OnRefresh is called with the renewed cookies after each refresh.
+	OnRefresh func(cookies []*http.Cookie) error
+
+	mu         sync.Mutex
+	expires    time.Time
+	generation int
+}
+
+// NewSessionRefresher creates a SessionRefresher for the session cookies in jar,
+// where expires is when the session cookie expires, zero when unknown.
+func NewSessionRefresher(baseURL string, jar http.CookieJar, next http.RoundTripper, expires time.Time, onRefresh func(cookies []*http.Cookie) error) *SessionRefresher {
+	return &SessionRefresher{
+		BaseURL:   baseURL,
+		Jar:       jar,
+		Next:      next,
+		OnRefresh: onRefresh,
+		expires:   expires,
+	}
+}
+
+// RoundTrip sends the request, renewing the session first when it is known to have
+// expired, and renewing it and retrying once when the response shows it has expired.
+// Requests are sent unchanged when there is no refresh cookie. Returns an error if
+// the request fails or the session cannot be renewed.
+func (r *SessionRefresher) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !r.canRefresh() {
+		return r.next().RoundTrip(req)
+	}
+
+	r.mu.Lock()
+	if !r.expires.IsZero() && time.Now().After(r.expires) {
+		if err := r.refresh(); err != nil {
+			r.mu.Unlock()
+			return nil, err
+		}
+	}
+	generation := r.generation
+	r.mu.Unlock()
+
+	resp, err := r.next().RoundTrip(withJarCookies(req, r.Jar))
+	if err != nil || !sessionExpired(resp) || !canRetry(req) {
+		return resp, err
+	}
+	resp.Body.Close()
+
+	r.mu.Lock()
+	// Another request may already have renewed the session while this one was sent
+	if r.generation == generation {
+		err = r.refresh()
+	}
+	r.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	retry, err := rewind(req)
+	if err != nil {
+		return nil, err
+	}
+	return r.next().RoundTrip(withJarCookies(retry, r.Jar))
+}
+
+// refresh exchanges the refresh cookie for a new session, updating the jar and the
+// known expiry and passing the new cookies to OnRefresh. Must be called with mu held.
+func (r *SessionRefresher) refresh() error {
+	base, err := url.Parse(r.BaseURL)
+	if err != nil {
+		return fmt.Errorf("error parsing domain: %w", err)
+	}
+
+	refreshCookie := r.refreshCookie(base)
+	if refreshCookie == nil {
+		return fmt.Errorf("%w: no %s cookie", ErrRefreshFailed, RefreshCookie)
+	}
+
+	path := r.RefreshPath
+	if path == "" {
+		path = DefaultRefreshPath
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimRight(r.BaseURL, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	req.AddCookie(&http.Cookie{Name: RefreshCookie, Value: refreshCookie.Value})
+
+	resp, err := r.next().RoundTrip(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRefreshFailed, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	var session *http.Cookie
+	renewed := resp.Cookies()
+	for _, cookie := range renewed {
+		if cookie.Name == SessionCookie && cookie.Value != "" {
+			session = cookie
+		}
+	}
+	if resp.StatusCode != http.StatusOK || session == nil {
+		return fmt.Errorf("%w: %s returned %d", ErrRefreshFailed, req.URL, resp.StatusCode)
+	}
+
+	r.Jar.SetCookies(base, renewed)
+	r.expires = session.Expires
+	if r.expires.IsZero() {
+		r.expires = TokenExpiry(session.Value)
+	}
+	r.generation++
+
+	if r.OnRefresh != nil {
+		if err := r.OnRefresh(renewed); err != nil {
+			return fmt.Errorf("error saving refreshed cookies: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// canRefresh reports whether the jar holds a refresh cookie, so requests without
+// one, such as anonymous ones, pass through unchanged.
+func (r *SessionRefresher) canRefresh() bool {
+	base, err := url.Parse(r.BaseURL)
+	return err == nil && r.refreshCookie(base) != nil
+}
+
+// refreshCookie returns the refresh cookie the jar holds for base, or nil.
+func (r *SessionRefresher) refreshCookie(base *url.URL) *http.Cookie {
+	if r.Jar == nil {
+		return nil
+	}
+	for _, cookie := range r.Jar.Cookies(base) {
+		if cookie.Name == RefreshCookie {
+			return cookie
+		}
+	}
+	return nil
+}
+
+// next returns the transport that sends requests.
+func (r *SessionRefresher) next() http.RoundTripper {
+	if r.Next == nil {
+		return http.DefaultTransport
+	}
+	return r.Next
+}
+
+// sessionExpired reports whether a response shows the session has expired, either
+// by refusing it as unauthorized or by redirecting to sign in.
+func sessionExpired(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusUnauthorized {
+		return true
+	}
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		location := strings.ToLower(resp.Header.Get("Location"))
+		return strings.Contains(location, "login") || strings.Contains(location, "sign_in")
+	}
+	return false
+}
+
+// canRetry reports whether a request can be sent again after the session is renewed.
+func canRetry(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewind returns a copy of the request that can be sent again.
+func rewind(req *http.Request) (*http.Request, error) {
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	return retry, nil
+}
+
+// withJarCookies returns the request with its Cookie header set from the jar, so a
+// retried request carries the renewed session.
+func withJarCookies(req *http.Request, jar http.CookieJar) *http.Request {
+	cookies := jar.Cookies(req.URL)
+	if len(cookies) == 0 {
+		return req
+	}
+
+	withCookies := req.Clone(req.Context())
+	withCookies.Header.Del("Cookie")
+	for _, cookie := range cookies {
+		withCookies.AddCookie(cookie)
+	}
+	return withCookies
+}
+
+// TokenExpiry returns the expiry claim of a JSON web token, or zero when the value
+// is not a token or has no expiry.
+func TokenExpiry(value string) time.Time {
+	parts := strings.Split(value, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(claims.Exp, 0)
+}
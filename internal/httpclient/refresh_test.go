@@ -0,0 +1,206 @@
+package httpclient
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// refreshSite is a stand-in for the site, serving pages only to the fresh session,
+// sending stale sessions to sign in, and exchanging the valid refresh cookie for a
+// fresh session.
+type refreshSite struct {
+	*httptest.Server
+	refreshes int32
+	stale     int32
+}
+
+func newRefreshSite(t *testing.T) *refreshSite {
+	site := &refreshSite{}
+	site.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == DefaultRefreshPath {
+			atomic.AddInt32(&site.refreshes, 1)
+			cookie, err := r.Cookie(RefreshCookie)
+			if r.Method != http.MethodPost || err != nil || cookie.Value != "refresh-1" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: SessionCookie, Value: "fresh", Path: "/"})
+			http.SetCookie(w, &http.Cookie{Name: RefreshCookie, Value: "refresh-2", Path: "/"})
+			return
+		}
+		if r.URL.Path == "/login" {
+			w.Write([]byte("sign in"))
+			return
+		}
+
+		if cookie, err := r.Cookie(SessionCookie); err != nil || cookie.Value != "fresh" {
+			atomic.AddInt32(&site.stale, 1)
+			http.Redirect(w, r, "/login", http.StatusFound)
+			return
+		}
+		w.Write([]byte("mod page"))
+	}))
+	t.Cleanup(site.Close)
+	return site
+}
+
+// newStaleJar returns a cookie jar holding a stale session and the given refresh cookie.
+func newStaleJar(t *testing.T, siteURL, refresh string) http.CookieJar {
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	u, err := url.Parse(siteURL)
+	require.NoError(t, err)
+	jar.SetCookies(u, []*http.Cookie{{Name: SessionCookie, Value: "stale"}, {Name: RefreshCookie, Value: refresh}})
+	return jar
+}
+
+func TestSessionRefresher_RefreshesExpiredSession(t *testing.T) {
+	// Arrange
+	site := newRefreshSite(t)
+	jar := newStaleJar(t, site.URL, "refresh-1")
+	var saved []*http.Cookie
+	refresher := NewSessionRefresher(site.URL, jar, nil, time.Time{}, func(cookies []*http.Cookie) error {
+		saved = cookies
+		return nil
+	})
+	client := &http.Client{Jar: jar, Transport: refresher}
+
+	// Act
+	resp, err := client.Get(site.URL + "/skyrim/mods/1")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, "mod page", string(body))
+	assert.Equal(t, int32(1), site.refreshes)
+	require.Len(t, saved, 2)
+	assert.Equal(t, "fresh", saved[0].Value)
+	assert.Equal(t, "refresh-2", saved[1].Value)
+}
+
+func TestSessionRefresher_RefreshesOnceForConcurrentRequests(t *testing.T) {
+	// Arrange
+	site := newRefreshSite(t)
+	jar := newStaleJar(t, site.URL, "refresh-1")
+	client := &http.Client{Jar: jar, Transport: NewSessionRefresher(site.URL, jar, nil, time.Time{}, nil)}
+
+	// Act
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := client.Get(site.URL + "/skyrim/mods/1")
+			if err == nil {
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusOK {
+					err = fmt.Errorf("status %d", resp.StatusCode)
+				}
+			}
+			errs[i] = err
+		}(i)
+	}
+	wg.Wait()
+
+	// Assert
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), site.refreshes)
+}
+
+func TestSessionRefresher_RefreshesKnownExpiryBeforeRequest(t *testing.T) {
+	// Arrange
+	site := newRefreshSite(t)
+	jar := newStaleJar(t, site.URL, "refresh-1")
+	client := &http.Client{Jar: jar, Transport: NewSessionRefresher(site.URL, jar, nil, time.Now().Add(-time.Minute), nil)}
+
+	// Act
+	resp, err := client.Get(site.URL + "/skyrim/mods/1")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(1), site.refreshes)
+	assert.Equal(t, int32(0), site.stale)
+}
+
+func TestSessionRefresher_RefreshRejected(t *testing.T) {
+	// Arrange
+	site := newRefreshSite(t)
+	jar := newStaleJar(t, site.URL, "revoked")
+	client := &http.Client{Jar: jar, Transport: NewSessionRefresher(site.URL, jar, nil, time.Time{}, nil)}
+
+	// Act
+	_, err := client.Get(site.URL + "/skyrim/mods/1")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrRefreshFailed)
+	assert.ErrorContains(t, err, "returned 401")
+}
+
+func TestSessionRefresher_NoRefreshCookie(t *testing.T) {
+	// Arrange
+	site := newRefreshSite(t)
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	client := &http.Client{Jar: jar, Transport: NewSessionRefresher(site.URL, jar, nil, time.Now().Add(-time.Minute), nil)}
+
+	// Act
+	resp, err := client.Get(site.URL + "/skyrim/mods/1")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, site.URL+"/login", resp.Request.URL.String())
+	assert.Equal(t, int32(0), site.refreshes)
+}
+
+func TestSaveCookies_KeepsOtherCookies(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "session-cookies.json"), []byte(`{"nexusmods_session":"stale","other":"kept"}`), 0644))
+
+	// Act
+	err := SaveCookies(dir, "session-cookies.json", []*http.Cookie{{Name: SessionCookie, Value: "fresh"}})
+
+	// Assert
+	require.NoError(t, err)
+	cookies, err := LoadCookies(dir, "session-cookies.json")
+	require.NoError(t, err)
+	values := map[string]string{}
+	for _, cookie := range cookies {
+		values[cookie.Name] = cookie.Value
+	}
+	assert.Equal(t, map[string]string{"nexusmods_session": "fresh", "other": "kept"}, values)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+}
+
+func TestTokenExpiry(t *testing.T) {
+	// Arrange
+	expires := time.Unix(1735689600, 0)
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"sub":"1","exp":%d}`, expires.Unix())))
+
+	// Act & Assert
+	assert.True(t, expires.Equal(TokenExpiry("eyJhbGciOiJIUzI1NiJ9."+payload+".c2ln")))
+	assert.True(t, TokenExpiry("plain-session-id").IsZero())
+	assert.True(t, TokenExpiry("a.!!!.c").IsZero())
+}
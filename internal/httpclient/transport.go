@@ -0,0 +1,164 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// DefaultUserAgent is the User-Agent sent when none is configured, naming the scraper
+// instead of Go's default.
+const DefaultUserAgent = "nexus-mods-scraper (+https://github.com/ondrovic/nexus-mods-scraper)"
+
+// TransportOptions configures how requests reach the site: through a proxy, with a
+// User-Agent, trusting an extra CA certificate, with extra headers, and logged.
+type TransportOptions struct {
+	// Proxy is the http, https, socks5 or socks5h proxy URL. When empty, the
+	// HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables are used.
+	Proxy string
+	// UserAgent is sent with every request, DefaultUserAgent when empty.
+	UserAgent string
+	// CACert is a PEM file of CA certificates trusted along with the system's.
+	CACert string
+	// Headers are extra headers sent with every request, each as "Name: value".
+	Headers []string
+	// Logger logs every request when set.
+	Logger *slog.Logger
+}
+
+// NewTransport builds the transport for the options, sending requests through the
+// proxy with the User-Agent and extra headers, trusting the CA certificates, and
+// logging each request when a logger is given.
+// Returns an error if the proxy URL, CA certificate file or a header is invalid.
+func NewTransport(opts TransportOptions) (http.RoundTripper, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	proxy, err := proxyFunc(opts.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	transport.Proxy = proxy
+
+	if opts.CACert != "" {
+		pool, err := certPool(opts.CACert)
+		if err != nil {
+			return nil, err
+		}
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	headers, err := parseHeaders(opts.Headers)
+	if err != nil {
+		return nil, err
+	}
+
+	userAgent := opts.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+
+	var roundTripper http.RoundTripper = &headerTransport{next: transport, userAgent: userAgent, headers: headers}
+	if opts.Logger != nil {
+		roundTripper = NewLoggingTransport(roundTripper, opts.Logger)
+	}
+	return roundTripper, nil
+}
+
+// proxyFunc returns the function choosing the proxy for each request, the proxy
+// given or, when empty, the one in the environment.
+func proxyFunc(proxy string) (func(*http.Request) (*url.URL, error), error) {
+	if proxy == "" {
+		// Read the environment now, where http.ProxyFromEnvironment reads it once
+		fromEnv := httpproxy.FromEnvironment().ProxyFunc()
+		return func(req *http.Request) (*url.URL, error) {
+			return fromEnv(req.URL)
+		}, nil
+	}
+
+	proxyURL, err := url.Parse(proxy)
+	if err != nil || proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q, expected a URL such as http://host:port or socks5://host:port", proxy)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return http.ProxyURL(proxyURL), nil
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, use http, https, socks5 or socks5h", proxyURL.Scheme)
+	}
+}
+
+// certPool returns the system's CA certificates along with those in the PEM file.
+func certPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading CA certificate: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", path)
+	}
+	return pool, nil
+}
+
+// HeaderNames returns the names of the headers, each given as "Name: value",
+// skipping any without a name.
+func HeaderNames(headers []string) []string {
+	var names []string
+	for _, header := range headers {
+		name, _, _ := strings.Cut(header, ":")
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, textproto.CanonicalMIMEHeaderKey(name))
+		}
+	}
+	return names
+}
+
+// parseHeaders parses the headers, each given as "Name: value".
+func parseHeaders(headers []string) (http.Header, error) {
+	parsed := http.Header{}
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid header %q, expected \"Name: value\"", header)
+		}
+		parsed.Add(textproto.CanonicalMIMEHeaderKey(name), strings.TrimSpace(value))
+	}
+	return parsed, nil
+}
+
+// headerTransport sets the User-Agent and extra headers on each request before
+// sending it with next.
+type headerTransport struct {
+	next      http.RoundTripper
+	userAgent string
+	headers   http.Header
+}
+
+// RoundTrip sends a copy of the request with the User-Agent, unless the request sets
+// its own, and the extra headers, which replace any the request has.
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	for name, values := range t.headers {
+		req.Header[name] = values
+	}
+	return t.next.RoundTrip(req)
+}
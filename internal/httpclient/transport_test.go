@@ -0,0 +1,317 @@
+package httpclient
+
+import (
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newProxyStandIn starts an HTTP proxy stand-in answering every request itself with
+// the host and path asked for, and tunnelling CONNECT requests to the target given.
+func newProxyStandIn(t *testing.T, target string) *httptest.Server {
+	t.Helper()
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodConnect {
+			backend, err := net.Dial("tcp", target)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadGateway)
+				return
+			}
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				backend.Close()
+				return
+			}
+			_, _ = conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+			go func() { _, _ = io.Copy(backend, conn); backend.Close() }()
+			go func() { _, _ = io.Copy(conn, backend); conn.Close() }()
+			return
+		}
+		w.Header().Set("X-Proxied", "true")
+		_, _ = io.WriteString(w, r.URL.Host+r.URL.Path)
+	}))
+	t.Cleanup(proxy.Close)
+	return proxy
+}
+
+// newSOCKS5StandIn starts a SOCKS5 proxy stand-in that connects every request to the
+// target given, recording the address each one asked for.
+func newSOCKS5StandIn(t *testing.T, target string) (string, <-chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	requested := make(chan string, 1)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSOCKS5(conn, target, requested)
+		}
+	}()
+	return listener.Addr().String(), requested
+}
+
+// serveSOCKS5 answers a SOCKS5 greeting and CONNECT request without authentication,
+// then relays the connection to the target.
+func serveSOCKS5(conn net.Conn, target string, requested chan<- string) {
+	defer conn.Close()
+
+	greeting := make([]byte, 2)
+	if _, err := io.ReadFull(conn, greeting); err != nil {
+		return
+	}
+	if _, err := io.ReadFull(conn, make([]byte, greeting[1])); err != nil {
+		return
+	}
+	_, _ = conn.Write([]byte{5, 0})
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return
+	}
+	var host string
+	switch header[3] {
+	case 1:
+		addr := make([]byte, 4)
+		_, _ = io.ReadFull(conn, addr)
+		host = net.IP(addr).String()
+	case 3:
+		length := make([]byte, 1)
+		_, _ = io.ReadFull(conn, length)
+		name := make([]byte, length[0])
+		_, _ = io.ReadFull(conn, name)
+		host = string(name)
+	default:
+		return
+	}
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(conn, port); err != nil {
+		return
+	}
+	requested <- net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port))))
+
+	backend, err := net.Dial("tcp", target)
+	if err != nil {
+		_, _ = conn.Write([]byte{5, 1, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer backend.Close()
+	_, _ = conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+
+	go func() { _, _ = io.Copy(backend, conn) }()
+	_, _ = io.Copy(conn, backend)
+}
+
+// writeCACert writes the TLS server's certificate as a PEM file and returns its path.
+func writeCACert(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	return path
+}
+
+// clearProxyEnv unsets the proxy environment variables for the test.
+func clearProxyEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy"} {
+		t.Setenv(name, "")
+	}
+}
+
+// get sends a GET request for the URL through the transport and returns the response
+// and its body.
+func get(t *testing.T, transport http.RoundTripper, url string) (*http.Response, string) {
+	t.Helper()
+	resp, err := (&http.Client{Transport: transport}).Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+func TestNewTransport_Proxy(t *testing.T) {
+	// Arrange
+	clearProxyEnv(t)
+	proxy := newProxyStandIn(t, "")
+
+	transport, err := NewTransport(TransportOptions{Proxy: proxy.URL})
+	require.NoError(t, err)
+
+	// Act
+	resp, body := get(t, transport, "http://nexus.test/mods/1")
+
+	// Assert
+	assert.Equal(t, "true", resp.Header.Get("X-Proxied"))
+	assert.Equal(t, "nexus.test/mods/1", body)
+}
+
+func TestNewTransport_ProxyFromEnvironment(t *testing.T) {
+	// Arrange
+	clearProxyEnv(t)
+	proxy := newProxyStandIn(t, "")
+	t.Setenv("HTTP_PROXY", proxy.URL)
+
+	transport, err := NewTransport(TransportOptions{})
+	require.NoError(t, err)
+
+	// Act
+	resp, body := get(t, transport, "http://nexus.test/mods/1")
+
+	// Assert
+	assert.Equal(t, "true", resp.Header.Get("X-Proxied"))
+	assert.Equal(t, "nexus.test/mods/1", body)
+}
+
+func TestNewTransport_HTTPSProxyFromEnvironment(t *testing.T) {
+	// Arrange
+	clearProxyEnv(t)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "tunnelled "+r.Host)
+	}))
+	defer server.Close()
+	proxy := newProxyStandIn(t, server.Listener.Addr().String())
+	t.Setenv("HTTPS_PROXY", proxy.URL)
+
+	transport, err := NewTransport(TransportOptions{CACert: writeCACert(t, server)})
+	require.NoError(t, err)
+
+	// Act
+	_, body := get(t, transport, "https://example.com/")
+
+	// Assert
+	assert.Equal(t, "tunnelled example.com", body)
+}
+
+func TestNewTransport_SOCKS5Proxy(t *testing.T) {
+	// Arrange
+	clearProxyEnv(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "via socks "+r.Host)
+	}))
+	defer server.Close()
+	addr, requested := newSOCKS5StandIn(t, server.Listener.Addr().String())
+
+	transport, err := NewTransport(TransportOptions{Proxy: "socks5h://" + addr})
+	require.NoError(t, err)
+
+	// Act
+	_, body := get(t, transport, "http://nexus.test:8080/")
+
+	// Assert
+	assert.Equal(t, "via socks nexus.test:8080", body)
+	assert.Equal(t, "nexus.test:8080", <-requested)
+}
+
+func TestNewTransport_CACert(t *testing.T) {
+	// Arrange
+	clearProxyEnv(t)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "trusted")
+	}))
+	defer server.Close()
+
+	trusting, err := NewTransport(TransportOptions{CACert: writeCACert(t, server)})
+	require.NoError(t, err)
+	untrusting, err := NewTransport(TransportOptions{})
+	require.NoError(t, err)
+
+	// Act
+	_, body := get(t, trusting, server.URL)
+	_, untrustedErr := (&http.Client{Transport: untrusting}).Get(server.URL)
+
+	// Assert
+	assert.Equal(t, "trusted", body)
+	assert.ErrorContains(t, untrustedErr, "certificate")
+}
+
+func TestNewTransport_Headers(t *testing.T) {
+	// Arrange
+	clearProxyEnv(t)
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	tests := map[string]struct {
+		opts      TransportOptions
+		userAgent string
+	}{
+		"default user agent": {opts: TransportOptions{}, userAgent: DefaultUserAgent},
+		"custom user agent":  {opts: TransportOptions{UserAgent: "my-agent/1.0"}, userAgent: "my-agent/1.0"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			transport, err := NewTransport(tt.opts)
+			require.NoError(t, err)
+
+			// Act
+			get(t, transport, server.URL)
+
+			// Assert
+			assert.Equal(t, tt.userAgent, received.Get("User-Agent"))
+		})
+	}
+
+	t.Run("extra headers", func(t *testing.T) {
+		transport, err := NewTransport(TransportOptions{Headers: []string{"x-api-key: secret", "Accept-Language: en"}})
+		require.NoError(t, err)
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept-Language", "de")
+
+		// Act
+		resp, err := transport.RoundTrip(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		// Assert
+		assert.Equal(t, "secret", received.Get("X-Api-Key"))
+		assert.Equal(t, []string{"en"}, received.Values("Accept-Language"))
+		assert.Equal(t, "de", req.Header.Get("Accept-Language"), "the caller's request is left unchanged")
+	})
+}
+
+func TestNewTransport_Invalid(t *testing.T) {
+	// Arrange
+	emptyPEM := filepath.Join(t.TempDir(), "empty.pem")
+	require.NoError(t, os.WriteFile(emptyPEM, []byte("not a certificate"), 0644))
+
+	tests := map[string]struct {
+		opts     TransportOptions
+		expected string
+	}{
+		"proxy without host": {opts: TransportOptions{Proxy: "localhost"}, expected: `invalid proxy "localhost", expected a URL such as http://host:port or socks5://host:port`},
+		"proxy scheme":       {opts: TransportOptions{Proxy: "ftp://proxy:21"}, expected: `unsupported proxy scheme "ftp", use http, https, socks5 or socks5h`},
+		"missing CA file":    {opts: TransportOptions{CACert: filepath.Join(t.TempDir(), "missing.pem")}, expected: "error reading CA certificate"},
+		"CA file not PEM":    {opts: TransportOptions{CACert: emptyPEM}, expected: "no PEM certificates found in " + emptyPEM},
+		"header":             {opts: TransportOptions{Headers: []string{"no colon"}}, expected: `invalid header "no colon", expected "Name: value"`},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			_, err := NewTransport(tt.opts)
+
+			// Assert
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/logging"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/scheduler"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
+	"github.com/ondrovic/nexus-mods-scraper/pkg/nexus"
+)
+
+// Options configures the API server.
+type Options struct {
+	// OutputDirectory holds the saved results listed by GET /games/{game}/mods.
+	OutputDirectory string
+	// CacheTTL is how long a scraped mod is served from memory, 0 disables caching.
+	CacheTTL time.Duration
+	// CacheSize is the most mods kept in memory. When the cache is full, expired
+	// mods are evicted first and then the mod expiring soonest.
+	CacheSize int
+	// MaxBatch limits the number of mods in a single POST /scrape request.
+	MaxBatch int
+	// Workers is the number of mods in a POST /scrape batch scraped at once.
+	Workers int
+	// PerHost limits the mods in a POST /scrape batch scraped at once from the same
+	// host, 0 means no limit.
+	PerHost int
+	// Logger logs each mod served from the cache and each saved file skipped because
+	// it cannot be read, by default nothing is logged.
+	Logger *slog.Logger
+}
+
+// ScrapeRequest identifies one mod in a POST /scrape batch.
+type ScrapeRequest struct {
+	Game  string `json:"game"`
+	ModID int64  `json:"modId"`
+}
+
+// ScrapeResult is the outcome of one mod in a POST /scrape batch, holding either
+// the results or the error that stopped the scrape.
+type ScrapeResult struct {
+	Game    string         `json:"game"`
+	ModID   int64          `json:"modId"`
+	Results *types.Results `json:"results,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// maxRequestBody is the largest POST /scrape body read, far more than the batch
+// limit allows, so large bodies are refused before they are buffered.
+const maxRequestBody = 1 << 20
+
+// gamePattern matches the game slugs used in mod URLs, such as skyrimspecialedition.
+var gamePattern = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+// cacheEntry is a scraped mod and the time it stops being served from the cache.
+type cacheEntry struct {
+	results types.Results
+	expires time.Time
+}
+
+// call is a scrape in progress that identical requests wait on instead of
+// starting their own.
+type call struct {
+	done    chan struct{}
+	results types.Results
+	err     error
+}
+
+// Server serves scraped and saved mods over HTTP. Scrapes are cached in memory
+// for the configured TTL and concurrent requests for the same mod share one fetch.
+type Server struct {
+	options Options
+	scraper *nexus.Scraper
+	now     func() time.Time
+
+	mu       sync.Mutex
+	cache    map[string]cacheEntry
+	inflight map[string]*call
+}
+
+// New creates a Server that scrapes mods with the given scraper and its session.
+func New(options Options, scraper *nexus.Scraper) *Server {
+	if options.MaxBatch <= 0 {
+		options.MaxBatch = 50
+	}
+	if options.Workers <= 0 {
+		options.Workers = 4
+	}
+	if options.CacheSize <= 0 {
+		options.CacheSize = 1000
+	}
+	if options.Logger == nil {
+		options.Logger = logging.Discard()
+	}
+
+	return &Server{
+		options:  options,
+		scraper:  scraper,
+		now:      time.Now,
+		cache:    map[string]cacheEntry{},
+		inflight: map[string]*call{},
+	}
+}
+
+// Handler returns the HTTP handler exposing the API routes.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.HandleFunc("GET /games/{game}/mods", s.handleListMods)
+	mux.HandleFunc("GET /games/{game}/mods/{id}", s.handleGetMod)
+	mux.HandleFunc("POST /scrape", s.handleScrape)
+	return mux
+}
+
+// handleHealth reports that the server is up.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleListMods returns the saved results for a game, or an empty list when
+// nothing has been saved for it.
+func (s *Server) handleListMods(w http.ResponseWriter, r *http.Request) {
+	game := strings.ToLower(r.PathValue("game"))
+	if err := validateGame(game); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// The slug cannot leave the output directory, checked again in case that changes
+	root := filepath.Clean(s.options.OutputDirectory)
+	dir := filepath.Join(root, game)
+	if !strings.HasPrefix(dir, root+string(filepath.Separator)) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid game %q", game))
+		return
+	}
+
+	mods := []types.ModInfo{}
+	if _, err := os.Stat(dir); err == nil {
+		saved, err := storage.LoadSavedResults(dir, s.options.Logger)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		for _, results := range saved {
+			mods = append(mods, results.Mods)
+		}
+	}
+
+	writeJson(w, http.StatusOK, mods)
+}
+
+// handleGetMod returns a mod, served from the cache when possible. The refresh
+// query parameter forces a new scrape.
+func (s *Server) handleGetMod(w http.ResponseWriter, r *http.Request) {
+	game := strings.ToLower(r.PathValue("game"))
+	if err := validateGame(game); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	modID, err := formatters.StrToInt(r.PathValue("id"))
+	if err != nil || modID <= 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid mod id %q", r.PathValue("id")))
+		return
+	}
+
+	refresh := r.URL.Query().Get("refresh")
+	results, err := s.Scrape(r.Context(), game, modID, refresh == "1" || refresh == "true")
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	writeJson(w, http.StatusOK, results)
+}
+
+// handleScrape scrapes a batch of mods on a pool of workers and returns a result for
+// each, in request order. A failed mod is reported in its result rather than failing
+// the whole batch, and bodies over maxRequestBody are refused.
+func (s *Server) handleScrape(w http.ResponseWriter, r *http.Request) {
+	var requests []ScrapeRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody)).Decode(&requests); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("request body is larger than %d bytes", maxRequestBody))
+			return
+		}
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body, expected [{\"game\": \"...\", \"modId\": 123}]: %w", err))
+		return
+	}
+	if len(requests) == 0 {
+		writeError(w, http.StatusBadRequest, errors.New("no mods to scrape"))
+		return
+	}
+	if len(requests) > s.options.MaxBatch {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("too many mods to scrape, the limit is %d", s.options.MaxBatch))
+		return
+	}
+
+	responses := make([]ScrapeResult, len(requests))
+	var tasks []scheduler.Task
+	for i, request := range requests {
+		responses[i] = ScrapeResult{Game: request.Game, ModID: request.ModID}
+		if request.Game == "" || request.ModID <= 0 {
+			responses[i].Error = "game and a positive modId are required"
+			continue
+		}
+		if err := validateGame(strings.ToLower(request.Game)); err != nil {
+			responses[i].Error = err.Error()
+			continue
+		}
+
+		tasks = append(tasks, scheduler.Task{
+			Label: fmt.Sprintf("%s/%d", request.Game, request.ModID),
+			Host:  scheduler.HostOf(s.scraper.BaseURL()),
+			Run: func(ctx context.Context) error {
+				results, err := s.Scrape(ctx, request.Game, request.ModID, false)
+				if err != nil {
+					responses[i].Error = err.Error()
+					return nil
+				}
+				responses[i].Results = &results
+				return nil
+			},
+		})
+	}
+
+	// Failures are reported per mod, so the batch itself never fails
+	scheduler.New(scheduler.Options{Workers: s.options.Workers, PerHost: s.options.PerHost}).Run(r.Context(), tasks...)
+
+	writeJson(w, http.StatusOK, responses)
+}
+
+// Scrape returns the mod from the cache when it has not expired, and otherwise
+// scrapes it, joining a scrape already in progress for the same mod. When refresh
+// is set the cache is skipped but an in-progress scrape is still shared. The scrape
+// stops when ctx is cancelled, and requests that joined it start their own unless
+// they were cancelled too. Mods served from the cache are logged.
+func (s *Server) Scrape(ctx context.Context, game string, modID int64, refresh bool) (types.Results, error) {
+	game = strings.ToLower(game)
+	key := fmt.Sprintf("%s/%d", game, modID)
+
+	s.mu.Lock()
+	if entry, ok := s.cache[key]; ok && !refresh && s.now().Before(entry.expires) {
+		s.mu.Unlock()
+		s.options.Logger.Info("served from cache", "game", game, "mod_id", modID, "cache_hit", true)
+		return entry.results, nil
+	}
+	if c, ok := s.inflight[key]; ok {
+		s.mu.Unlock()
+		select {
+		case <-c.done:
+		case <-ctx.Done():
+			return types.Results{}, ctx.Err()
+		}
+		if c.err != nil && errors.Is(c.err, context.Canceled) && ctx.Err() == nil {
+			return s.Scrape(ctx, game, modID, refresh)
+		}
+		return c.results, c.err
+	}
+
+	c := &call{done: make(chan struct{})}
+	s.inflight[key] = c
+	s.mu.Unlock()
+
+	c.results, c.err = s.scraper.ScrapeMod(ctx, game, modID)
+
+	s.mu.Lock()
+	delete(s.inflight, key)
+	if c.err == nil && s.options.CacheTTL > 0 {
+		s.evictLocked(key)
+		s.cache[key] = cacheEntry{results: c.results, expires: s.now().Add(s.options.CacheTTL)}
+	}
+	s.mu.Unlock()
+	close(c.done)
+
+	return c.results, c.err
+}
+
+// evictLocked makes room in the cache for key, removing the expired mods and then,
+// while the cache is still full, the mod expiring soonest. The caller must hold mu.
+func (s *Server) evictLocked(key string) {
+	if _, ok := s.cache[key]; ok || len(s.cache) < s.options.CacheSize {
+		return
+	}
+
+	now := s.now()
+	for k, entry := range s.cache {
+		if !now.Before(entry.expires) {
+			delete(s.cache, k)
+		}
+	}
+
+	for len(s.cache) >= s.options.CacheSize {
+		oldest := ""
+		for k, entry := range s.cache {
+			if oldest == "" || entry.expires.Before(s.cache[oldest].expires) {
+				oldest = k
+			}
+		}
+		delete(s.cache, oldest)
+	}
+}
+
+// validateGame returns an error unless game is a lower-case game slug.
+func validateGame(game string) error {
+	if !gamePattern.MatchString(game) {
+		return fmt.Errorf("invalid game %q, expected lower-case letters, digits and dashes", game)
+	}
+	return nil
+}
+
+// writeJson writes v as a JSON response with the given status.
+func writeJson(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes err as a JSON error response with the given status.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJson(w, status, map[string]string{"error": err.Error()})
+}
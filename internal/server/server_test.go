@@ -0,0 +1,263 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/pkg/nexus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingFetcher returns a fetch function that counts its calls and waits on
+// release, when set, before returning.
+func countingFetcher(calls *int32, release chan struct{}) nexus.ModFetcher {
+	return func(ctx context.Context, baseUrl, game string, modId int64, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		atomic.AddInt32(calls, 1)
+		if release != nil {
+			<-release
+		}
+		if modId == 404 {
+			return types.Results{}, errors.New("mod not found")
+		}
+		return types.Results{Mods: types.ModInfo{ModID: modId, Name: "Mod " + game}}, nil
+	}
+}
+
+// newScraper creates a scraper using fetch, or one that never scrapes when nil.
+func newScraper(t *testing.T, fetch nexus.ModFetcher) *nexus.Scraper {
+	var opts []nexus.Option
+	if fetch != nil {
+		opts = append(opts, nexus.WithModFetcher(fetch))
+	}
+	scraper, err := nexus.New(opts...)
+	require.NoError(t, err)
+	return scraper
+}
+
+func get(t *testing.T, handler http.Handler, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestHealthz(t *testing.T) {
+	// Arrange
+	handler := New(Options{}, newScraper(t, nil)).Handler()
+
+	// Act
+	rec := get(t, handler, "/healthz")
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+}
+
+func TestGetMod_CachesWithinTTL(t *testing.T) {
+	// Arrange
+	var calls int32
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	srv := New(Options{CacheTTL: time.Minute, Logger: logger}, newScraper(t, countingFetcher(&calls, nil)))
+	now := time.Now()
+	srv.now = func() time.Time { return now }
+	handler := srv.Handler()
+
+	// Act
+	first := get(t, handler, "/games/Skyrim/mods/10")
+	second := get(t, handler, "/games/skyrim/mods/10")
+	now = now.Add(2 * time.Minute)
+	expired := get(t, handler, "/games/skyrim/mods/10")
+	refreshed := get(t, handler, "/games/skyrim/mods/10?refresh=true")
+
+	// Assert
+	for _, rec := range []*httptest.ResponseRecorder{first, second, expired, refreshed} {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+	var results types.Results
+	require.NoError(t, json.Unmarshal(first.Body.Bytes(), &results))
+	assert.Equal(t, "Mod skyrim", results.Mods.Name)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Equal(t, 1, strings.Count(logs.String(), `msg="served from cache" game=skyrim mod_id=10 cache_hit=true`))
+}
+
+func TestGetMod_Errors(t *testing.T) {
+	// Arrange
+	var calls int32
+	handler := New(Options{CacheTTL: time.Minute}, newScraper(t, countingFetcher(&calls, nil))).Handler()
+
+	// Act
+	invalid := get(t, handler, "/games/skyrim/mods/abc")
+	failed := get(t, handler, "/games/skyrim/mods/404")
+	failedAgain := get(t, handler, "/games/skyrim/mods/404")
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, invalid.Code)
+	assert.Equal(t, http.StatusBadGateway, failed.Code)
+	assert.JSONEq(t, `{"error":"mod not found"}`, failed.Body.String())
+	assert.Equal(t, http.StatusBadGateway, failedAgain.Code)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "failures are not cached")
+}
+
+func TestScrape_CoalescesConcurrentRequests(t *testing.T) {
+	// Arrange
+	var calls int32
+	release := make(chan struct{})
+	srv := New(Options{}, newScraper(t, countingFetcher(&calls, release)))
+
+	// Act
+	var wg sync.WaitGroup
+	results := make([]types.Results, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = srv.Scrape(context.Background(), "skyrim", 10, false)
+		}(i)
+	}
+	require.Eventually(t, func() bool {
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		return len(srv.inflight) == 1
+	}, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond) // let the other requests join the scrape
+	close(release)
+	wg.Wait()
+
+	// Assert
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for _, result := range results {
+		assert.Equal(t, int64(10), result.Mods.ModID)
+	}
+}
+
+func TestListMods(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "skyrim"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "skyrim", "first 10.json"), []byte(`{"Mods":{"ModID":10,"Name":"First"}}`), 0644))
+	handler := New(Options{OutputDirectory: dir}, newScraper(t, nil)).Handler()
+
+	// Act
+	saved := get(t, handler, "/games/Skyrim/mods")
+	empty := get(t, handler, "/games/fallout4/mods")
+
+	// Assert
+	assert.Equal(t, http.StatusOK, saved.Code)
+	assert.JSONEq(t, `[{"ModID":10,"Name":"First","LastChecked":"0001-01-01T00:00:00Z"}]`, saved.Body.String())
+	assert.Equal(t, http.StatusOK, empty.Code)
+	assert.JSONEq(t, `[]`, empty.Body.String())
+}
+
+func TestPostScrape(t *testing.T) {
+	// Arrange
+	var calls int32
+	handler := New(Options{MaxBatch: 3}, newScraper(t, countingFetcher(&calls, nil))).Handler()
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/scrape", strings.NewReader(body)))
+		return rec
+	}
+
+	// Act
+	batch := post(`[{"game":"skyrim","modId":10},{"game":"skyrim","modId":404},{"game":"","modId":1}]`)
+	tooMany := post(`[{"game":"a","modId":1},{"game":"a","modId":2},{"game":"a","modId":3},{"game":"a","modId":4}]`)
+	invalid := post(`{`)
+	tooLarge := post(`[{"game":"` + strings.Repeat("a", maxRequestBody) + `","modId":1}]`)
+
+	// Assert
+	require.Equal(t, http.StatusOK, batch.Code)
+	var responses []ScrapeResult
+	require.NoError(t, json.Unmarshal(batch.Body.Bytes(), &responses))
+	require.Len(t, responses, 3)
+	require.NotNil(t, responses[0].Results)
+	assert.Equal(t, int64(10), responses[0].Results.Mods.ModID)
+	assert.Equal(t, "mod not found", responses[1].Error)
+	assert.NotEmpty(t, responses[2].Error)
+	assert.Equal(t, http.StatusBadRequest, tooMany.Code)
+	assert.Equal(t, http.StatusBadRequest, invalid.Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, tooLarge.Code)
+
+	traversal := post(`[{"game":"../skyrim","modId":10}]`)
+	require.Equal(t, http.StatusOK, traversal.Code)
+	require.NoError(t, json.Unmarshal(traversal.Body.Bytes(), &responses))
+	assert.Contains(t, responses[0].Error, "invalid game")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "invalid games are not scraped")
+}
+
+func TestInvalidGame(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	outputDirectory := filepath.Join(dir, "output")
+	require.NoError(t, os.MkdirAll(outputDirectory, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "secret 1.json"), []byte(`{"Mods":{"ModID":1,"Name":"Secret"}}`), 0644))
+	var calls int32
+	handler := New(Options{OutputDirectory: outputDirectory}, newScraper(t, countingFetcher(&calls, nil))).Handler()
+
+	// Act
+	listed := get(t, handler, "/games/..%2F/mods")
+	dot := get(t, handler, "/games/..%2E/mods")
+	scraped := get(t, handler, "/games/sky%20rim/mods/10")
+
+	// Assert
+	for _, rec := range []*httptest.ResponseRecorder{listed, dot, scraped} {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid game")
+		assert.NotContains(t, rec.Body.String(), "Secret")
+	}
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
+
+func TestScrape_EvictsWhenCacheIsFull(t *testing.T) {
+	// Arrange
+	var calls int32
+	srv := New(Options{CacheTTL: time.Minute, CacheSize: 2}, newScraper(t, countingFetcher(&calls, nil)))
+	now := time.Now()
+	srv.now = func() time.Time { return now }
+
+	// Act
+	for _, modID := range []int64{1, 2, 3} {
+		_, err := srv.Scrape(context.Background(), "skyrim", modID, false)
+		require.NoError(t, err)
+		now = now.Add(time.Second)
+	}
+	_, err := srv.Scrape(context.Background(), "skyrim", 3, false)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Len(t, srv.cache, 2)
+	assert.NotContains(t, srv.cache, "skyrim/1", "the mod expiring soonest is evicted")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestScrape_CancelledWithRequest(t *testing.T) {
+	// Arrange
+	blocking := func(ctx context.Context, baseUrl, game string, modId int64, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		<-ctx.Done()
+		return types.Results{}, ctx.Err()
+	}
+	srv := New(Options{}, newScraper(t, blocking))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// Act
+	_, err := srv.Scrape(ctx, "skyrim", 10, false)
+
+	// Assert
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Empty(t, srv.inflight)
+}
@@ -6,17 +6,49 @@ import (
 
 // cli related.
 // CliFlags defines the structure for command-line flags, including options such as
-// the base URL, cookie directory, cookie file, display and save result flags, game name,
-// mod ID, output directory, and valid cookies for the operation.
+// the base URL, session check, cookie directory, cookie file, display and save result
+// flags and their output formats, saved pages to scrape offline, game name, mod ID,
+// change notification sinks and options, output directory, page concurrency and
+// per-host limit, rate limit, proxy, User-Agent, CA certificate and extra headers,
+// cassettes to record or replay requests, overall and per-request timeouts, results
+// store, spreadsheet table options, output template, and valid cookies for the
+// operation.
 type CliFlags struct {
 	BaseUrl         string
+	CACert          string
+	CheckSession    bool
+	Concurrency     int
 	CookieDirectory string
 	CookieFile      string
 	DisplayResults  bool
+	FileColumns     []string
+	FilesHtml       string
+	Format          string
+	FromDir         string
+	FromHtml        string
 	GameName        string
+	Headers         []string
+	JoinSeparator   string
+	ModColumns      []string
 	ModID           int64
+	Notify          []string
+	NotifyDryRun    bool
+	NotifyRetries   int
+	NotifyTemplate  string
 	OutputDirectory string
+	PerHost         int
+	Proxy           string
+	RateLimit       time.Duration
+	Record          string
+	Replay          string
+	RequestTimeout  time.Duration
+	SaveFormat      string
 	SaveResults     bool
+	Store           string
+	TableFormat     string
+	Template        string
+	Timeout         time.Duration
+	UserAgent       string
 	ValidCookies    []string
 }
 
@@ -29,6 +61,13 @@ func NewScraper() *CliFlags {
 
 // nexus mods related.
 
+// Account represents the Nexus Mods account a session is logged in to, including the
+// username and whether the account has a premium membership.
+type Account struct {
+	Premium  bool   `json:"premium"`
+	Username string `json:"username,omitempty"`
+}
+
 // Results defines the structure for storing the scraping results, which includes
 // a ModInfo object under the key "Mods" in the JSON output.
 type Results struct {
@@ -2,13 +2,14 @@ package cli
 
 import (
 	"reflect"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 // RegisterFlag registers a command-line flag for a Cobra command based on the provided
 // name, shorthand, value, usage description, and target variable. It supports bool,
-// string, float64, int, and string slice types, ensuring the target is a pointer.
+// string, float64, int, duration, and string slice types, ensuring the target is a pointer.
 // If the value type is unsupported, the function panics.
 func RegisterFlag(cmd *cobra.Command, name, shorthand string, value interface{}, usage string, target interface{}) {
 	targetValue := reflect.ValueOf(target)
@@ -31,12 +32,18 @@ func RegisterFlag(cmd *cobra.Command, name, shorthand string, value interface{},
 		}
 	case string:
 		usage += "\n"
-	case float64, int, []string:
+	case float64, int, time.Duration, []string:
 		usage += "\n"
 	default:
 		panic("unsupported flag type")
 	}
 
+	// Durations are int64 underneath, so they are matched by type before kind
+	if durationTarget, ok := target.(*time.Duration); ok {
+		cmd.Flags().DurationVarP(durationTarget, name, shorthand, value.(time.Duration), usage)
+		return
+	}
+
 	// Register the flag based on the value type
 	switch elemType {
 	case reflect.Bool:
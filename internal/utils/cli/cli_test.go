@@ -2,6 +2,7 @@ package cli
 
 import (
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
@@ -113,3 +114,19 @@ func TestRegisterFlag_PanicOnUnsupportedType(t *testing.T) {
 		RegisterFlag(cmd, "config", "c", map[string]string{}, "Unsupported type", &unsupportedTarget)
 	})
 }
+
+func TestRegisterFlag_DurationFlag(t *testing.T) {
+	// Arrange
+	var durationTarget time.Duration
+	cmd := &cobra.Command{}
+
+	// Act
+	RegisterFlag(cmd, "timeout", "", 5*time.Minute, "How long to wait", &durationTarget)
+
+	// Assert
+	flag := cmd.Flags().Lookup("timeout")
+	require.NotNil(t, flag)
+	assert.Equal(t, "How long to wait\n", flag.Usage)
+	assert.Equal(t, "5m0s", flag.DefValue)
+	assert.Equal(t, 5*time.Minute, durationTarget)
+}
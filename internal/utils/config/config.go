@@ -0,0 +1,197 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is the prefix of the environment variables setting flags, such as
+// NMS_BASE_URL for --base-url.
+const EnvPrefix = "NMS_"
+
+// Source is where the effective value of a flag comes from.
+type Source string
+
+const (
+	// SourceFlag is a value given on the command line.
+	SourceFlag Source = "flag"
+	// SourceEnv is a value from an NMS_ environment variable.
+	SourceEnv Source = "env"
+	// SourceProfile is a value from the selected account profile.
+	SourceProfile Source = "profile"
+	// SourceConfig is a value from the configuration file.
+	SourceConfig Source = "config"
+	// SourceDefault is the flag's default value.
+	SourceDefault Source = "default"
+)
+
+// Setting is the effective value of a flag and where it comes from.
+type Setting struct {
+	Name   string
+	Value  string
+	Source Source
+}
+
+// Config is the configuration file, holding the named account profiles and the flag
+// values. Top-level values apply to every command with the flag, and a section named
+// after a command, nested for subcommands such as auth: status:, applies to that
+// command and wins over the top level.
+type Config struct {
+	Profiles map[string]Profile `yaml:"profiles,omitempty"`
+	Values   map[string]any     `yaml:",inline"`
+}
+
+// Profile is a named account, with its own cookie file and the base URL, rate limit
+// and output directory used with it. Empty fields keep the command's defaults.
+type Profile struct {
+	BaseUrl         string        `yaml:"base-url,omitempty"`
+	CookieDirectory string        `yaml:"cookie-directory,omitempty"`
+	CookieFile      string        `yaml:"cookie-filename,omitempty"`
+	OutputDirectory string        `yaml:"output-directory,omitempty"`
+	RateLimit       time.Duration `yaml:"rate-limit,omitempty"`
+}
+
+// DefaultPath returns the path of the configuration file in the user's configuration
+// directory, such as ~/.config/nexus-mods-scraper/config.yaml on linux.
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(dir, "nexus-mods-scraper", "config.yaml")
+}
+
+// Load reads the configuration file at path, returning an empty configuration when
+// it does not exist. Returns an error if the file cannot be read or decoded.
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Config{}, nil
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("error decoding config file: %s - %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Save writes the configuration file at path, creating its directory when needed.
+// Returns an error if the file cannot be encoded or written.
+func Save(path string, cfg Config) error {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("error encoding config file: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating config directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing config file: %w", err)
+	}
+	return nil
+}
+
+// Lookup returns the configured value of the flag for the command with the path, such
+// as ["auth", "status"], formatted as it would be given on the command line. The
+// command's section wins over the top level. The boolean is false when the flag is
+// not configured.
+func (c Config) Lookup(path []string, name string) (string, bool) {
+	sections := []map[string]any{c.Values}
+	section := c.Values
+	for _, command := range path {
+		next, ok := section[command].(map[string]any)
+		if !ok {
+			break
+		}
+		sections = append(sections, next)
+		section = next
+	}
+
+	// The most specific section holding the flag wins
+	for i := len(sections) - 1; i >= 0; i-- {
+		value, ok := sections[i][name]
+		if !ok || value == nil {
+			continue
+		}
+		if _, isSection := value.(map[string]any); isSection {
+			continue
+		}
+		return FormatValue(value), true
+	}
+	return "", false
+}
+
+// FormatValue returns a value read from the configuration file as it would be given
+// on the command line, joining lists with commas.
+func FormatValue(value any) string {
+	if list, ok := value.([]any); ok {
+		items := make([]string, 0, len(list))
+		for _, item := range list {
+			items = append(items, fmt.Sprint(item))
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(value)
+}
+
+// EnvName returns the environment variable setting the flag, such as NMS_BASE_URL
+// for base-url.
+func EnvName(flag string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// Profile returns the profile with the name. Returns an error if there is none.
+func (c Config) Profile(name string) (Profile, error) {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("profile %q not found, add it with profile add", name)
+	}
+	return profile, nil
+}
+
+// ProfileNames returns the sorted names of the profiles.
+func (c Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AddProfile adds the profile with the name. Returns an error if the name is empty
+// or a profile with it already exists.
+func (c *Config) AddProfile(name string, profile Profile) error {
+	if name == "" {
+		return errors.New("profile name must not be empty")
+	}
+	if _, ok := c.Profiles[name]; ok {
+		return fmt.Errorf("profile %q already exists, remove it first", name)
+	}
+	if c.Profiles == nil {
+		c.Profiles = map[string]Profile{}
+	}
+	c.Profiles[name] = profile
+	return nil
+}
+
+// RemoveProfile removes the profile with the name. Returns an error if there is none.
+func (c *Config) RemoveProfile(name string) error {
+	if _, ok := c.Profiles[name]; !ok {
+		return fmt.Errorf("profile %q not found", name)
+	}
+	delete(c.Profiles, name)
+	return nil
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_MissingFile(t *testing.T) {
+	// Act
+	cfg, err := Load(filepath.Join(t.TempDir(), "config.yaml"))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Profiles)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("profiles: [oops"), 0644))
+
+	// Act
+	_, err := Load(path)
+
+	// Assert
+	assert.ErrorContains(t, err, "error decoding config file")
+}
+
+func TestSave_RoundTrip(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "nested", "config.yaml")
+	cfg := Config{}
+	require.NoError(t, cfg.AddProfile("team", Profile{BaseUrl: "https://team.example", CookieFile: "team.json", RateLimit: 2 * time.Second}))
+
+	// Act
+	err := Save(path, cfg)
+
+	// Assert
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "profiles:\n    team:\n        base-url: https://team.example\n        cookie-filename: team.json\n        rate-limit: 2s\n", string(data))
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, cfg, loaded)
+}
+
+func TestConfig_Profiles(t *testing.T) {
+	// Arrange
+	cfg := Config{}
+
+	// Act
+	require.NoError(t, cfg.AddProfile("team", Profile{}))
+	require.NoError(t, cfg.AddProfile("personal", Profile{CookieFile: "personal.json"}))
+	duplicateErr := cfg.AddProfile("team", Profile{})
+	emptyErr := cfg.AddProfile("", Profile{})
+
+	// Assert
+	assert.EqualError(t, duplicateErr, `profile "team" already exists, remove it first`)
+	assert.EqualError(t, emptyErr, "profile name must not be empty")
+	assert.Equal(t, []string{"personal", "team"}, cfg.ProfileNames())
+
+	profile, err := cfg.Profile("personal")
+	require.NoError(t, err)
+	assert.Equal(t, "personal.json", profile.CookieFile)
+
+	require.NoError(t, cfg.RemoveProfile("personal"))
+	assert.EqualError(t, cfg.RemoveProfile("personal"), `profile "personal" not found`)
+	_, err = cfg.Profile("personal")
+	assert.EqualError(t, err, `profile "personal" not found, add it with profile add`)
+}
+
+func TestConfig_Lookup(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+base-url: https://top.example
+workers: 2
+valid-cookie-names: [a, b]
+auth:
+  status:
+    base-url: https://auth.example
+profiles:
+  team: {}
+`), 0644))
+	cfg, err := Load(path)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		path     []string
+		name     string
+		expected string
+		found    bool
+	}{
+		"top level":          {path: []string{"scrape"}, name: "base-url", expected: "https://top.example", found: true},
+		"command section":    {path: []string{"auth", "status"}, name: "base-url", expected: "https://auth.example", found: true},
+		"number":             {path: []string{"feed"}, name: "workers", expected: "2", found: true},
+		"list":               {path: []string{"extract"}, name: "valid-cookie-names", expected: "a,b", found: true},
+		"missing":            {path: []string{"scrape"}, name: "format"},
+		"section is no flag": {path: nil, name: "auth"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			value, found := cfg.Lookup(tt.path, tt.name)
+
+			// Assert
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestSave_KeepsValues(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("workers: 2\n"), 0644))
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	require.NoError(t, cfg.AddProfile("team", Profile{}))
+
+	// Act
+	err = Save(path, cfg)
+
+	// Assert
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "profiles:\n    team: {}\nworkers: 2\n", string(data))
+}
+
+func TestEnvName(t *testing.T) {
+	// Act & Assert
+	assert.Equal(t, "NMS_COOKIE_DIRECTORY", EnvName("cookie-directory"))
+}
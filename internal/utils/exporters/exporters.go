@@ -8,25 +8,35 @@ import (
 
 	"github.com/ondrovic/nexus-mods-scraper/internal/types"
 	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
-	"github.com/savioxavier/termlink"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/templates"
 )
 
-// DisplayResults formats and displays the scraped mod results. It takes command-line flags,
-// the results to be displayed, and a formatting function to convert mod information into
-// a JSON string. Returns an error if formatting fails.
-func DisplayResults(sc types.CliFlags, results types.Results, formatResultsFunc func(types.ModInfo) (string, error)) error {
-	jsonResults, err := formatResultsFunc(results.Mods)
+// DisplayResults formats and displays the scraped mod results in the output format
+// selected by the flags, which decides how it is printed. Returns an error if the
+// format is unknown or formatting fails.
+func DisplayResults(sc types.CliFlags, results types.Results) error {
+	format, err := formatters.LookupFormat(sc.Format)
+	if err != nil {
+		return err
+	}
+
+	formattedResults, err := format.Format(results.Mods)
 	if err != nil {
 		return fmt.Errorf("error while attempting to format results: %v", err)
 	}
 
-	formatters.PrintPrettyJson(jsonResults)
-	return nil
+	if format.Print == nil {
+		fmt.Println(formattedResults)
+		return nil
+	}
+
+	return format.Print(formattedResults)
 }
 
-// SaveCookiesToJson saves the provided cookie data as a JSON file in the specified directory.
-// It checks if the directory exists, creates it if necessary, and uses provided functions to
-// open the file and ensure the directory exists. Returns an error if any operation fails.
+// SaveCookiesToJson saves the provided cookie data as a JSON file in the specified directory,
+// readable only by the owner. It checks if the directory exists, creates it if necessary, and
+// uses provided functions to open the file and ensure the directory exists. Returns an error if
+// any operation fails.
 func SaveCookiesToJson(dir string, filename string, data interface{}, openFileFunc func(name string, flag int, perm os.FileMode) (*os.File, error), ensureDirExistsFunc func(string) error) error {
 	// Check if the directory exists, if not create it
 	if err := ensureDirExistsFunc(dir); err != nil {
@@ -36,13 +46,19 @@ func SaveCookiesToJson(dir string, filename string, data interface{}, openFileFu
 	// Join the directory and filename using filepath.Join for cross-platform compatibility
 	fullPath := filepath.Join(dir, filename)
 
-	// Open the file for writing (create if not exists, truncate if it exists)
-	file, err := openFileFunc(fullPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	// Open the file for writing (create if not exists, truncate if it exists), readable
+	// only by the owner as it holds logged-in sessions
+	file, err := openFileFunc(fullPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
+	// Files created before cookies were kept private keep their old mode when truncated
+	if err := file.Chmod(0600); err != nil {
+		return err
+	}
+
 	// Convert the data to a JSON formatted byte slice
 	jsonData, err := json.MarshalIndent(data, "", "    ") // Using 4 spaces for indentation
 	if err != nil {
@@ -54,7 +70,6 @@ func SaveCookiesToJson(dir string, filename string, data interface{}, openFileFu
 	if err != nil {
 		return err
 	}
-	fmt.Printf("Extracted cookies saved to %s\n", termlink.ColorLink(fullPath, fullPath, "green"))
 	return nil
 }
 
@@ -85,3 +100,59 @@ func SaveModInfoToJson(sc types.CliFlags, data interface{}, dir, filename string
 
 	return fullPath, nil
 }
+
+// SaveModInfo saves the provided results in the save format selected by the flags. JSON
+// results are written by SaveModInfoToJson, any other registered format is rendered and
+// written to "<filename>.<extension>" in the specified directory. Returns the full file
+// path or an error if the format is unknown or any operation fails.
+func SaveModInfo(sc types.CliFlags, results types.Results, dir, filename string, ensureDirExistsFunc func(string) error) (string, error) {
+	format, err := formatters.LookupFormat(sc.SaveFormat)
+	if err != nil {
+		return "", err
+	}
+
+	if format.Name == formatters.JsonFormat {
+		return SaveModInfoToJson(sc, results, dir, filename, ensureDirExistsFunc)
+	}
+
+	// Check if the directory exists, if not create it
+	if err := ensureDirExistsFunc(dir); err != nil {
+		return "", err
+	}
+
+	fullPath := filepath.Join(dir, fmt.Sprintf("%s.%s", filename, format.Extension))
+
+	formattedResults, err := format.Format(results.Mods)
+	if err != nil {
+		return "", fmt.Errorf("error formatting data: %s - %v", fullPath, err)
+	}
+
+	if err := os.WriteFile(fullPath, []byte(formattedResults+"\n"), 0644); err != nil {
+		return "", fmt.Errorf("error saving file: %s - %v", fullPath, err)
+	}
+
+	return fullPath, nil
+}
+
+// SaveModInfoToTemplate renders the results through the given template and saves the
+// output as "<filename>.<extension>" in the specified directory, using the extension
+// of the template. Returns the full file path or an error if any operation fails.
+func SaveModInfoToTemplate(results types.Results, tmpl *templates.Template, dir, filename string, ensureDirExistsFunc func(string) error) (string, error) {
+	// Check if the directory exists, if not create it
+	if err := ensureDirExistsFunc(dir); err != nil {
+		return "", err
+	}
+
+	fullPath := filepath.Join(dir, fmt.Sprintf("%s.%s", filename, tmpl.Extension))
+
+	rendered, err := tmpl.Render(results)
+	if err != nil {
+		return "", fmt.Errorf("error formatting data: %s - %v", fullPath, err)
+	}
+
+	if err := os.WriteFile(fullPath, []byte(rendered), 0644); err != nil {
+		return "", fmt.Errorf("error saving file: %s - %v", fullPath, err)
+	}
+
+	return fullPath, nil
+}
@@ -9,8 +9,11 @@ import (
 	"time"
 
 	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/templates"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // Mocking utils.EnsureDirExists and file operations
@@ -18,15 +21,6 @@ type Mocker struct {
 	mock.Mock
 }
 
-func (m *Mocker) FormatResultsAsJson(results types.ModInfo) (string, error) {
-	args := m.Called(results)
-	return args.String(0), args.Error(1)
-}
-
-func (m *Mocker) PrintPrettyJson(jsonData string) {
-	m.Called(jsonData)
-}
-
 func (m *Mocker) EnsureDirExists(dir string) error {
 	args := m.Called(dir)
 	return args.Error(0)
@@ -34,7 +28,6 @@ func (m *Mocker) EnsureDirExists(dir string) error {
 
 func TestDisplayResults_Success(t *testing.T) {
 	// Arrange
-	mockFormatter := new(Mocker)
 	sc := types.CliFlags{}
 	results := types.Results{
 		Mods: types.ModInfo{
@@ -43,81 +36,30 @@ func TestDisplayResults_Success(t *testing.T) {
 			LastUpdated:      "2024-01-01",
 			Description:      "Description1",
 			ShortDescription: "Short description",
-			ChangeLogs: []types.ChangeLog{
-				{Version: "v1.0", Notes: []string{"Initial release"}},
-			},
-			Tags: []string{"Tag1", "Tag2"},
-			Files: []types.File{
-				{
-					Name:        "File1",
-					Version:     "1.0",
-					FileSize:    "10MB",
-					UploadDate:  "2024-01-01",
-					UniqueDLs:   "100",
-					TotalDLs:    "200",
-					Description: "File description",
-				},
-			},
+			ChangeLogs:       []types.ChangeLog{{Version: "v1.0", Notes: []string{"Initial release"}}},
+			Tags:             []string{"Tag1", "Tag2"},
 		},
 	}
 
-	jsonData := `{
-		"Name": "Mod1",
-		"Creator": "Creator1",
-		"LastUpdated": "2024-01-01",
-		"Description": "Description1",
-		"ShortDescription": "Short description",
-		"ChangeLogs": [{"Version": "v1.0", "Notes": ["Initial release"]}],
-		"Tags": ["Tag1", "Tag2"],
-		"Files": [{
-			"Name": "File1",
-			"Version": "1.0",
-			"FileSize": "10MB",
-			"UploadDate": "2024-01-01",
-			"UniqueDLs": "100",
-			"TotalDLs": "200",
-			"Description": "File description"
-		}]
-	}`
-
-	mockFormatter.On("FormatResultsAsJson", results.Mods).Return(jsonData, nil)
-	mockFormatter.On("PrintPrettyJson", jsonData).Return()
-
 	// Act
-	err := DisplayResults(sc, results, mockFormatter.FormatResultsAsJson)
+	err := DisplayResults(sc, results)
 
 	// Assert
 	assert.NoError(t, err)
-
-	// Verify that FormatResultsAsJson was called once
-	mockFormatter.AssertCalled(t, "FormatResultsAsJson", results.Mods)
 }
 
 func TestDisplayResults_FormatError(t *testing.T) {
-	// Arrange: Create a mock formatter and set expectations for the error
-	mockFormatter := new(Mocker)
-	sc := types.CliFlags{}
-	results := types.Results{
-		Mods: types.ModInfo{
-			Name:             "Mod1",
-			LastUpdated:      "2024-01-01",
-			Description:      "Description1",
-			ShortDescription: "Short description",
-		},
-	}
-
-	// Mock FormatResultsAsJson to return an error
-	mockFormatter.On("FormatResultsAsJson", results.Mods).Return("", errors.New("mock formatting error"))
+	// Arrange
+	require.NoError(t, formatters.RegisterFormat(formatters.OutputFormat{
+		Name:   "failing-display",
+		Format: func(types.ModInfo) (string, error) { return "", errors.New("mock formatting error") },
+	}))
 
-	// Act: Call DisplayResults with the mocked formatter
-	err := DisplayResults(sc, results, mockFormatter.FormatResultsAsJson)
+	// Act
+	err := DisplayResults(types.CliFlags{Format: "failing-display"}, types.Results{Mods: types.ModInfo{Name: "Mod1"}})
 
-	// Assert: Verify that an error is returned
-	assert.Error(t, err)
+	// Assert
 	assert.EqualError(t, err, fmt.Sprintf("error while attempting to format results: %v", "mock formatting error"))
-
-	// Verify that FormatResultsAsJson was called once
-	mockFormatter.AssertCalled(t, "FormatResultsAsJson", results.Mods)
 }
 
 func TestSaveCookiesToJson_Success(t *testing.T) {
@@ -156,6 +98,11 @@ func TestSaveCookiesToJson_Success(t *testing.T) {
     "session": "1234"
 }`
 	assert.Equal(t, expectedContent, string(fileContent))
+
+	// The cookie file is readable only by its owner
+	info, err := os.Stat(tempFile.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
 }
 
 func TestSaveModInfoToJson_Success(t *testing.T) {
@@ -219,3 +166,80 @@ func TestSaveModInfoToJson_EnsureDirExistsError(t *testing.T) {
 	assert.Contains(t, err.Error(), "directory error")
 	mockUtils.AssertCalled(t, "EnsureDirExists", dir)
 }
+
+func TestDisplayResults_UnknownFormat(t *testing.T) {
+	// Act
+	err := DisplayResults(types.CliFlags{Format: "xml"}, types.Results{})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown output format "xml"`)
+}
+
+func TestDisplayResults_PlainFormat(t *testing.T) {
+	// Arrange
+	results := types.Results{Mods: types.ModInfo{Name: "Mod1"}}
+
+	// Act
+	err := DisplayResults(types.CliFlags{Format: "yaml"}, results)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestSaveModInfo_Yaml(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	results := types.Results{Mods: types.ModInfo{Name: "Test Mod", ModID: 7}}
+
+	// Act
+	returnedPath, err := SaveModInfo(types.CliFlags{SaveFormat: "yaml"}, results, tempDir, "modinfo", func(string) error { return nil })
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(tempDir, "modinfo.yaml"), returnedPath)
+	fileContent, err := os.ReadFile(returnedPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "LastChecked: \"0001-01-01T00:00:00Z\"\nModID: 7\nName: Test Mod\n", string(fileContent))
+}
+
+func TestSaveModInfo_JsonKeepsResultsWrapper(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	results := types.Results{Mods: types.ModInfo{Name: "Test Mod"}}
+
+	// Act
+	returnedPath, err := SaveModInfo(types.CliFlags{}, results, tempDir, "modinfo", func(string) error { return nil })
+
+	// Assert
+	assert.NoError(t, err)
+	fileContent, err := os.ReadFile(returnedPath)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"Mods":{"LastChecked":"0001-01-01T00:00:00Z","Name":"Test Mod"}}`, string(fileContent))
+}
+
+func TestSaveModInfo_UnknownFormat(t *testing.T) {
+	// Act
+	_, err := SaveModInfo(types.CliFlags{SaveFormat: "xml"}, types.Results{}, "dir", "modinfo", func(string) error { return nil })
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown output format "xml"`)
+}
+
+func TestSaveModInfoToTemplate_Success(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	tmpl, err := templates.Parse("card.html.tmpl", "<b>{{ .Mods.Name }}</b>")
+	assert.NoError(t, err)
+
+	// Act
+	returnedPath, err := SaveModInfoToTemplate(types.Results{Mods: types.ModInfo{Name: "Test Mod"}}, tmpl, tempDir, "modinfo", func(string) error { return nil })
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(tempDir, "modinfo.html"), returnedPath)
+	fileContent, err := os.ReadFile(returnedPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "<b>Test Mod</b>", string(fileContent))
+}
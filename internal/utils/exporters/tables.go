@@ -0,0 +1,302 @@
+package exporters
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+)
+
+const (
+	// CsvTable is the table format for comma separated values.
+	CsvTable string = "csv"
+	// TsvTable is the table format for tab separated values.
+	TsvTable string = "tsv"
+)
+
+// TableOptions controls how mods are flattened into spreadsheet rows, including
+// the table format, which columns appear in the mods and files sheets, and the
+// separator used when joining nested fields such as tags into a single cell.
+type TableOptions struct {
+	Format        string
+	ModColumns    []string
+	FileColumns   []string
+	JoinSeparator string
+}
+
+// modColumn extracts a single cell value for a mod in the given game.
+type modColumn func(game string, mod types.ModInfo, sep string) string
+
+// fileColumn extracts a single cell value for one of a mod's files.
+type fileColumn func(game string, mod types.ModInfo, file types.File) string
+
+// DefaultModColumns is the column order used for the mods sheet when none is configured.
+var DefaultModColumns = []string{
+	"game", "mod_id", "name", "creator", "uploader", "latest_version", "last_updated",
+	"original_upload", "virus_status", "tags", "dependencies", "mods_using", "changelogs",
+	"file_count", "short_description", "url", "last_checked",
+}
+
+// DefaultFileColumns is the column order used for the files sheet when none is configured.
+var DefaultFileColumns = []string{
+	"game", "mod_id", "mod_name", "name", "version", "upload_date", "file_size",
+	"unique_downloads", "total_downloads", "description",
+}
+
+// modColumns maps each supported mods sheet column to its extractor. Nested fields
+// are flattened by joining their entries with the configured separator.
+var modColumns = map[string]modColumn{
+	"game":              func(game string, _ types.ModInfo, _ string) string { return game },
+	"mod_id":            func(_ string, m types.ModInfo, _ string) string { return strconv.FormatInt(m.ModID, 10) },
+	"name":              func(_ string, m types.ModInfo, _ string) string { return m.Name },
+	"creator":           func(_ string, m types.ModInfo, _ string) string { return m.Creator },
+	"uploader":          func(_ string, m types.ModInfo, _ string) string { return m.Uploader },
+	"latest_version":    func(_ string, m types.ModInfo, _ string) string { return m.LatestVersion },
+	"last_updated":      func(_ string, m types.ModInfo, _ string) string { return m.LastUpdated },
+	"original_upload":   func(_ string, m types.ModInfo, _ string) string { return m.OriginalUpload },
+	"virus_status":      func(_ string, m types.ModInfo, _ string) string { return m.VirusStatus },
+	"short_description": func(_ string, m types.ModInfo, _ string) string { return m.ShortDescription },
+	"description":       func(_ string, m types.ModInfo, _ string) string { return m.Description },
+	"url":               func(_ string, m types.ModInfo, _ string) string { return m.Url },
+	"file_count":        func(_ string, m types.ModInfo, _ string) string { return strconv.Itoa(len(m.Files)) },
+	"tags":              func(_ string, m types.ModInfo, sep string) string { return strings.Join(m.Tags, sep) },
+	"dependencies":      func(_ string, m types.ModInfo, sep string) string { return joinRequirements(m.Dependencies, sep) },
+	"mods_using":        func(_ string, m types.ModInfo, sep string) string { return joinRequirements(m.ModsUsing, sep) },
+	"changelogs":        func(_ string, m types.ModInfo, sep string) string { return joinChangeLogs(m.ChangeLogs, sep) },
+	"last_checked": func(_ string, m types.ModInfo, _ string) string {
+		if m.LastChecked.IsZero() {
+			return ""
+		}
+		return m.LastChecked.Format(time.RFC3339)
+	},
+}
+
+// fileColumns maps each supported files sheet column to its extractor. Every row
+// carries the game and mod_id of its parent so the sheet joins back to the mods sheet.
+var fileColumns = map[string]fileColumn{
+	"game":             func(game string, _ types.ModInfo, _ types.File) string { return game },
+	"mod_id":           func(_ string, m types.ModInfo, _ types.File) string { return strconv.FormatInt(m.ModID, 10) },
+	"mod_name":         func(_ string, m types.ModInfo, _ types.File) string { return m.Name },
+	"name":             func(_ string, _ types.ModInfo, f types.File) string { return f.Name },
+	"version":          func(_ string, _ types.ModInfo, f types.File) string { return f.Version },
+	"upload_date":      func(_ string, _ types.ModInfo, f types.File) string { return f.UploadDate },
+	"file_size":        func(_ string, _ types.ModInfo, f types.File) string { return f.FileSize },
+	"unique_downloads": func(_ string, _ types.ModInfo, f types.File) string { return f.UniqueDLs },
+	"total_downloads":  func(_ string, _ types.ModInfo, f types.File) string { return f.TotalDLs },
+	"description":      func(_ string, _ types.ModInfo, f types.File) string { return f.Description },
+}
+
+// ModColumnNames returns the sorted names of every column the mods sheet supports.
+func ModColumnNames() []string {
+	return sortedKeys(modColumns)
+}
+
+// FileColumnNames returns the sorted names of every column the files sheet supports.
+func FileColumnNames() []string {
+	return sortedKeys(fileColumns)
+}
+
+// WriteModsTable writes a header row followed by one row per mod to w, using the
+// configured mod columns. Returns an error if a column is unknown or writing fails.
+func WriteModsTable(w io.Writer, game string, mods []types.ModInfo, opts TableOptions) error {
+	columns := opts.ModColumns
+	if len(columns) == 0 {
+		columns = DefaultModColumns
+	}
+
+	extractors := make([]modColumn, 0, len(columns))
+	for _, name := range columns {
+		extractor, ok := modColumns[name]
+		if !ok {
+			return fmt.Errorf("unknown mod column %q, expected one of: %s", name, strings.Join(ModColumnNames(), ", "))
+		}
+		extractors = append(extractors, extractor)
+	}
+
+	writer, err := newTableWriter(w, opts.Format)
+	if err != nil {
+		return err
+	}
+
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+
+	sep := joinSeparator(opts)
+	for _, mod := range mods {
+		row := make([]string, len(extractors))
+		for i, extractor := range extractors {
+			row[i] = extractor(game, mod, sep)
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteFilesTable writes a header row followed by one row per file of every mod to
+// w, using the configured file columns. Returns an error if a column is unknown or
+// writing fails.
+func WriteFilesTable(w io.Writer, game string, mods []types.ModInfo, opts TableOptions) error {
+	columns := opts.FileColumns
+	if len(columns) == 0 {
+		columns = DefaultFileColumns
+	}
+
+	extractors := make([]fileColumn, 0, len(columns))
+	for _, name := range columns {
+		extractor, ok := fileColumns[name]
+		if !ok {
+			return fmt.Errorf("unknown file column %q, expected one of: %s", name, strings.Join(FileColumnNames(), ", "))
+		}
+		extractors = append(extractors, extractor)
+	}
+
+	writer, err := newTableWriter(w, opts.Format)
+	if err != nil {
+		return err
+	}
+
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+
+	for _, mod := range mods {
+		for _, file := range mod.Files {
+			row := make([]string, len(extractors))
+			for i, extractor := range extractors {
+				row[i] = extractor(game, mod, file)
+			}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// SaveModInfoToTables writes the mods and files sheets for the given mods into dir,
+// named "<filename>-mods.<ext>" and "<filename>-files.<ext>". Returns the paths of
+// both sheets or an error if the directory cannot be created or writing fails.
+func SaveModInfoToTables(game string, mods []types.ModInfo, dir, filename string, opts TableOptions, ensureDirExistsFunc func(string) error) ([]string, error) {
+	if err := ensureDirExistsFunc(dir); err != nil {
+		return nil, err
+	}
+
+	ext, err := TableExtension(opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	sheets := []struct {
+		suffix string
+		write  func(io.Writer, string, []types.ModInfo, TableOptions) error
+	}{
+		{"mods", WriteModsTable},
+		{"files", WriteFilesTable},
+	}
+
+	paths := make([]string, 0, len(sheets))
+	for _, sheet := range sheets {
+		fullPath := filepath.Join(dir, fmt.Sprintf("%s-%s.%s", filename, sheet.suffix, ext))
+
+		file, err := os.Create(fullPath)
+		if err != nil {
+			return nil, fmt.Errorf("error saving file: %s - %v", fullPath, err)
+		}
+
+		if err := sheet.write(file, game, mods, opts); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("error saving file: %s - %v", fullPath, err)
+		}
+
+		if err := file.Close(); err != nil {
+			return nil, fmt.Errorf("error saving file: %s - %v", fullPath, err)
+		}
+
+		paths = append(paths, fullPath)
+	}
+
+	return paths, nil
+}
+
+// newTableWriter returns a csv.Writer configured with the delimiter for the given
+// table format. Returns an error if the format is not csv or tsv.
+func newTableWriter(w io.Writer, format string) (*csv.Writer, error) {
+	ext, err := TableExtension(format)
+	if err != nil {
+		return nil, err
+	}
+
+	writer := csv.NewWriter(w)
+	if ext == TsvTable {
+		writer.Comma = '\t'
+	}
+
+	return writer, nil
+}
+
+// TableExtension returns the file extension for the given table format, or an
+// error if the format is not csv or tsv.
+func TableExtension(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "", CsvTable:
+		return CsvTable, nil
+	case TsvTable:
+		return TsvTable, nil
+	default:
+		return "", fmt.Errorf("unknown table format %q, expected %s or %s", format, CsvTable, TsvTable)
+	}
+}
+
+// joinSeparator returns the configured separator for nested fields, defaulting to "; ".
+func joinSeparator(opts TableOptions) string {
+	if opts.JoinSeparator == "" {
+		return "; "
+	}
+	return opts.JoinSeparator
+}
+
+// joinRequirements flattens requirements into "Name (Notes)" entries joined by sep.
+func joinRequirements(requirements []types.Requirement, sep string) string {
+	parts := make([]string, 0, len(requirements))
+	for _, requirement := range requirements {
+		if requirement.Notes != "" {
+			parts = append(parts, fmt.Sprintf("%s (%s)", requirement.Name, requirement.Notes))
+		} else {
+			parts = append(parts, requirement.Name)
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+// joinChangeLogs flattens changelogs into "Version: note / note" entries joined by sep.
+func joinChangeLogs(changeLogs []types.ChangeLog, sep string) string {
+	parts := make([]string, 0, len(changeLogs))
+	for _, changeLog := range changeLogs {
+		parts = append(parts, fmt.Sprintf("%s: %s", changeLog.Version, strings.Join(changeLog.Notes, " / ")))
+	}
+	return strings.Join(parts, sep)
+}
+
+// sortedKeys returns the keys of a column map in alphabetical order.
+func sortedKeys[T any](columns map[string]T) []string {
+	names := make([]string, 0, len(columns))
+	for name := range columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
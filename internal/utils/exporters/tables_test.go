@@ -0,0 +1,117 @@
+package exporters
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var tableMods = []types.ModInfo{
+	{
+		ModID:         1,
+		Name:          "Mod One",
+		LatestVersion: "1.1",
+		Tags:          []string{"Armour", "Weapons"},
+		Dependencies:  []types.Requirement{{Name: "Base", Notes: "Required"}, {Name: "Extra"}},
+		ChangeLogs:    []types.ChangeLog{{Version: "1.1", Notes: []string{"Fix", "Tweak"}}},
+		Files: []types.File{
+			{Name: "Main", Version: "1.1", FileSize: "10MB"},
+			{Name: "Patch", Version: "1.0", FileSize: "1MB"},
+		},
+	},
+	{
+		ModID: 2,
+		Name:  "Mod, Two",
+		Files: []types.File{{Name: "Main", Version: "2.0"}},
+	},
+}
+
+func TestWriteModsTable_FlattensNestedFields(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	opts := TableOptions{ModColumns: []string{"game", "mod_id", "name", "tags", "dependencies", "changelogs", "file_count"}}
+
+	// Act
+	err := WriteModsTable(&buf, "skyrim", tableMods, opts)
+
+	// Assert
+	assert.NoError(t, err)
+	expected := "game,mod_id,name,tags,dependencies,changelogs,file_count\n" +
+		"skyrim,1,Mod One,Armour; Weapons,Base (Required); Extra,1.1: Fix / Tweak,2\n" +
+		"skyrim,2,\"Mod, Two\",,,,1\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestWriteFilesTable_ChildRowsPerFile(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	opts := TableOptions{Format: TsvTable, FileColumns: []string{"mod_id", "name", "version"}}
+
+	// Act
+	err := WriteFilesTable(&buf, "skyrim", tableMods, opts)
+
+	// Assert
+	assert.NoError(t, err)
+	expected := "mod_id\tname\tversion\n1\tMain\t1.1\n1\tPatch\t1.0\n2\tMain\t2.0\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestWriteModsTable_CustomSeparator(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	opts := TableOptions{ModColumns: []string{"tags"}, JoinSeparator: "|"}
+
+	// Act
+	err := WriteModsTable(&buf, "skyrim", tableMods[:1], opts)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "tags\nArmour|Weapons\n", buf.String())
+}
+
+func TestWriteModsTable_UnknownColumn(t *testing.T) {
+	// Act
+	err := WriteModsTable(&bytes.Buffer{}, "skyrim", tableMods, TableOptions{ModColumns: []string{"nope"}})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown mod column "nope"`)
+}
+
+func TestWriteFilesTable_UnknownFormat(t *testing.T) {
+	// Act
+	err := WriteFilesTable(&bytes.Buffer{}, "skyrim", tableMods, TableOptions{Format: "xlsx"})
+
+	// Assert
+	assert.EqualError(t, err, `unknown table format "xlsx", expected csv or tsv`)
+}
+
+func TestSaveModInfoToTables_Success(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+
+	// Act
+	paths, err := SaveModInfoToTables("skyrim", tableMods, dir, "skyrim", TableOptions{Format: TsvTable}, func(string) error { return nil })
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "skyrim-mods.tsv"), filepath.Join(dir, "skyrim-files.tsv")}, paths)
+
+	files, err := os.ReadFile(paths[1])
+	require.NoError(t, err)
+	assert.Contains(t, string(files), "skyrim\t1\tMod One\tPatch\t1.0")
+}
+
+func TestSaveModInfoToTables_EnsureDirExistsError(t *testing.T) {
+	// Act
+	_, err := SaveModInfoToTables("skyrim", tableMods, "dir", "skyrim", TableOptions{}, func(string) error { return errors.New("directory error") })
+
+	// Assert
+	assert.EqualError(t, err, "directory error")
+}
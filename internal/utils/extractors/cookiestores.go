@@ -0,0 +1,76 @@
+package extractors
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/browserutils/kooky"
+)
+
+// StoreInfo describes a browser cookie store and the valid cookies it holds.
+type StoreInfo struct {
+	Browser        string
+	Profile        string
+	DefaultProfile bool
+	Path           string
+	// Cookies are the names of the valid cookies the store holds for the domain.
+	Cookies []string
+	// Err is the error reading the store, such as it being locked by the browser.
+	Err error
+}
+
+// HasValidCookies reports whether the store holds any of the valid cookies.
+func (s StoreInfo) HasValidCookies() bool {
+	return len(s.Cookies) > 0
+}
+
+// FilterStores wraps a store provider to return only the stores of the browser and
+// profile given, ignoring case, where an empty browser or profile matches any. The
+// stores left out are closed.
+func FilterStores(storeProvider func() []kooky.CookieStore, browser, profile string) func() []kooky.CookieStore {
+	if browser == "" && profile == "" {
+		return storeProvider
+	}
+
+	return func() []kooky.CookieStore {
+		var matching []kooky.CookieStore
+		for _, store := range storeProvider() {
+			if (browser == "" || strings.EqualFold(store.Browser(), browser)) &&
+				(profile == "" || strings.EqualFold(store.Profile(), profile)) {
+				matching = append(matching, store)
+				continue
+			}
+			store.Close()
+		}
+		return matching
+	}
+}
+
+// ListStores describes every store returned by the store provider, listing the valid
+// cookies each holds for the domain, so the right browser and profile can be chosen.
+func ListStores(domain string, validCookies []string, storeProvider func() []kooky.CookieStore) []StoreInfo {
+	var infos []StoreInfo
+	for _, store := range storeProvider() {
+		info := StoreInfo{
+			Browser:        store.Browser(),
+			Profile:        store.Profile(),
+			DefaultProfile: store.IsDefaultProfile(),
+			Path:           store.FilePath(),
+		}
+
+		cookies, err := store.ReadCookies(kooky.Valid, kooky.DomainContains(domain))
+		if err != nil {
+			info.Err = err
+		}
+		for _, cookie := range cookies {
+			if slices.Contains(validCookies, cookie.Name) && !slices.Contains(info.Cookies, cookie.Name) {
+				info.Cookies = append(info.Cookies, cookie.Name)
+			}
+		}
+		slices.Sort(info.Cookies)
+
+		store.Close()
+		infos = append(infos, info)
+	}
+	return infos
+}
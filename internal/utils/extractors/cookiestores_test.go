@@ -0,0 +1,77 @@
+package extractors
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/browserutils/kooky"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newMockStore returns a mock cookie store for the browser and profile holding the cookies.
+func newMockStore(browser, profile string, cookies []*kooky.Cookie, err error) *MockCookieStore {
+	store := new(MockCookieStore)
+	store.On("Browser").Return(browser)
+	store.On("Profile").Return(profile)
+	store.On("IsDefaultProfile").Return(profile == "default")
+	store.On("FilePath").Return("/profiles/" + browser + "/" + profile)
+	store.On("ReadCookies", mock.Anything).Return(cookies, err)
+	store.On("Close").Return(nil)
+	return store
+}
+
+func TestFilterStores(t *testing.T) {
+	// Arrange
+	firefox := newMockStore("firefox", "default", nil, nil)
+	firefoxWork := newMockStore("firefox", "work", nil, nil)
+	chrome := newMockStore("chrome", "Default", nil, nil)
+	provider := func() []kooky.CookieStore { return []kooky.CookieStore{firefox, firefoxWork, chrome} }
+
+	// Act
+	byBrowser := FilterStores(provider, "Firefox", "")()
+	byBoth := FilterStores(provider, "firefox", "WORK")()
+	byProfile := FilterStores(provider, "", "default")()
+
+	// Assert
+	assert.Equal(t, []kooky.CookieStore{firefox, firefoxWork}, byBrowser)
+	assert.Equal(t, []kooky.CookieStore{firefoxWork}, byBoth)
+	assert.Equal(t, []kooky.CookieStore{firefox, chrome}, byProfile)
+	chrome.AssertCalled(t, "Close")
+}
+
+func TestFilterStores_NoFilter(t *testing.T) {
+	// Arrange
+	store := newMockStore("firefox", "default", nil, nil)
+
+	// Act
+	stores := FilterStores(func() []kooky.CookieStore { return []kooky.CookieStore{store} }, "", "")()
+
+	// Assert
+	assert.Equal(t, []kooky.CookieStore{store}, stores)
+	store.AssertNotCalled(t, "Close")
+}
+
+func TestListStores(t *testing.T) {
+	// Arrange
+	session := &kooky.Cookie{Cookie: http.Cookie{Name: "nexusmods_session", Value: "abc"}}
+	tracking := &kooky.Cookie{Cookie: http.Cookie{Name: "tracking", Value: "x"}}
+	provider := func() []kooky.CookieStore {
+		return []kooky.CookieStore{
+			newMockStore("firefox", "default", []*kooky.Cookie{tracking, session, session}, nil),
+			newMockStore("chrome", "Work", nil, errors.New("database is locked")),
+		}
+	}
+
+	// Act
+	stores := ListStores("nexusmods.com", []string{"nexusmods_session", "nexusmods_session_refresh"}, provider)
+
+	// Assert
+	assert.Equal(t, []StoreInfo{
+		{Browser: "firefox", Profile: "default", DefaultProfile: true, Path: "/profiles/firefox/default", Cookies: []string{"nexusmods_session"}},
+		{Browser: "chrome", Profile: "Work", Path: "/profiles/chrome/Work", Err: errors.New("database is locked")},
+	}, stores)
+	assert.True(t, stores[0].HasValidCookies())
+	assert.False(t, stores[1].HasValidCookies())
+}
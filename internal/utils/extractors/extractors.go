@@ -4,6 +4,8 @@ import (
 	"errors"
 
 	"fmt"
+	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/ondrovic/nexus-mods-scraper/internal/types"
@@ -34,13 +36,77 @@ func IsAdultContent(doc *goquery.Document, modId int64) bool {
 	return false
 }
 
+// accountNameSelectors locate the logged-in username in the site header, checked in
+// order so both the current and the older header layouts are supported.
+var accountNameSelectors = []string{
+	"#user-dropdown .username",
+	".user-profile-menu-info h3",
+	"a.username",
+}
+
+// premiumSelectors match the badges shown to accounts with a premium membership.
+var premiumSelectors = []string{
+	"#user-dropdown .premium",
+	".user-profile-menu-info .premium",
+	".premium-badge",
+}
+
+// ExtractAccount parses the site header in a goquery document to find the account
+// the page was loaded for. Returns an Account with an empty username when the page
+// was loaded without a logged-in session.
+func ExtractAccount(doc *goquery.Document) types.Account {
+	var account types.Account
+
+	for _, selector := range accountNameSelectors {
+		if name := extractElementText(doc, selector); name != "" {
+			account.Username = name
+			break
+		}
+	}
+	if account.Username == "" {
+		return account
+	}
+
+	for _, selector := range premiumSelectors {
+		if doc.Find(selector).Length() > 0 {
+			account.Premium = true
+			break
+		}
+	}
+
+	return account
+}
+
 // CookieExtractor extracts valid cookies for a specified domain from available cookie stores.
 // It takes a domain, a list of valid cookie names, and a store provider function that returns
 // cookie stores. Returns a map of cookie names and values, or an error if no cookies are found
 // or if an error occurs while reading the stores.
 func CookieExtractor(domain string, validCookies []string, storeProvider func() []kooky.CookieStore) (map[string]string, error) {
+	extracted, err := BrowserCookies(domain, validCookies, storeProvider)
+	if err != nil {
+		return nil, err
+	}
+
 	// Declare a map to store cookies
 	cookies := make(map[string]string)
+	for _, cookie := range extracted {
+		cookies[cookie.Name] = cookie.Value
+	}
+
+	// Return the map of cookies
+	return cookies, nil
+}
+
+// BrowserCookies extracts valid cookies for a specified domain from available cookie
+// stores, as CookieExtractor does, keeping each cookie's domain, path, expiry and flags.
+// When several stores hold cookies, every cookie is taken from the store holding the
+// newest one, by creation time and then by expiry, so a stale login in another browser
+// does not replace a fresh one and cookies from two accounts are never combined.
+// Returns an error if no cookies are found or no cookie stores are available.
+func BrowserCookies(domain string, validCookies []string, storeProvider func() []kooky.CookieStore) ([]*http.Cookie, error) {
+	// The store holding the newest cookie, and its cookies in the order they were read
+	var chosen []*kooky.Cookie
+	var newest *kooky.Cookie
 
 	// Find all available cookie stores (for all browsers)
 	cookieStores := storeProvider()
@@ -64,28 +130,56 @@ func CookieExtractor(domain string, validCookies []string, storeProvider func()
 			continue
 		}
 
-		// Filter and store valid cookies in the map
+		// Keep the newest valid cookie of each name the store holds
+		var matched []*kooky.Cookie
+		var storeNewest *kooky.Cookie
 		for _, cookie := range storeCookies {
-			for _, valid := range validCookies {
-				if cookie.Name == valid {
-					cookies[cookie.Name] = cookie.Value
-				}
+			if !slices.Contains(validCookies, cookie.Name) {
+				continue
+			}
+			index := slices.IndexFunc(matched, func(kept *kooky.Cookie) bool { return kept.Name == cookie.Name })
+			switch {
+			case index < 0:
+				matched = append(matched, cookie)
+			case newerCookie(cookie, matched[index]):
+				matched[index] = cookie
+			}
+			if storeNewest == nil || newerCookie(cookie, storeNewest) {
+				storeNewest = cookie
 			}
 		}
 
+		if storeNewest != nil && (newest == nil || newerCookie(storeNewest, newest)) {
+			chosen = matched
+			newest = storeNewest
+		}
+
 		// Close the store explicitly after reading its cookies
 		store.Close()
 	}
 
 	// Check if any cookies were found
-	if len(cookies) == 0 {
+	if len(chosen) == 0 {
 		return nil, errors.New("no matching cookies found")
 	}
 
-	// Return the map of cookies
+	cookies := make([]*http.Cookie, 0, len(chosen))
+	for _, found := range chosen {
+		cookie := found.Cookie
+		cookies = append(cookies, &cookie)
+	}
 	return cookies, nil
 }
 
+// newerCookie reports whether cookie is newer than current, having been created
+// later or, when created at the same time, expiring later.
+func newerCookie(cookie, current *kooky.Cookie) bool {
+	if !cookie.Creation.Equal(current.Creation) {
+		return cookie.Creation.After(current.Creation)
+	}
+	return cookie.Expires.After(current.Expires)
+}
+
 // extractChangeLogs parses a goquery document to extract versioned change logs.
 // It looks for specific elements containing version and log notes, and returns
 // a slice of ChangeLog objects with the version and corresponding notes.
@@ -148,7 +242,7 @@ func extractCleanTextExcludingElementText(doc *goquery.Document, selector, elem
 // name, version, upload date, file size, unique downloads, total downloads, and
 // description. Returns a slice of File objects with the extracted details.
 func ExtractFileInfo(doc *goquery.Document) []types.File {
-	fileElements := doc.Find(".file-expander-header")
+	fileElements := doc.Find(fileSelector)
 	files := make([]types.File, 0, fileElements.Length())
 
 	fileElements.Each(func(i int, s *goquery.Selection) {
@@ -167,27 +261,113 @@ func ExtractFileInfo(doc *goquery.Document) []types.File {
 	return files
 }
 
+// Selectors of the mod page fields read by ExtractModInfo.
+const (
+	nameSelector             = "#pagetitle > h1"
+	lastUpdatedSelector      = "#fileinfo > div:nth-child(2) > time"
+	originalUploadSelector   = "#fileinfo > div:nth-child(3) > time"
+	creatorSelector          = "#fileinfo > div:nth-child(4)"
+	uploaderSelector         = "#fileinfo > div:nth-child(5) > a"
+	virusStatusSelector      = "#fileinfo > div:nth-child(6) > div > span"
+	shortDescriptionSelector = "#section > div > div.wrap.flex > div:nth-child(2) > div > div.tabcontent.tabcontent-mod-page > div.container.tab-description > p"
+	descriptionSelector      = "#section > div > div.wrap.flex > div:nth-child(2) > div > div.tabcontent.tabcontent-mod-page > div.container.mod_description_container.condensed"
+	fileSelector             = ".file-expander-header"
+)
+
 // ExtractModInfo parses a goquery document to extract detailed mod information,
 // including name, last updated date, original upload date, creator, changelogs,
 // uploader, virus status, short description, full description, tags, dependencies,
 // and mods requiring this file. Returns a ModInfo object with the extracted details.
 func ExtractModInfo(doc *goquery.Document) types.ModInfo {
 	return types.ModInfo{
-		Name:             extractElementText(doc, "#pagetitle > h1"),
-		LastUpdated:      extractElementText(doc, "#fileinfo > div:nth-child(2) > time"),
-		OriginalUpload:   extractElementText(doc, "#fileinfo > div:nth-child(3) > time"),
-		Creator:          extractCleanTextExcludingElementText(doc, "#fileinfo > div:nth-child(4)", "h3"),
+		Name:             extractElementText(doc, nameSelector),
+		LastUpdated:      extractElementText(doc, lastUpdatedSelector),
+		OriginalUpload:   extractElementText(doc, originalUploadSelector),
+		Creator:          extractCleanTextExcludingElementText(doc, creatorSelector, "h3"),
 		ChangeLogs:       extractChangeLogs(doc),
-		Uploader:         extractElementText(doc, "#fileinfo > div:nth-child(5) > a"),
-		VirusStatus:      extractElementText(doc, "#fileinfo > div:nth-child(6) > div > span"),
-		ShortDescription: extractElementText(doc, "#section > div > div.wrap.flex > div:nth-child(2) > div > div.tabcontent.tabcontent-mod-page > div.container.tab-description > p"),
-		Description:      extractElementText(doc, "#section > div > div.wrap.flex > div:nth-child(2) > div > div.tabcontent.tabcontent-mod-page > div.container.mod_description_container.condensed"),
+		Uploader:         extractElementText(doc, uploaderSelector),
+		VirusStatus:      extractElementText(doc, virusStatusSelector),
+		ShortDescription: extractElementText(doc, shortDescriptionSelector),
+		Description:      extractElementText(doc, descriptionSelector),
 		Tags:             extractTags(doc),
 		Dependencies:     extractRequirements(doc, "Nexus requirements"),
 		ModsUsing:        extractRequirements(doc, "Mods requiring this file"),
 	}
 }
 
+// Reasons given by a Warning.
+const (
+	// SelectorMissed means the selector matched nothing on the page.
+	SelectorMissed = "selector matched nothing"
+	// FieldEmpty means the selector matched but the field has no text.
+	FieldEmpty = "field is empty"
+)
+
+// Warning is a field the extractors could not read from a page, with the selector it
+// is read from and the reason, SelectorMissed or FieldEmpty.
+type Warning struct {
+	Field    string
+	Selector string
+	Reason   string
+}
+
+// CheckModInfo returns a warning for each text field of the mod extracted from the
+// mod page that is empty, telling a selector that no longer matches the page apart
+// from a field the mod leaves blank.
+func CheckModInfo(doc *goquery.Document, mod types.ModInfo) []Warning {
+	fields := []struct {
+		name, selector, value string
+	}{
+		{"name", nameSelector, mod.Name},
+		{"lastUpdated", lastUpdatedSelector, mod.LastUpdated},
+		{"originalUpload", originalUploadSelector, mod.OriginalUpload},
+		{"creator", creatorSelector, mod.Creator},
+		{"uploader", uploaderSelector, mod.Uploader},
+		{"virusStatus", virusStatusSelector, mod.VirusStatus},
+		{"shortDescription", shortDescriptionSelector, mod.ShortDescription},
+		{"description", descriptionSelector, mod.Description},
+	}
+
+	var warnings []Warning
+	for _, field := range fields {
+		if field.value != "" {
+			continue
+		}
+		warnings = append(warnings, Warning{Field: field.name, Selector: field.selector, Reason: emptyReason(doc.Find(field.selector))})
+	}
+	return warnings
+}
+
+// CheckFileInfo returns a warning when no files were extracted from the files tab,
+// and for each file without a name or version.
+func CheckFileInfo(doc *goquery.Document, files []types.File) []Warning {
+	if len(files) == 0 {
+		return []Warning{{Field: "files", Selector: fileSelector, Reason: SelectorMissed}}
+	}
+
+	var warnings []Warning
+	doc.Find(fileSelector).Each(func(i int, s *goquery.Selection) {
+		if i >= len(files) {
+			return
+		}
+		if files[i].Name == "" {
+			warnings = append(warnings, Warning{Field: fmt.Sprintf("files[%d].name", i), Selector: "p", Reason: emptyReason(s.Find("p"))})
+		}
+		if files[i].Version == "" {
+			warnings = append(warnings, Warning{Field: fmt.Sprintf("files[%d].version", i), Selector: ".stat-version .stat", Reason: emptyReason(s.Find(".stat-version .stat"))})
+		}
+	})
+	return warnings
+}
+
+// emptyReason returns why a field read from the selection is empty.
+func emptyReason(selection *goquery.Selection) string {
+	if selection.Length() == 0 {
+		return SelectorMissed
+	}
+	return FieldEmpty
+}
+
 // extractRequirements parses a goquery document to extract a list of requirements
 // from a table with the specified title. It returns a slice of Requirement objects
 // containing the name and notes for each requirement. If the table is not found,
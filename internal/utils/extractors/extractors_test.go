@@ -80,6 +80,39 @@ func TestIsAdultContent(t *testing.T) {
 	assert.True(t, result, "Expected true for adult content")
 }
 
+func TestExtractAccount(t *testing.T) {
+	tests := map[string]struct {
+		html     string
+		expected types.Account
+	}{
+		"premium member": {
+			html:     `<div id="user-dropdown"><span class="username">Dovahkiin</span><span class="premium">Premium</span></div>`,
+			expected: types.Account{Username: "Dovahkiin", Premium: true},
+		},
+		"member with older header": {
+			html:     `<div class="user-profile-menu-info"><h3>Lydia</h3><p>Member</p></div>`,
+			expected: types.Account{Username: "Lydia"},
+		},
+		"logged out": {
+			html:     `<div class="premium-badge"></div><a id="login" href="/login">Log in</a>`,
+			expected: types.Account{},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			doc, _ := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+
+			// Act
+			account := ExtractAccount(doc)
+
+			// Assert
+			assert.Equal(t, tt.expected, account)
+		})
+	}
+}
+
 func TestCookieExtractor_Success(t *testing.T) {
 	// Arrange: Create a mock cookie store
 	mockStore := new(MockCookieStore)
@@ -113,6 +146,79 @@ func TestCookieExtractor_Success(t *testing.T) {
 	mockStore.AssertExpectations(t)
 }
 
+func TestBrowserCookies_KeepsAttributes(t *testing.T) {
+	// Arrange
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+	mockStore := new(MockCookieStore)
+	mockStore.On("ReadCookies", mock.Anything).Return([]*kooky.Cookie{
+		{Cookie: http.Cookie{Name: "session", Value: "1234", Domain: ".example.com", Path: "/", Expires: expires, Secure: true, HttpOnly: true}},
+		{Cookie: http.Cookie{Name: "tracking", Value: "ignored", Domain: ".example.com"}},
+	}, nil)
+	mockStore.On("Close").Return(nil)
+
+	// Act
+	result, err := BrowserCookies("example.com", []string{"session"}, func() []kooky.CookieStore { return []kooky.CookieStore{mockStore} })
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []*http.Cookie{{Name: "session", Value: "1234", Domain: ".example.com", Path: "/", Expires: expires, Secure: true, HttpOnly: true}}, result)
+}
+
+func TestBrowserCookies_PicksNewestCookie(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	stale := &kooky.Cookie{Cookie: http.Cookie{Name: "session", Value: "stale", Expires: now.Add(time.Hour)}, Creation: now.Add(-24 * time.Hour)}
+	fresh := &kooky.Cookie{Cookie: http.Cookie{Name: "session", Value: "fresh", Expires: now.Add(time.Hour)}, Creation: now}
+	longer := &kooky.Cookie{Cookie: http.Cookie{Name: "session", Value: "longer", Expires: now.Add(2 * time.Hour)}, Creation: now}
+	storeWith := func(cookie *kooky.Cookie) kooky.CookieStore {
+		store := new(MockCookieStore)
+		store.On("ReadCookies", mock.Anything).Return([]*kooky.Cookie{cookie}, nil)
+		store.On("Close").Return(nil)
+		return store
+	}
+
+	// Act
+	freshFirst, err := BrowserCookies("example.com", []string{"session"}, func() []kooky.CookieStore {
+		return []kooky.CookieStore{storeWith(fresh), storeWith(stale)}
+	})
+	assert.NoError(t, err)
+	sameCreation, err := BrowserCookies("example.com", []string{"session"}, func() []kooky.CookieStore {
+		return []kooky.CookieStore{storeWith(longer), storeWith(fresh)}
+	})
+	assert.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, "fresh", freshFirst[0].Value)
+	assert.Equal(t, "longer", sameCreation[0].Value)
+}
+
+func TestBrowserCookies_TakesEveryCookieFromFreshestStore(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	storeWith := func(cookies ...*kooky.Cookie) kooky.CookieStore {
+		store := new(MockCookieStore)
+		store.On("ReadCookies", mock.Anything).Return(cookies, nil)
+		store.On("Close").Return(nil)
+		return store
+	}
+	otherAccount := storeWith(
+		&kooky.Cookie{Cookie: http.Cookie{Name: "session", Value: "other"}, Creation: now.Add(-time.Hour)},
+		&kooky.Cookie{Cookie: http.Cookie{Name: "refresh", Value: "other-refresh"}, Creation: now.Add(time.Minute)},
+	)
+	freshLogin := storeWith(
+		&kooky.Cookie{Cookie: http.Cookie{Name: "session", Value: "fresh"}, Creation: now.Add(2 * time.Minute)},
+	)
+
+	// Act
+	result, err := BrowserCookies("example.com", []string{"session", "refresh"}, func() []kooky.CookieStore {
+		return []kooky.CookieStore{otherAccount, freshLogin}
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []*http.Cookie{{Name: "session", Value: "fresh"}}, result)
+}
+
 func TestCookieExtractor_NoCookieStores(t *testing.T) {
 	// Arrange: Mock function that returns no cookie stores
 	mockStoreProvider := func() []kooky.CookieStore {
@@ -351,3 +457,38 @@ func TestExtractTags(t *testing.T) {
 	assert.Len(t, result, 1)
 	assert.Equal(t, "Tag1", result[0])
 }
+
+func TestCheckModInfo(t *testing.T) {
+	// Arrange
+	html := `<div id="pagetitle"><h1>Mod Name</h1></div>
+			<div id="fileinfo">
+				<h2>File information</h2>
+				<div><h3>Last updated</h3><time></time></div>
+			</div>`
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+	mod := ExtractModInfo(doc)
+
+	// Act
+	warnings := CheckModInfo(doc, mod)
+
+	// Assert
+	assert.Len(t, warnings, 7)
+	assert.Equal(t, Warning{Field: "lastUpdated", Selector: lastUpdatedSelector, Reason: FieldEmpty}, warnings[0])
+	assert.Equal(t, Warning{Field: "originalUpload", Selector: originalUploadSelector, Reason: SelectorMissed}, warnings[1])
+}
+
+func TestCheckFileInfo(t *testing.T) {
+	// Arrange
+	html := `<div class="file-expander-header"><p>File1</p><div class="stat-version"><div class="stat">v1.0</div></div></div>
+			<div class="file-expander-header"><p>File2</p></div>`
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+	emptyDoc, _ := goquery.NewDocumentFromReader(strings.NewReader(`<div></div>`))
+
+	// Act
+	warnings := CheckFileInfo(doc, ExtractFileInfo(doc))
+	emptyWarnings := CheckFileInfo(emptyDoc, ExtractFileInfo(emptyDoc))
+
+	// Assert
+	assert.Equal(t, []Warning{{Field: "files[1].version", Selector: ".stat-version .stat", Reason: SelectorMissed}}, warnings)
+	assert.Equal(t, []Warning{{Field: "files", Selector: fileSelector, Reason: SelectorMissed}}, emptyWarnings)
+}
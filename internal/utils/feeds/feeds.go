@@ -0,0 +1,285 @@
+package feeds
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+)
+
+const (
+	// AtomFeed is the Atom 1.0 feed format.
+	AtomFeed string = "atom"
+	// RssFeed is the RSS 2.0 feed format.
+	RssFeed string = "rss"
+)
+
+// Feed is a format independent feed of mod updates.
+type Feed struct {
+	Title   string
+	Link    string
+	ID      string
+	Updated time.Time
+	Items   []Item
+}
+
+// Item is a single feed entry, either a new file version or a changelog entry.
+type Item struct {
+	ID      string
+	Title   string
+	Link    string
+	Summary string
+	Author  string
+	Date    time.Time
+}
+
+// BuildItems creates feed items for every file version and changelog entry of the
+// mods, newest first. Files are dated by their upload date, and changelogs by the
+// upload date of the file with the same version, falling back to the mod's last
+// updated date for the latest version and its last checked time otherwise. Items
+// left without a date, such as those of mods saved without a last checked time, are
+// skipped. The mod page link is built from baseUrl when a mod has no Url.
+func BuildItems(baseUrl, game string, mods []types.ModInfo) []Item {
+	var items []Item
+
+	for _, mod := range mods {
+		link := mod.Url
+		if link == "" {
+			link = fmt.Sprintf("%s/%s/mods/%d", strings.TrimRight(baseUrl, "/"), game, mod.ModID)
+		}
+
+		uploaded := make(map[string]time.Time, len(mod.Files))
+		for _, file := range mod.Files {
+			date, err := formatters.ParseNexusDate(file.UploadDate)
+			if err != nil {
+				date = mod.LastChecked
+			}
+			if date.IsZero() {
+				continue
+			}
+			if existing, ok := uploaded[file.Version]; !ok || date.Before(existing) {
+				uploaded[file.Version] = date
+			}
+
+			items = append(items, Item{
+				ID:      fmt.Sprintf("urn:nexus-mods:%s:%d:file:%s:%s", game, mod.ModID, file.Name, file.Version),
+				Title:   fmt.Sprintf("%s: %s %s", mod.Name, file.Name, file.Version),
+				Link:    link + "?tab=files",
+				Summary: fileSummary(file),
+				Author:  mod.Creator,
+				Date:    date,
+			})
+		}
+
+		for _, changeLog := range mod.ChangeLogs {
+			date, ok := uploaded[changeLog.Version]
+			if !ok {
+				date = mod.LastChecked
+				if changeLog.Version == mod.LatestVersion {
+					if updated, err := formatters.ParseNexusDate(mod.LastUpdated); err == nil {
+						date = updated
+					}
+				}
+			}
+			if date.IsZero() {
+				continue
+			}
+
+			items = append(items, Item{
+				ID:      fmt.Sprintf("urn:nexus-mods:%s:%d:changelog:%s", game, mod.ModID, changeLog.Version),
+				Title:   fmt.Sprintf("%s %s changelog", mod.Name, changeLog.Version),
+				Link:    link + "?tab=logs",
+				Summary: "- " + strings.Join(changeLog.Notes, "\n- "),
+				Author:  mod.Creator,
+				Date:    date,
+			})
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Date.Equal(items[j].Date) {
+			return items[i].ID < items[j].ID
+		}
+		return items[i].Date.After(items[j].Date)
+	})
+
+	return items
+}
+
+// fileSummary describes a file version for its feed item.
+func fileSummary(file types.File) string {
+	parts := []string{fmt.Sprintf("Version %s", file.Version)}
+	if file.FileSize != "" {
+		parts = append(parts, fmt.Sprintf("size %s", file.FileSize))
+	}
+	if file.UploadDate != "" {
+		parts = append(parts, fmt.Sprintf("uploaded %s", file.UploadDate))
+	}
+
+	summary := strings.Join(parts, ", ")
+	if file.Description != "" {
+		summary += "\n\n" + file.Description
+	}
+	return summary
+}
+
+// Write encodes the feed to w in the given format, atom or rss. Returns an error
+// if the format is unknown or encoding fails.
+func Write(w io.Writer, format string, feed Feed) error {
+	switch strings.ToLower(format) {
+	case "", AtomFeed:
+		return WriteAtom(w, feed)
+	case RssFeed:
+		return WriteRss(w, feed)
+	default:
+		return fmt.Errorf("unknown feed format %q, expected %s or %s", format, AtomFeed, RssFeed)
+	}
+}
+
+// atomFeed is the XML representation of an Atom 1.0 feed.
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// atomLink is an Atom link element.
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+// atomEntry is the XML representation of an Atom entry.
+type atomEntry struct {
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Author  *atomAuthor `xml:"author,omitempty"`
+	Summary string      `xml:"summary"`
+}
+
+// atomAuthor is an Atom author element.
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+// WriteAtom encodes the feed as Atom 1.0 to w.
+func WriteAtom(w io.Writer, feed Feed) error {
+	out := atomFeed{
+		Title:   feed.Title,
+		ID:      feed.ID,
+		Updated: feedUpdated(feed).Format(time.RFC3339),
+		Link:    atomLink{Href: feed.Link},
+	}
+
+	for _, item := range feed.Items {
+		entry := atomEntry{
+			Title:   item.Title,
+			ID:      item.ID,
+			Updated: item.Date.UTC().Format(time.RFC3339),
+			Link:    atomLink{Href: item.Link},
+			Summary: item.Summary,
+		}
+		if item.Author != "" {
+			entry.Author = &atomAuthor{Name: item.Author}
+		}
+		out.Entries = append(out.Entries, entry)
+	}
+
+	return encodeXml(w, out)
+}
+
+// rssFeed is the XML representation of an RSS 2.0 feed.
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+// rssChannel is the RSS channel element.
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+// rssItem is the XML representation of an RSS item.
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+// rssGUID is an RSS guid element that is not a permalink.
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// WriteRss encodes the feed as RSS 2.0 to w.
+func WriteRss(w io.Writer, feed Feed) error {
+	out := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         feed.Title,
+			Link:          feed.Link,
+			Description:   feed.Title,
+			LastBuildDate: feedUpdated(feed).Format(time.RFC1123Z),
+		},
+	}
+
+	for _, item := range feed.Items {
+		out.Channel.Items = append(out.Channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Summary,
+			GUID:        rssGUID{Value: item.ID},
+			PubDate:     item.Date.UTC().Format(time.RFC1123Z),
+		})
+	}
+
+	return encodeXml(w, out)
+}
+
+// feedUpdated returns the feed's updated time, defaulting to its newest item.
+func feedUpdated(feed Feed) time.Time {
+	if !feed.Updated.IsZero() {
+		return feed.Updated.UTC()
+	}
+
+	var newest time.Time
+	for _, item := range feed.Items {
+		if item.Date.After(newest) {
+			newest = item.Date
+		}
+	}
+	return newest.UTC()
+}
+
+// encodeXml writes the XML header and the indented encoding of v to w.
+func encodeXml(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("error encoding feed: %w", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,116 @@
+package feeds
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var feedMods = []types.ModInfo{
+	{
+		ModID:         7,
+		Name:          "Better Things",
+		Creator:       "Author",
+		LatestVersion: "1.1",
+		LastUpdated:   "03 Mar 2024, 10:00AM",
+		LastChecked:   time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+		ChangeLogs: []types.ChangeLog{
+			{Version: "1.1", Notes: []string{"Fixed a bug"}},
+			{Version: "1.0", Notes: []string{"Initial release"}},
+			{Version: "0.9", Notes: []string{"Beta"}},
+		},
+		Files: []types.File{
+			{Name: "Main", Version: "1.1", UploadDate: "02 Mar 2024, 9:00AM", FileSize: "10MB"},
+			{Name: "Main", Version: "1.0", UploadDate: "01 Jan 2024, 9:00AM"},
+		},
+	},
+}
+
+func TestBuildItems_DatesAndOrder(t *testing.T) {
+	// Act
+	items := BuildItems("https://nexusmods.com/", "skyrim", feedMods)
+
+	// Assert
+	require.Len(t, items, 5)
+
+	// 0.9 has no file so it falls back to the last checked time, making it newest
+	assert.Equal(t, "Better Things 0.9 changelog", items[0].Title)
+	assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), items[0].Date)
+
+	assert.Equal(t, "urn:nexus-mods:skyrim:7:changelog:1.1", items[1].ID)
+	assert.Equal(t, "urn:nexus-mods:skyrim:7:file:Main:1.1", items[2].ID)
+	assert.Equal(t, time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC), items[1].Date)
+	assert.Equal(t, "https://nexusmods.com/skyrim/mods/7?tab=files", items[2].Link)
+	assert.Contains(t, items[2].Summary, "size 10MB")
+	assert.Equal(t, "- Initial release", items[3].Summary)
+}
+
+func TestBuildItems_SkipsUndated(t *testing.T) {
+	// Arrange
+	mods := []types.ModInfo{{
+		ModID:         8,
+		Name:          "Undated",
+		LatestVersion: "2.0",
+		ChangeLogs:    []types.ChangeLog{{Version: "2.0", Notes: []string{"New"}}},
+		Files: []types.File{
+			{Name: "Main", Version: "2.0", UploadDate: "unknown"},
+			{Name: "Main", Version: "1.0", UploadDate: "01 Jan 2024, 9:00AM"},
+		},
+	}}
+
+	// Act
+	items := BuildItems("https://nexusmods.com", "skyrim", mods)
+
+	// Assert
+	require.Len(t, items, 1)
+	assert.Equal(t, "urn:nexus-mods:skyrim:8:file:Main:1.0", items[0].ID)
+}
+
+func TestWriteAtom(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	feed := Feed{Title: "skyrim mod updates", ID: "urn:test", Link: "https://nexusmods.com/skyrim/mods", Items: BuildItems("https://nexusmods.com", "skyrim", feedMods)}
+
+	// Act
+	err := WriteAtom(&buf, feed)
+
+	// Assert
+	assert.NoError(t, err)
+	var decoded atomFeed
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, "skyrim mod updates", decoded.Title)
+	assert.Equal(t, "2024-04-01T00:00:00Z", decoded.Updated)
+	assert.Len(t, decoded.Entries, 5)
+	assert.Equal(t, "Author", decoded.Entries[0].Author.Name)
+}
+
+func TestWriteRss(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	feed := Feed{Title: "skyrim mod updates", Link: "https://nexusmods.com/skyrim/mods", Items: BuildItems("https://nexusmods.com", "skyrim", feedMods)}
+
+	// Act
+	err := Write(&buf, RssFeed, feed)
+
+	// Assert
+	assert.NoError(t, err)
+	var decoded rssFeed
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, "2.0", decoded.Version)
+	assert.Len(t, decoded.Channel.Items, 5)
+	assert.Equal(t, "Sat, 02 Mar 2024 09:00:00 +0000", decoded.Channel.Items[1].PubDate)
+	assert.False(t, decoded.Channel.Items[0].GUID.IsPermaLink)
+}
+
+func TestWrite_UnknownFormat(t *testing.T) {
+	// Act
+	err := Write(&bytes.Buffer{}, "json", Feed{})
+
+	// Assert
+	assert.EqualError(t, err, `unknown feed format "json", expected atom or rss`)
+}
@@ -0,0 +1,342 @@
+package formatters
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// JsonFormat is the default output format.
+	JsonFormat string = "json"
+	// YamlFormat renders results as YAML.
+	YamlFormat string = "yaml"
+	// TomlFormat renders results as TOML.
+	TomlFormat string = "toml"
+	// NdjsonFormat renders each mod as a single line of JSON.
+	NdjsonFormat string = "ndjson"
+	// MarkdownFormat renders results as a human readable Markdown mod report.
+	MarkdownFormat string = "markdown"
+)
+
+// OutputFormat describes a named output format, including the file extension used
+// when saving, the function that renders a mod, an optional function used to print
+// the rendered output to the terminal, and an optional function that parses a saved
+// file back into a mod. When Print is nil the rendered output is printed as is, and
+// when Parse is nil saved files in the format cannot be loaded.
+type OutputFormat struct {
+	Name      string
+	Extension string
+	Format    func(types.ModInfo) (string, error)
+	Print     func(string) error
+	Parse     func(string) (types.ModInfo, error)
+}
+
+var (
+	// formatsMu guards the format registry.
+	formatsMu sync.RWMutex
+	// formats holds every registered output format keyed by its lower-cased name.
+	formats = map[string]OutputFormat{}
+)
+
+// init registers the built-in output formats.
+func init() {
+	for _, format := range []OutputFormat{
+		{Name: JsonFormat, Extension: "json", Format: FormatResultsAsJson, Print: func(s string) error { return PrintPrettyJson(s) }, Parse: ParseJson},
+		{Name: YamlFormat, Extension: "yaml", Format: FormatResultsAsYaml, Parse: ParseYaml},
+		{Name: TomlFormat, Extension: "toml", Format: FormatResultsAsToml, Parse: ParseToml},
+		{Name: NdjsonFormat, Extension: "ndjson", Format: FormatResultsAsNdjson, Parse: ParseJson},
+		{Name: MarkdownFormat, Extension: "md", Format: FormatResultsAsMarkdown},
+	} {
+		if err := RegisterFormat(format); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterFormat adds an output format to the registry so it can be selected by
+// name for both terminal display and saved files. Returns an error if the format
+// has no name or render function, or if a format with the same name exists.
+func RegisterFormat(format OutputFormat) error {
+	name := strings.ToLower(strings.TrimSpace(format.Name))
+	if name == "" {
+		return fmt.Errorf("output format must have a name")
+	}
+	if format.Format == nil {
+		return fmt.Errorf("output format %q must have a format function", name)
+	}
+	if format.Extension == "" {
+		format.Extension = name
+	}
+	format.Name = name
+
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	if _, exists := formats[name]; exists {
+		return fmt.Errorf("output format %q is already registered", name)
+	}
+	formats[name] = format
+
+	return nil
+}
+
+// LookupFormat returns the registered output format with the given name. An empty
+// name resolves to the JSON format. Returns an error if no such format exists.
+func LookupFormat(name string) (OutputFormat, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = JsonFormat
+	}
+
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	format, ok := formats[name]
+	if !ok {
+		return OutputFormat{}, fmt.Errorf("unknown output format %q, expected one of: %s", name, strings.Join(formatNamesLocked(), ", "))
+	}
+
+	return format, nil
+}
+
+// FormatNames returns the sorted names of every registered output format.
+func FormatNames() []string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	return formatNamesLocked()
+}
+
+// formatNamesLocked returns the sorted format names, the caller must hold formatsMu.
+func formatNamesLocked() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// toGeneric converts a mod into generic maps and slices via its JSON encoding so
+// other encoders use the same field names and omit the same empty fields.
+func toGeneric(mods types.ModInfo) (map[string]interface{}, error) {
+	data, err := json.Marshal(mods)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal mod information: %w", err)
+	}
+
+	var generic map[string]interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal mod information: %w", err)
+	}
+
+	return generic, nil
+}
+
+// fromGeneric converts generic maps and slices decoded from another encoding back
+// into a mod via JSON, the reverse of toGeneric.
+func fromGeneric(generic map[string]interface{}) (types.ModInfo, error) {
+	data, err := json.Marshal(generic)
+	if err != nil {
+		return types.ModInfo{}, fmt.Errorf("failed to unmarshal mod information: %w", err)
+	}
+
+	var mod types.ModInfo
+	if err := json.Unmarshal(data, &mod); err != nil {
+		return types.ModInfo{}, fmt.Errorf("failed to unmarshal mod information: %w", err)
+	}
+
+	return mod, nil
+}
+
+// ParseJson parses saved JSON back into a mod, either results holding the mod under
+// the Mods key, as saved by the scrape command, or a mod on its own.
+func ParseJson(data string) (types.ModInfo, error) {
+	var results types.Results
+	if err := json.Unmarshal([]byte(data), &results); err != nil {
+		return types.ModInfo{}, fmt.Errorf("failed to unmarshal mod information: %w", err)
+	}
+	if results.Mods.ModID != 0 {
+		return results.Mods, nil
+	}
+
+	var mod types.ModInfo
+	if err := json.Unmarshal([]byte(data), &mod); err != nil {
+		return types.ModInfo{}, fmt.Errorf("failed to unmarshal mod information: %w", err)
+	}
+
+	return mod, nil
+}
+
+// ParseYaml parses YAML rendered by FormatResultsAsYaml back into a mod.
+func ParseYaml(data string) (types.ModInfo, error) {
+	var generic map[string]interface{}
+	if err := yaml.Unmarshal([]byte(data), &generic); err != nil {
+		return types.ModInfo{}, fmt.Errorf("failed to unmarshal mod information: %w", err)
+	}
+
+	return fromGeneric(generic)
+}
+
+// ParseToml parses TOML rendered by FormatResultsAsToml back into a mod.
+func ParseToml(data string) (types.ModInfo, error) {
+	var generic map[string]interface{}
+	if err := toml.Unmarshal([]byte(data), &generic); err != nil {
+		return types.ModInfo{}, fmt.Errorf("failed to unmarshal mod information: %w", err)
+	}
+
+	return fromGeneric(generic)
+}
+
+// FormatResultsAsYaml takes a ModInfo object, formats it as YAML using the same
+// field names as the JSON output, and returns the result.
+func FormatResultsAsYaml(mods types.ModInfo) (string, error) {
+	generic, err := toGeneric(mods)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(generic); err != nil {
+		return "", fmt.Errorf("failed to marshal mod information: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("failed to marshal mod information: %w", err)
+	}
+
+	return strings.TrimRight(buf.String(), "\n"), nil
+}
+
+// FormatResultsAsToml takes a ModInfo object, formats it as TOML using the same
+// field names as the JSON output, and returns the result.
+func FormatResultsAsToml(mods types.ModInfo) (string, error) {
+	generic, err := toGeneric(mods)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := toml.Marshal(generic)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal mod information: %w", err)
+	}
+
+	return strings.TrimRight(string(data), "\n"), nil
+}
+
+// FormatResultsAsNdjson takes a ModInfo object and formats it as a single line of
+// JSON, suitable for appending to newline delimited JSON streams.
+func FormatResultsAsNdjson(mods types.ModInfo) (string, error) {
+	data, err := json.Marshal(mods)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal mod information: %w", err)
+	}
+	return string(data), nil
+}
+
+// FormatResultsAsMarkdown takes a ModInfo object and formats it as a Markdown mod
+// report with its details, files, changelogs, requirements and tags.
+func FormatResultsAsMarkdown(mods types.ModInfo) (string, error) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n", EscapeMarkdown(mods.Name))
+	if mods.ShortDescription != "" {
+		fmt.Fprintf(&b, "\n> %s\n", EscapeMarkdown(mods.ShortDescription))
+	}
+
+	b.WriteString("\n| Field | Value |\n| --- | --- |\n")
+	for _, row := range [][2]string{
+		{"Mod ID", fmt.Sprintf("%d", mods.ModID)},
+		{"Latest version", mods.LatestVersion},
+		{"Creator", mods.Creator},
+		{"Uploader", mods.Uploader},
+		{"Last updated", mods.LastUpdated},
+		{"Original upload", mods.OriginalUpload},
+		{"Virus status", mods.VirusStatus},
+		{"Url", mods.Url},
+	} {
+		if row[1] != "" && row[1] != "0" {
+			fmt.Fprintf(&b, "| %s | %s |\n", row[0], markdownCell(row[1]))
+		}
+	}
+
+	if mods.Description != "" {
+		fmt.Fprintf(&b, "\n## Description\n\n%s\n", EscapeMarkdown(mods.Description))
+	}
+
+	if len(mods.Files) > 0 {
+		b.WriteString("\n## Files\n\n| Name | Version | Size | Uploaded | Unique DLs | Total DLs |\n| --- | --- | --- | --- | --- | --- |\n")
+		for _, file := range mods.Files {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+				markdownCell(file.Name), markdownCell(file.Version), markdownCell(file.FileSize),
+				markdownCell(file.UploadDate), markdownCell(file.UniqueDLs), markdownCell(file.TotalDLs))
+		}
+	}
+
+	if len(mods.ChangeLogs) > 0 {
+		b.WriteString("\n## Changelogs\n")
+		for _, changeLog := range mods.ChangeLogs {
+			fmt.Fprintf(&b, "\n### %s\n\n", EscapeMarkdown(changeLog.Version))
+			for _, note := range changeLog.Notes {
+				fmt.Fprintf(&b, "- %s\n", EscapeMarkdown(note))
+			}
+		}
+	}
+
+	for _, section := range []struct {
+		title        string
+		requirements []types.Requirement
+	}{
+		{"Requirements", mods.Dependencies},
+		{"Mods using this file", mods.ModsUsing},
+	} {
+		if len(section.requirements) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", section.title)
+		for _, requirement := range section.requirements {
+			if requirement.Notes != "" {
+				fmt.Fprintf(&b, "- %s: %s\n", EscapeMarkdown(requirement.Name), EscapeMarkdown(requirement.Notes))
+			} else {
+				fmt.Fprintf(&b, "- %s\n", EscapeMarkdown(requirement.Name))
+			}
+		}
+	}
+
+	if len(mods.Tags) > 0 {
+		tags := make([]string, 0, len(mods.Tags))
+		for _, tag := range mods.Tags {
+			tags = append(tags, fmt.Sprintf("`%s`", strings.ReplaceAll(tag, "`", "'")))
+		}
+		fmt.Fprintf(&b, "\n## Tags\n\n%s\n", strings.Join(tags, " "))
+	}
+
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+// markdownReplacer escapes characters that Markdown would otherwise interpret.
+var markdownReplacer = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "#", `\#`, "|", `\|`,
+)
+
+// EscapeMarkdown escapes Markdown control characters in free text.
+func EscapeMarkdown(s string) string {
+	return markdownReplacer.Replace(s)
+}
+
+// markdownCell escapes text for use inside a Markdown table cell, which also
+// cannot contain line breaks.
+func markdownCell(s string) string {
+	return strings.Join(strings.Fields(EscapeMarkdown(s)), " ")
+}
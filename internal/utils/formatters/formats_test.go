@@ -0,0 +1,158 @@
+package formatters
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var formatMod = types.ModInfo{
+	ModID:         42,
+	Name:          "Better *Things*",
+	LatestVersion: "1.2",
+	Creator:       "Creator",
+	Tags:          []string{"Armour", "Weapons"},
+	ChangeLogs:    []types.ChangeLog{{Version: "1.2", Notes: []string{"Fixed | pipes"}}},
+	Dependencies:  []types.Requirement{{Name: "Base Mod", Notes: "Required"}},
+	Files:         []types.File{{Name: "Main File", Version: "1.2", FileSize: "10MB"}},
+}
+
+func TestLookupFormat_DefaultsToJson(t *testing.T) {
+	// Act
+	format, err := LookupFormat("")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, JsonFormat, format.Name)
+	assert.Equal(t, "json", format.Extension)
+	assert.NotNil(t, format.Print)
+}
+
+func TestLookupFormat_Unknown(t *testing.T) {
+	// Act
+	_, err := LookupFormat("xml")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown output format "xml"`)
+}
+
+func TestFormatNames_IncludesBuiltIns(t *testing.T) {
+	// Act
+	names := FormatNames()
+
+	// Assert
+	for _, name := range []string{JsonFormat, YamlFormat, TomlFormat, NdjsonFormat, MarkdownFormat} {
+		assert.Contains(t, names, name)
+	}
+}
+
+func TestRegisterFormat_ThirdParty(t *testing.T) {
+	// Arrange
+	format := OutputFormat{
+		Name:   "Shout",
+		Format: func(m types.ModInfo) (string, error) { return strings.ToUpper(m.Name), nil },
+	}
+
+	// Act
+	err := RegisterFormat(format)
+	require.NoError(t, err)
+	registered, lookupErr := LookupFormat("shout")
+
+	// Assert
+	assert.NoError(t, lookupErr)
+	assert.Equal(t, "shout", registered.Extension)
+	out, err := registered.Format(formatMod)
+	assert.NoError(t, err)
+	assert.Equal(t, "BETTER *THINGS*", out)
+}
+
+func TestRegisterFormat_Invalid(t *testing.T) {
+	// Assert
+	assert.EqualError(t, RegisterFormat(OutputFormat{}), "output format must have a name")
+	assert.EqualError(t, RegisterFormat(OutputFormat{Name: "empty"}), `output format "empty" must have a format function`)
+	assert.EqualError(t, RegisterFormat(OutputFormat{Name: "JSON", Format: func(types.ModInfo) (string, error) { return "", errors.New("x") }}), `output format "json" is already registered`)
+}
+
+func TestFormatResultsAsYaml(t *testing.T) {
+	// Act
+	out, err := FormatResultsAsYaml(formatMod)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Contains(t, out, "ModID: 42")
+	assert.Contains(t, out, "LatestVersion: \"1.2\"")
+	assert.Contains(t, out, "- Armour")
+}
+
+func TestFormatResultsAsToml(t *testing.T) {
+	// Act
+	out, err := FormatResultsAsToml(formatMod)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Contains(t, out, "ModID = 42")
+	assert.Contains(t, out, "[[Files]]")
+	assert.Contains(t, out, "name = 'Main File'")
+}
+
+func TestFormatResultsAsNdjson(t *testing.T) {
+	// Act
+	out, err := FormatResultsAsNdjson(formatMod)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotContains(t, out, "\n")
+	var decoded types.ModInfo
+	require.NoError(t, json.Unmarshal([]byte(out), &decoded))
+	assert.Equal(t, formatMod.Name, decoded.Name)
+}
+
+func TestParse_RoundTrip(t *testing.T) {
+	for _, name := range []string{JsonFormat, YamlFormat, TomlFormat, NdjsonFormat} {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			format, err := LookupFormat(name)
+			require.NoError(t, err)
+			out, err := format.Format(formatMod)
+			require.NoError(t, err)
+
+			// Act
+			parsed, err := format.Parse(out)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, formatMod, parsed)
+		})
+	}
+}
+
+func TestParseJson_Results(t *testing.T) {
+	// Act
+	parsed, err := ParseJson(`{"Mods":{"ModID":42,"Name":"Saved"}}`)
+	_, invalidErr := ParseJson(`{`)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, types.ModInfo{ModID: 42, Name: "Saved"}, parsed)
+	assert.Error(t, invalidErr)
+}
+
+func TestFormatResultsAsMarkdown(t *testing.T) {
+	// Act
+	out, err := FormatResultsAsMarkdown(formatMod)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out, `# Better \*Things\*`))
+	assert.Contains(t, out, "| Mod ID | 42 |")
+	assert.Contains(t, out, "| Main File | 1.2 | 10MB |  |  |  |")
+	assert.Contains(t, out, "### 1.2\n\n- Fixed \\| pipes")
+	assert.Contains(t, out, "- Base Mod: Required")
+	assert.Contains(t, out, "`Armour` `Weapons`")
+}
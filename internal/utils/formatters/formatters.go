@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ondrovic/nexus-mods-scraper/internal/types"
 
@@ -83,6 +84,23 @@ func FormatResultsAsJson(mods types.ModInfo) (string, error) {
 	return string(jsonData), nil
 }
 
+// HumanizeBytes formats a byte count using binary units, for example 1536 becomes
+// "1.5 KB". Counts below one kilobyte are returned in bytes.
+func HumanizeBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
 // PrintJson prints a given JSON-formatted string to the standard output.
 func PrintJson(data string) {
 	fmt.Println(data)
@@ -116,6 +134,58 @@ func PrintPrettyJson(data string, useAltColors ...bool) error {
 	return nil
 }
 
+// fileSizePattern matches a file size such as "12.5MB", "512 kB" or "2048".
+var fileSizePattern = regexp.MustCompile(`^([0-9][0-9,]*(?:\.[0-9]+)?)\s*([KMGT]?I?B?)$`)
+
+// ParseFileSize converts a file size as shown on Nexus Mods, such as "12.5MB" or
+// "512KB", into a number of bytes using binary units. A bare number is treated as
+// bytes. Returns an error if the size cannot be parsed.
+func ParseFileSize(size string) (int64, error) {
+	matches := fileSizePattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(size)))
+	if matches == nil {
+		return 0, fmt.Errorf("invalid file size %q", size)
+	}
+
+	value, err := strconv.ParseFloat(strings.ReplaceAll(matches[1], ",", ""), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid file size %q: %w", size, err)
+	}
+
+	multiplier := float64(1)
+	if unit := strings.TrimSuffix(strings.TrimSuffix(matches[2], "B"), "I"); unit != "" {
+		multiplier = float64(int64(1) << (10 * (strings.Index("KMGT", unit) + 1)))
+	}
+
+	return int64(value * multiplier), nil
+}
+
+// nexusDateLayouts lists the date layouts used across Nexus Mods pages and the
+// saved results, tried in order by ParseNexusDate.
+var nexusDateLayouts = []string{
+	"02 Jan 2006, 3:04PM",
+	"2 Jan 2006, 3:04PM",
+	"02 Jan 2006 3:04PM",
+	"02 January 2006, 3:04PM",
+	"02 Jan 2006",
+	"2 Jan 2006",
+	"2006-01-02",
+	time.RFC3339,
+}
+
+// ParseNexusDate parses a date as shown on Nexus Mods, for example
+// "23 Oct 2024, 2:18PM", as well as ISO dates. Returns an error if none of the
+// known layouts match.
+func ParseNexusDate(value string) (time.Time, error) {
+	value = strings.Join(strings.Fields(value), " ")
+	for _, layout := range nexusDateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognised date %q", value)
+}
+
 // RemoveHTTPPrefix removes the http or https prefix from a given URL and returns
 // the modified string.
 func RemoveHTTPPrefix(url string) string {
@@ -130,6 +130,82 @@ func TestFormatResultsAsJson(t *testing.T) {
 }
 
 // Test for PrintJson
+// Test for HumanizeBytes
+func TestHumanizeBytes(t *testing.T) {
+	tests := []struct {
+		input    int64
+		expected string
+	}{
+		{512, "512 B"},
+		{1536, "1.5 KB"},
+		{10 * 1024 * 1024, "10.0 MB"},
+		{3 * 1024 * 1024 * 1024, "3.0 GB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			result := HumanizeBytes(tt.input)
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+// Test for ParseFileSize
+func TestParseFileSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+		wantErr  bool
+	}{
+		{"2048", 2048, false},
+		{"1.5KB", 1536, false},
+		{"10 MB", 10 * 1024 * 1024, false},
+		{"1GiB", 1024 * 1024 * 1024, false},
+		{"1,024kb", 1024 * 1024, false},
+		{"huge", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseFileSize(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+// Test for ParseNexusDate
+func TestParseNexusDate(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Time
+		wantErr  bool
+	}{
+		{"23 Oct 2024, 2:18PM", time.Date(2024, 10, 23, 14, 18, 0, 0, time.UTC), false},
+		{" 5 Jan  2023, 9:01AM ", time.Date(2023, 1, 5, 9, 1, 0, 0, time.UTC), false},
+		{"2024-02-29", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), false},
+		{"yesterday", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseNexusDate(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if !result.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
 func TestPrintJson(t *testing.T) {
 	data := `{
 		"Name": "Test Mod",
@@ -0,0 +1,164 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	// TextFormat writes each record as key=value pairs.
+	TextFormat = "text"
+	// JsonFormat writes each record as a JSON object.
+	JsonFormat = "json"
+)
+
+// Redacted replaces the values of cookies and credentials in log records.
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are the attribute keys whose values are always redacted.
+var sensitiveKeys = map[string]bool{
+	"authorization": true,
+	"cookie":        true,
+	"cookies":       true,
+	"passphrase":    true,
+	"password":      true,
+	"set-cookie":    true,
+	"token":         true,
+}
+
+// sensitiveHeaders are the headers whose values are redacted when a header is logged.
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// Options configures the logger built by New.
+type Options struct {
+	// Verbosity raises the level from warnings to info at 1 and debug at 2 or more.
+	Verbosity int
+	// Quiet lowers the level to errors only, taking precedence over Verbosity.
+	Quiet bool
+	// Format is TextFormat or JsonFormat, TextFormat when empty.
+	Format string
+	// File is the file records are appended to instead of the writer given to New.
+	File string
+}
+
+// Level returns the level logged at for the verbosity: warnings by default, info at
+// 1, debug at 2 or more, and only errors when quiet.
+func Level(verbosity int, quiet bool) slog.Level {
+	switch {
+	case quiet:
+		return slog.LevelError
+	case verbosity >= 2:
+		return slog.LevelDebug
+	case verbosity == 1:
+		return slog.LevelInfo
+	default:
+		return slog.LevelWarn
+	}
+}
+
+// New creates a logger writing to the log file when one is given, or to w otherwise,
+// with cookies and credentials redacted. The returned closer closes the log file.
+// Returns an error if the format is unknown or the log file cannot be opened.
+func New(opts Options, w io.Writer) (*slog.Logger, io.Closer, error) {
+	var closer io.Closer = nopCloser{}
+	if opts.File != "" {
+		file, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error opening log file: %w", err)
+		}
+		w, closer = file, file
+	}
+
+	handlerOptions := &slog.HandlerOptions{
+		Level:       Level(opts.Verbosity, opts.Quiet),
+		ReplaceAttr: Redact,
+	}
+
+	var handler slog.Handler
+	switch strings.ToLower(opts.Format) {
+	case "", TextFormat:
+		handler = slog.NewTextHandler(w, handlerOptions)
+	case JsonFormat:
+		handler = slog.NewJSONHandler(w, handlerOptions)
+	default:
+		closer.Close()
+		return nil, nil, fmt.Errorf("unsupported log format %q, use %s or %s", opts.Format, TextFormat, JsonFormat)
+	}
+
+	return slog.New(handler), closer, nil
+}
+
+// Discard returns a logger that drops every record.
+func Discard() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// Redact replaces the values of cookies and credentials in an attribute: attributes
+// named like a cookie or credential, cookies, and the cookie and authorization
+// headers of a logged header. It is used as the handler's ReplaceAttr.
+func Redact(groups []string, attr slog.Attr) slog.Attr {
+	if sensitiveKeys[strings.ToLower(attr.Key)] {
+		return slog.String(attr.Key, Redacted)
+	}
+	if attr.Value.Kind() != slog.KindAny {
+		return attr
+	}
+
+	switch value := attr.Value.Any().(type) {
+	case *http.Cookie:
+		return slog.String(attr.Key, redactCookie(value))
+	case []*http.Cookie:
+		redacted := make([]string, 0, len(value))
+		for _, cookie := range value {
+			redacted = append(redacted, redactCookie(cookie))
+		}
+		return slog.Any(attr.Key, redacted)
+	case http.Header:
+		redacted := value.Clone()
+		for _, name := range sensitiveHeaders {
+			if len(redacted.Values(name)) > 0 {
+				redacted.Set(name, Redacted)
+			}
+		}
+		return slog.Any(attr.Key, redacted)
+	}
+	return attr
+}
+
+// redactCookie returns the cookie's name with its value redacted.
+func redactCookie(cookie *http.Cookie) string {
+	if cookie == nil {
+		return ""
+	}
+	return cookie.Name + "=" + Redacted
+}
+
+// contextKey is the key the logger is stored under in a context.
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the logger.
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or one that drops every record when
+// ctx carries none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return Discard()
+}
+
+// nopCloser is the closer returned when there is no log file to close.
+type nopCloser struct{}
+
+// Close does nothing.
+func (nopCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,133 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevel(t *testing.T) {
+	tests := map[string]struct {
+		verbosity int
+		quiet     bool
+		expected  slog.Level
+	}{
+		"default":           {expected: slog.LevelWarn},
+		"verbose":           {verbosity: 1, expected: slog.LevelInfo},
+		"very verbose":      {verbosity: 2, expected: slog.LevelDebug},
+		"more than -vv":     {verbosity: 3, expected: slog.LevelDebug},
+		"quiet":             {quiet: true, expected: slog.LevelError},
+		"quiet wins over v": {verbosity: 2, quiet: true, expected: slog.LevelError},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Act & Assert
+			assert.Equal(t, tt.expected, Level(tt.verbosity, tt.quiet))
+		})
+	}
+}
+
+func TestNew_JsonFormat(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+	logger, closer, err := New(Options{Verbosity: 1, Format: JsonFormat}, &out)
+	require.NoError(t, err)
+	defer closer.Close()
+
+	// Act
+	logger.Debug("hidden")
+	logger.Info("request", "url", "https://nexusmods.com", "status", 200)
+
+	// Assert
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+	assert.Equal(t, "request", record["msg"])
+	assert.Equal(t, "https://nexusmods.com", record["url"])
+	assert.Equal(t, float64(200), record["status"])
+}
+
+func TestNew_LogFile(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "scraper.log")
+	require.NoError(t, os.WriteFile(path, []byte("earlier run\n"), 0600))
+	var out bytes.Buffer
+
+	// Act
+	logger, closer, err := New(Options{File: path}, &out)
+	require.NoError(t, err)
+	logger.Warn("extraction warning", "field", "name")
+	require.NoError(t, closer.Close())
+
+	// Assert
+	assert.Empty(t, out.String())
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "earlier run\n")
+	assert.Contains(t, string(data), `level=WARN msg="extraction warning" field=name`)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestNew_Invalid(t *testing.T) {
+	// Act
+	_, _, formatErr := New(Options{Format: "xml"}, &bytes.Buffer{})
+	_, _, fileErr := New(Options{File: filepath.Join(t.TempDir(), "missing", "scraper.log")}, &bytes.Buffer{})
+
+	// Assert
+	assert.EqualError(t, formatErr, `unsupported log format "xml", use text or json`)
+	assert.ErrorContains(t, fileErr, "error opening log file")
+}
+
+func TestRedact(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+	logger, _, err := New(Options{Format: JsonFormat}, &out)
+	require.NoError(t, err)
+	session := &http.Cookie{Name: "nexusmods_session", Value: "secret-session"}
+	refresh := &http.Cookie{Name: "nexusmods_session_refresh", Value: "secret-refresh"}
+
+	// Act
+	logger.Warn("redacted",
+		"Cookie", "nexusmods_session=secret-session",
+		"session", session,
+		"cookies", []*http.Cookie{session, refresh},
+		"header", http.Header{"Cookie": {"a=secret-header"}, "Accept": {"text/html"}},
+		"jar", []*http.Cookie{session, refresh},
+		"url", "https://nexusmods.com",
+	)
+
+	// Assert
+	assert.NotContains(t, out.String(), "secret")
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+	assert.Equal(t, Redacted, record["Cookie"])
+	assert.Equal(t, "nexusmods_session="+Redacted, record["session"])
+	assert.Equal(t, Redacted, record["cookies"])
+	assert.Equal(t, []any{"nexusmods_session=" + Redacted, "nexusmods_session_refresh=" + Redacted}, record["jar"])
+	assert.Equal(t, map[string]any{"Cookie": []any{Redacted}, "Accept": []any{"text/html"}}, record["header"])
+	assert.Equal(t, "https://nexusmods.com", record["url"])
+}
+
+func TestContext(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&out, nil))
+
+	// Act
+	FromContext(context.Background()).Warn("dropped")
+	FromContext(NewContext(context.Background(), logger)).Warn("kept")
+
+	// Assert
+	assert.NotContains(t, out.String(), "dropped")
+	assert.Contains(t, out.String(), "msg=kept")
+}
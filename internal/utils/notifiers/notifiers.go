@@ -0,0 +1,328 @@
+package notifiers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/templates"
+)
+
+const (
+	// WebhookSink posts the event and message as JSON to a URL.
+	WebhookSink string = "webhook"
+	// DiscordSink posts the message to a Discord incoming webhook.
+	DiscordSink string = "discord"
+	// SlackSink posts the message to a Slack compatible incoming webhook.
+	SlackSink string = "slack"
+	// CommandSink runs a local command with the event as JSON on stdin.
+	CommandSink string = "command"
+)
+
+// DefaultMessageTemplate is the message sent when no template is configured.
+const DefaultMessageTemplate = `{{ .Name }} ({{ .Game }} #{{ .ModID }}) {{ if .VersionChanged }}updated from {{ default "unknown" .PreviousVersion }} to {{ .LatestVersion }}{{ else }}has new changelog entries{{ end }}
+{{- range .NewChangeLogs }}
+{{ .Version }}: {{ join .Notes "; " }}
+{{- end }}
+{{ .Url }}`
+
+// Event describes a change detected when a mod is scraped again, either a new
+// latest version, new changelog entries, or both.
+type Event struct {
+	Game            string            `json:"game"`
+	ModID           int64             `json:"modId"`
+	Name            string            `json:"name"`
+	Url             string            `json:"url"`
+	PreviousVersion string            `json:"previousVersion"`
+	LatestVersion   string            `json:"latestVersion"`
+	VersionChanged  bool              `json:"versionChanged"`
+	NewChangeLogs   []types.ChangeLog `json:"newChangeLogs,omitempty"`
+	DetectedAt      time.Time         `json:"detectedAt"`
+}
+
+// DetectChanges compares a freshly scraped mod against its previous snapshot and
+// returns an event when the latest version changed or changelog versions appeared
+// that were not in the snapshot. The boolean is false when nothing changed.
+func DetectChanges(game, url string, previous, current types.ModInfo) (Event, bool) {
+	known := make(map[string]bool, len(previous.ChangeLogs))
+	for _, changeLog := range previous.ChangeLogs {
+		known[changeLog.Version] = true
+	}
+
+	var newChangeLogs []types.ChangeLog
+	for _, changeLog := range current.ChangeLogs {
+		if !known[changeLog.Version] {
+			newChangeLogs = append(newChangeLogs, changeLog)
+		}
+	}
+
+	versionChanged := current.LatestVersion != "" && current.LatestVersion != previous.LatestVersion
+	if !versionChanged && len(newChangeLogs) == 0 {
+		return Event{}, false
+	}
+
+	detectedAt := current.LastChecked
+	if detectedAt.IsZero() {
+		detectedAt = time.Now()
+	}
+
+	return Event{
+		Game:            game,
+		ModID:           current.ModID,
+		Name:            current.Name,
+		Url:             url,
+		PreviousVersion: previous.LatestVersion,
+		LatestVersion:   current.LatestVersion,
+		VersionChanged:  versionChanged,
+		NewChangeLogs:   newChangeLogs,
+		DetectedAt:      detectedAt,
+	}, true
+}
+
+// Sink delivers a rendered notification message for an event.
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, event Event, message string) error
+}
+
+// HTTPClient is the subset of http.Client used by the webhook sinks.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// ParseSink creates a sink from a "<kind>=<target>" specification, where the target
+// is a URL for the webhook, discord and slack sinks, or a shell command for the
+// command sink. Returns an error if the kind is unknown or the target is missing.
+func ParseSink(spec string, client HTTPClient) (Sink, error) {
+	kind, target, _ := strings.Cut(spec, "=")
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	target = strings.TrimSpace(target)
+
+	if target == "" {
+		return nil, fmt.Errorf("notification sink %q requires a target, e.g. %s=https://example.com/hook", spec, WebhookSink)
+	}
+
+	switch kind {
+	case WebhookSink, DiscordSink, SlackSink:
+		return &HTTPSink{Kind: kind, URL: target, Client: client}, nil
+	case CommandSink:
+		return &ExecSink{Command: target}, nil
+	default:
+		return nil, fmt.Errorf("unknown notification sink %q, expected one of: %s, %s, %s, %s", kind, WebhookSink, DiscordSink, SlackSink, CommandSink)
+	}
+}
+
+// HTTPSink posts notifications to an incoming webhook. The generic webhook kind
+// receives the event and message, Discord receives {"content": ...} and Slack
+// receives {"text": ...}.
+type HTTPSink struct {
+	Kind   string
+	URL    string
+	Client HTTPClient
+}
+
+// Name returns the sink kind and the host of its URL. The rest of the URL is left
+// out, as Discord and Slack webhook URLs hold their secret token in the path.
+func (s *HTTPSink) Name() string {
+	u, err := url.Parse(s.URL)
+	if err != nil || u.Host == "" {
+		return s.Kind
+	}
+	return fmt.Sprintf("%s %s", s.Kind, u.Host)
+}
+
+// Payload returns the JSON body posted for the event and message.
+func (s *HTTPSink) Payload(event Event, message string) ([]byte, error) {
+	switch s.Kind {
+	case DiscordSink:
+		return json.Marshal(map[string]string{"content": message})
+	case SlackSink:
+		return json.Marshal(map[string]string{"text": message})
+	default:
+		return json.Marshal(struct {
+			Event   Event  `json:"event"`
+			Message string `json:"message"`
+		}{event, message})
+	}
+}
+
+// Send posts the payload to the webhook URL. Returns an error if the request fails
+// or the server does not respond with a 2xx status, naming the sink without the full
+// URL.
+func (s *HTTPSink) Send(ctx context.Context, event Event, message string) error {
+	body, err := s.Payload(event, message)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return s.redact(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return s.redact(err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s returned %d", s.Name(), resp.StatusCode)
+	}
+
+	return nil
+}
+
+// redact replaces the URL that request errors quote with the sink's name, so the
+// webhook token does not appear in errors and logs.
+func (s *HTTPSink) redact(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("%s %s: %w", urlErr.Op, s.Name(), urlErr.Err)
+	}
+	return err
+}
+
+// ExecSink runs a local command through the shell for each notification. The event
+// is written to its stdin as JSON and the message is available in NMS_MESSAGE.
+type ExecSink struct {
+	Command string
+}
+
+// Name returns the sink kind and its command.
+func (s *ExecSink) Name() string {
+	return fmt.Sprintf("%s %s", CommandSink, s.Command)
+}
+
+// Send runs the command. Returns an error including the command output if it fails.
+func (s *ExecSink) Send(ctx context.Context, event Event, message string) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", s.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", s.Command)
+	}
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(), "NMS_MESSAGE="+message)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %w: %s", s.Name(), err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// Notifier renders a message for each event and delivers it to every sink, retrying
+// failed deliveries. In dry-run mode messages are written to Out instead of sent.
+type Notifier struct {
+	Sinks    []Sink
+	Template *template.Template
+	Retries  int
+	Backoff  time.Duration
+	DryRun   bool
+	Out      io.Writer
+}
+
+// ParseMessageTemplate parses a notification message template, using the default
+// message when source is empty. Templates have the same helpers as output templates.
+func ParseMessageTemplate(source string) (*template.Template, error) {
+	if source == "" {
+		source = DefaultMessageTemplate
+	}
+
+	tmpl, err := template.New("notification").Funcs(templates.FuncMap()).Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing notification template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// Notify renders the message for the event and sends it to every sink. Every sink
+// is attempted, and the returned error lists each sink that failed after retries.
+func (n *Notifier) Notify(ctx context.Context, event Event) error {
+	tmpl := n.Template
+	if tmpl == nil {
+		var err error
+		if tmpl, err = ParseMessageTemplate(""); err != nil {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, event); err != nil {
+		return fmt.Errorf("error rendering notification: %w", err)
+	}
+	message := buf.String()
+
+	var failures []string
+	for _, sink := range n.Sinks {
+		if n.DryRun {
+			if n.Out != nil {
+				fmt.Fprintf(n.Out, "[dry-run] %s:\n%s\n", sink.Name(), message)
+			}
+			continue
+		}
+
+		if err := n.send(ctx, sink, event, message); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to send notifications: %s", strings.Join(failures, "; "))
+	}
+
+	return nil
+}
+
+// send delivers to one sink, retrying up to Retries more times with a backoff that
+// doubles after every failed attempt. A negative Retries makes a single attempt.
+func (n *Notifier) send(ctx context.Context, sink Sink, event Event, message string) error {
+	retries := max(n.Retries, 0)
+	backoff := n.Backoff
+	var err error
+
+	for attempt := 0; attempt <= retries; attempt++ {
+		if err = sink.Send(ctx, event, message); err == nil {
+			return nil
+		}
+
+		if attempt == retries {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	return fmt.Errorf("%s: %w (after %d attempts)", sink.Name(), err, retries+1)
+}
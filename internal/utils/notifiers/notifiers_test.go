@@ -0,0 +1,370 @@
+package notifiers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent() Event {
+	return Event{
+		Game:            "skyrim",
+		ModID:           1234,
+		Name:            "Test Mod",
+		Url:             "https://nexusmods.com/skyrim/mods/1234",
+		PreviousVersion: "1.0",
+		LatestVersion:   "1.1",
+		VersionChanged:  true,
+		NewChangeLogs:   []types.ChangeLog{{Version: "1.1", Notes: []string{"Fixed things", "Added things"}}},
+	}
+}
+
+type fakeSink struct {
+	failures int
+	calls    int
+	messages []string
+}
+
+func (s *fakeSink) Name() string { return "fake" }
+
+func (s *fakeSink) Send(_ context.Context, _ Event, message string) error {
+	s.calls++
+	if s.calls <= s.failures {
+		return errors.New("unavailable")
+	}
+	s.messages = append(s.messages, message)
+	return nil
+}
+
+func TestDetectChanges(t *testing.T) {
+	previous := types.ModInfo{
+		ModID:         1234,
+		LatestVersion: "1.0",
+		ChangeLogs:    []types.ChangeLog{{Version: "1.0", Notes: []string{"Initial release"}}},
+	}
+
+	tests := []struct {
+		name           string
+		current        types.ModInfo
+		changed        bool
+		versionChanged bool
+		newChangeLogs  int
+	}{
+		{
+			name:    "unchanged",
+			current: previous,
+		},
+		{
+			name: "new version and changelog",
+			current: types.ModInfo{
+				ModID:         1234,
+				LatestVersion: "1.1",
+				ChangeLogs: []types.ChangeLog{
+					{Version: "1.1", Notes: []string{"Fixed things"}},
+					{Version: "1.0", Notes: []string{"Initial release"}},
+				},
+			},
+			changed:        true,
+			versionChanged: true,
+			newChangeLogs:  1,
+		},
+		{
+			name: "new changelog only",
+			current: types.ModInfo{
+				ModID:         1234,
+				LatestVersion: "1.0",
+				ChangeLogs: []types.ChangeLog{
+					{Version: "0.9", Notes: []string{"Backfilled"}},
+					{Version: "1.0", Notes: []string{"Initial release"}},
+				},
+			},
+			changed:       true,
+			newChangeLogs: 1,
+		},
+		{
+			name:    "missing latest version",
+			current: types.ModInfo{ModID: 1234, ChangeLogs: previous.ChangeLogs},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			event, changed := DetectChanges("skyrim", "https://example.com", previous, tt.current)
+
+			// Assert
+			assert.Equal(t, tt.changed, changed)
+			assert.Equal(t, tt.versionChanged, event.VersionChanged)
+			assert.Len(t, event.NewChangeLogs, tt.newChangeLogs)
+			if changed {
+				assert.Equal(t, "1.0", event.PreviousVersion)
+				assert.False(t, event.DetectedAt.IsZero())
+			}
+		})
+	}
+}
+
+func TestParseSink(t *testing.T) {
+	tests := []struct {
+		spec    string
+		name    string
+		wantErr bool
+	}{
+		{spec: "webhook=https://example.com/hook", name: "webhook example.com"},
+		{spec: "Discord=https://discord.test/api/webhooks/1/secret-token", name: "discord discord.test"},
+		{spec: "slack=https://hooks.slack.test/services/T0/B0/secret-token", name: "slack hooks.slack.test"},
+		{spec: "command=cat > /dev/null", name: "command cat > /dev/null"},
+		{spec: "webhook", wantErr: true},
+		{spec: "email=me@example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			// Act
+			sink, err := ParseSink(tt.spec, nil)
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.name, sink.Name())
+		})
+	}
+}
+
+func TestHTTPSink_Payloads(t *testing.T) {
+	tests := []struct {
+		kind string
+		key  string
+	}{
+		{kind: WebhookSink, key: "message"},
+		{kind: DiscordSink, key: "content"},
+		{kind: SlackSink, key: "text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			// Arrange
+			var body map[string]interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				data, _ := io.ReadAll(r.Body)
+				require.NoError(t, json.Unmarshal(data, &body))
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+			sink := &HTTPSink{Kind: tt.kind, URL: server.URL, Client: server.Client()}
+
+			// Act
+			err := sink.Send(context.Background(), testEvent(), "hello")
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, "hello", body[tt.key])
+			if tt.kind == WebhookSink {
+				assert.Equal(t, "1.1", body["event"].(map[string]interface{})["latestVersion"])
+			}
+		})
+	}
+}
+
+func TestHTTPSink_ErrorStatus(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	sink := &HTTPSink{Kind: WebhookSink, URL: server.URL}
+
+	// Act
+	err := sink.Send(context.Background(), testEvent(), "hello")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "returned 502")
+}
+
+func TestHTTPSink_ErrorsHideToken(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	rejected := &HTTPSink{Kind: DiscordSink, URL: server.URL + "/api/webhooks/1/secret-token"}
+	unreachable := &HTTPSink{Kind: SlackSink, URL: "http://127.0.0.1:0/services/T0/B0/secret-token"}
+
+	// Act
+	rejectedErr := rejected.Send(context.Background(), testEvent(), "hello")
+	unreachableErr := unreachable.Send(context.Background(), testEvent(), "hello")
+
+	// Assert
+	require.Error(t, rejectedErr)
+	require.Error(t, unreachableErr)
+	assert.Contains(t, rejectedErr.Error(), "returned 403")
+	assert.NotContains(t, rejectedErr.Error(), "secret-token")
+	assert.NotContains(t, unreachableErr.Error(), "secret-token")
+}
+
+func TestExecSink_Send(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+
+	// Arrange
+	out := filepath.Join(t.TempDir(), "event.json")
+	sink := &ExecSink{Command: `cat > "` + out + `" && test "$NMS_MESSAGE" = "hello"`}
+
+	// Act
+	err := sink.Send(context.Background(), testEvent(), "hello")
+
+	// Assert
+	require.NoError(t, err)
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	var event Event
+	require.NoError(t, json.Unmarshal(data, &event))
+	assert.Equal(t, int64(1234), event.ModID)
+}
+
+func TestExecSink_Failure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+
+	// Arrange
+	sink := &ExecSink{Command: "echo broken >&2; exit 3"}
+
+	// Act
+	err := sink.Send(context.Background(), testEvent(), "hello")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "broken")
+}
+
+func TestNotifier_DefaultMessage(t *testing.T) {
+	// Arrange
+	sink := &fakeSink{}
+	notifier := &Notifier{Sinks: []Sink{sink}}
+
+	// Act
+	err := notifier.Notify(context.Background(), testEvent())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, sink.messages, 1)
+	assert.Equal(t, "Test Mod (skyrim #1234) updated from 1.0 to 1.1\n1.1: Fixed things; Added things\nhttps://nexusmods.com/skyrim/mods/1234", sink.messages[0])
+}
+
+func TestNotifier_CustomTemplate(t *testing.T) {
+	// Arrange
+	tmpl, err := ParseMessageTemplate("{{ upper .Name }} {{ .LatestVersion }}")
+	require.NoError(t, err)
+	sink := &fakeSink{}
+	notifier := &Notifier{Sinks: []Sink{sink}, Template: tmpl}
+
+	// Act
+	err = notifier.Notify(context.Background(), testEvent())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TEST MOD 1.1"}, sink.messages)
+}
+
+func TestParseMessageTemplate_Invalid(t *testing.T) {
+	// Act
+	_, err := ParseMessageTemplate("{{ .Name ")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "error parsing notification template")
+}
+
+func TestNotifier_Retries(t *testing.T) {
+	// Arrange
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	notifier := &Notifier{
+		Sinks:   []Sink{&HTTPSink{Kind: SlackSink, URL: server.URL}},
+		Retries: 2,
+		Backoff: time.Millisecond,
+	}
+
+	// Act
+	err := notifier.Notify(context.Background(), testEvent())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+}
+
+func TestNotifier_GivesUpAfterRetries(t *testing.T) {
+	// Arrange
+	failing := &fakeSink{failures: 10}
+	working := &fakeSink{}
+	notifier := &Notifier{Sinks: []Sink{failing, working}, Retries: 1, Backoff: time.Millisecond}
+
+	// Act
+	err := notifier.Notify(context.Background(), testEvent())
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "after 2 attempts")
+	assert.Equal(t, 2, failing.calls)
+	assert.Len(t, working.messages, 1)
+}
+
+func TestNotifier_NegativeRetries(t *testing.T) {
+	// Arrange
+	failing := &fakeSink{failures: 10}
+	notifier := &Notifier{Sinks: []Sink{failing}, Retries: -2, Backoff: time.Millisecond}
+
+	// Act
+	err := notifier.Notify(context.Background(), testEvent())
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "after 1 attempts")
+	assert.NotContains(t, err.Error(), "%!w")
+	assert.Equal(t, 1, failing.calls)
+}
+
+func TestNotifier_DryRun(t *testing.T) {
+	// Arrange
+	sink := &fakeSink{}
+	var out bytes.Buffer
+	notifier := &Notifier{Sinks: []Sink{sink}, DryRun: true, Out: &out}
+
+	// Act
+	err := notifier.Notify(context.Background(), testEvent())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sink.calls)
+	assert.Contains(t, out.String(), "[dry-run] fake:")
+	assert.Contains(t, out.String(), "updated from 1.0 to 1.1")
+}
@@ -0,0 +1,281 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Priorities for common tasks, a mod's page is fetched before its tabs.
+const (
+	LowPriority    = -10
+	NormalPriority = 0
+	HighPriority   = 10
+)
+
+// Task is a unit of work run by the scheduler.
+type Task struct {
+	// Label identifies the task in progress reports and errors.
+	Label string
+	// Host is the host the task requests, tasks for the same host share its
+	// concurrency limit. An empty host is not limited.
+	Host string
+	// Priority orders the queue, higher priorities start first and tasks with the
+	// same priority start in the order they were given.
+	Priority int
+	// Run does the work, stopping early when ctx is cancelled.
+	Run func(ctx context.Context) error
+}
+
+// HostOf returns the host of rawURL for a task's Host, or an empty host when rawURL
+// cannot be parsed.
+func HostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// Progress reports a finished task along with the running totals.
+type Progress struct {
+	Label     string
+	Err       error
+	Completed int
+	Failed    int
+	Total     int
+}
+
+// Options configures a Scheduler.
+type Options struct {
+	// Workers is the number of tasks run at once, defaulting to 4.
+	Workers int
+	// PerHost limits the tasks run at once for the same host, 0 means no limit.
+	PerHost int
+	// FailFast cancels the remaining tasks after the first failure.
+	FailFast bool
+	// OnProgress is called after each task finishes, one call at a time and in
+	// order, so it should return quickly.
+	OnProgress func(Progress)
+}
+
+// TaskError is the failure of a labelled task.
+type TaskError struct {
+	Label string
+	Err   error
+}
+
+// Error returns the task's error prefixed with its label.
+func (e *TaskError) Error() string {
+	if e.Label == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %v", e.Label, e.Err)
+}
+
+// Unwrap returns the task's error.
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// Errors collects every failed task, in the order the tasks were given.
+type Errors []*TaskError
+
+// Error returns the failures joined into one message.
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d tasks failed: %s", len(e), strings.Join(messages, "; "))
+}
+
+// Unwrap returns the failures so errors.Is and errors.As can match any of them.
+func (e Errors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Scheduler runs tasks on a bounded pool of workers.
+type Scheduler struct {
+	options Options
+}
+
+// New creates a Scheduler with the given options.
+func New(options Options) *Scheduler {
+	if options.Workers <= 0 {
+		options.Workers = 4
+	}
+	return &Scheduler{options: options}
+}
+
+// queued is a task waiting to run, with its position in the given tasks.
+type queued struct {
+	Task
+	index int
+}
+
+// run holds the state shared by the workers during a call to Run.
+type run struct {
+	options Options
+	ctx     context.Context
+	cancel  context.CancelFunc
+	parent  context.Context
+
+	mu         sync.Mutex
+	ready      *sync.Cond
+	queue      []queued
+	active     map[string]int
+	failures   map[int]*TaskError
+	failedFast bool
+	completed  int
+	failed     int
+	total      int
+}
+
+// Run runs the tasks and waits for them to finish. Tasks that have not started when
+// ctx is cancelled are skipped, and a task that panics fails with the panic. Returns
+// nil when every task succeeds, Errors holding each failed task, or ctx's error when
+// tasks were skipped without any failing.
+func (s *Scheduler) Run(ctx context.Context, tasks ...Task) error {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	r := &run{
+		options:  s.options,
+		ctx:      runCtx,
+		cancel:   cancel,
+		parent:   ctx,
+		queue:    make([]queued, len(tasks)),
+		active:   map[string]int{},
+		failures: map[int]*TaskError{},
+		total:    len(tasks),
+	}
+	r.ready = sync.NewCond(&r.mu)
+	for i, task := range tasks {
+		r.queue[i] = queued{Task: task, index: i}
+	}
+	sort.SliceStable(r.queue, func(i, j int) bool {
+		return r.queue[i].Priority > r.queue[j].Priority
+	})
+
+	workers := min(s.options.Workers, len(tasks))
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.work()
+		}()
+	}
+	wg.Wait()
+
+	if len(r.failures) == 0 {
+		if r.completed+r.failed < r.total {
+			return ctx.Err()
+		}
+		return nil
+	}
+
+	indexes := make([]int, 0, len(r.failures))
+	for index := range r.failures {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	errs := make(Errors, len(indexes))
+	for i, index := range indexes {
+		errs[i] = r.failures[index]
+	}
+	return errs
+}
+
+// work runs queued tasks until the queue is empty or the run is cancelled.
+func (r *run) work() {
+	for {
+		task, ok := r.next()
+		if !ok {
+			return
+		}
+
+		r.finish(task, runTask(r.ctx, task.Task))
+	}
+}
+
+// runTask runs the task, returning a panic inside it as the task's error so one
+// task cannot take down the other workers.
+func runTask(ctx context.Context, task Task) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("task panicked: %v", recovered)
+		}
+	}()
+	return task.Run(ctx)
+}
+
+// next takes the highest priority task whose host is under its limit, waiting for
+// a running task to finish when every queued host is busy. Returns false when the
+// queue is empty or the run is cancelled.
+func (r *run) next() (queued, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for {
+		if len(r.queue) == 0 || r.ctx.Err() != nil {
+			r.queue = nil
+			r.ready.Broadcast()
+			return queued{}, false
+		}
+
+		for i, task := range r.queue {
+			if task.Host != "" && r.options.PerHost > 0 && r.active[task.Host] >= r.options.PerHost {
+				continue
+			}
+			r.queue = append(r.queue[:i], r.queue[i+1:]...)
+			r.active[task.Host]++
+			return task, true
+		}
+
+		r.ready.Wait()
+	}
+}
+
+// finish records the outcome of a task, cancelling the run on failure when failing
+// fast, and reports progress. Tasks cancelled by failing fast are not reported.
+func (r *run) finish(task queued, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	defer r.ready.Broadcast()
+
+	r.active[task.Host]--
+
+	switch {
+	case err == nil:
+		r.completed++
+	case r.failedFast && r.parent.Err() == nil && errors.Is(err, context.Canceled):
+		// Cancelled because another task failed, not a failure of its own
+		return
+	default:
+		r.failed++
+		r.failures[task.index] = &TaskError{Label: task.Label, Err: err}
+		if r.options.FailFast && !r.failedFast {
+			r.failedFast = true
+			r.cancel()
+		}
+	}
+
+	if r.options.OnProgress != nil {
+		r.options.OnProgress(Progress{Label: task.Label, Err: err, Completed: r.completed, Failed: r.failed, Total: r.total})
+	}
+}
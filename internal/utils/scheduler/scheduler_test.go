@@ -0,0 +1,232 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun_AllSucceed(t *testing.T) {
+	// Arrange
+	var calls int32
+	tasks := make([]Task, 20)
+	for i := range tasks {
+		tasks[i] = Task{Run: func(ctx context.Context) error {
+			atomic.AddInt32(&calls, 1)
+			return nil
+		}}
+	}
+
+	// Act
+	err := New(Options{Workers: 3}).Run(context.Background(), tasks...)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int32(20), calls)
+}
+
+func TestRun_LimitsWorkers(t *testing.T) {
+	// Arrange
+	var running, peak int32
+	tasks := make([]Task, 12)
+	for i := range tasks {
+		tasks[i] = Task{Run: func(ctx context.Context) error {
+			now := atomic.AddInt32(&running, 1)
+			for {
+				old := atomic.LoadInt32(&peak)
+				if now <= old || atomic.CompareAndSwapInt32(&peak, old, now) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil
+		}}
+	}
+
+	// Act
+	err := New(Options{Workers: 3}).Run(context.Background(), tasks...)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, peak, int32(3))
+}
+
+func TestRun_PerHostLimit(t *testing.T) {
+	// Arrange
+	var mu sync.Mutex
+	running := map[string]int{}
+	peak := map[string]int{}
+	task := func(host string) Task {
+		return Task{Host: host, Run: func(ctx context.Context) error {
+			mu.Lock()
+			running[host]++
+			peak[host] = max(peak[host], running[host])
+			mu.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			mu.Lock()
+			running[host]--
+			mu.Unlock()
+			return nil
+		}}
+	}
+	var tasks []Task
+	for i := 0; i < 6; i++ {
+		tasks = append(tasks, task("nexusmods.com"), task("example.com"))
+	}
+
+	// Act
+	err := New(Options{Workers: 6, PerHost: 2}).Run(context.Background(), tasks...)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, peak["nexusmods.com"])
+	assert.Equal(t, 2, peak["example.com"])
+}
+
+func TestRun_Priority(t *testing.T) {
+	// Arrange
+	var mu sync.Mutex
+	var order []string
+	task := func(label string, priority int) Task {
+		return Task{Label: label, Priority: priority, Run: func(ctx context.Context) error {
+			mu.Lock()
+			order = append(order, label)
+			mu.Unlock()
+			return nil
+		}}
+	}
+
+	// Act
+	err := New(Options{Workers: 1}).Run(context.Background(),
+		task("files tab", NormalPriority),
+		task("changelog", LowPriority),
+		task("mod page", HighPriority),
+		task("requirements", NormalPriority),
+	)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mod page", "files tab", "requirements", "changelog"}, order)
+}
+
+func TestRun_CollectsEveryError(t *testing.T) {
+	// Arrange
+	pageErr := errors.New("page failed")
+	filesErr := errors.New("files failed")
+
+	// Act
+	err := New(Options{Workers: 2}).Run(context.Background(),
+		Task{Label: "mod 1", Run: func(ctx context.Context) error { return nil }},
+		Task{Label: "mod 2", Run: func(ctx context.Context) error { return pageErr }},
+		Task{Label: "mod 3", Run: func(ctx context.Context) error { return filesErr }},
+	)
+
+	// Assert
+	var errs Errors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "mod 2", errs[0].Label)
+	assert.Equal(t, "mod 3", errs[1].Label)
+	assert.ErrorIs(t, err, pageErr)
+	assert.ErrorIs(t, err, filesErr)
+	assert.EqualError(t, err, "2 tasks failed: mod 2: page failed; mod 3: files failed")
+}
+
+func TestRun_FailFast(t *testing.T) {
+	// Arrange
+	expectedErr := errors.New("page failed")
+	var started int32
+
+	// Act
+	err := New(Options{Workers: 2, FailFast: true}).Run(context.Background(),
+		Task{Label: "page", Run: func(ctx context.Context) error { return expectedErr }},
+		Task{Label: "files", Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+		Task{Label: "queued", Priority: LowPriority, Run: func(ctx context.Context) error {
+			atomic.AddInt32(&started, 1)
+			return nil
+		}},
+	)
+
+	// Assert
+	assert.EqualError(t, err, "page: page failed")
+	assert.Equal(t, int32(0), started)
+}
+
+func TestRun_Progress(t *testing.T) {
+	// Arrange
+	var reports []Progress
+	options := Options{Workers: 2, OnProgress: func(p Progress) { reports = append(reports, p) }}
+
+	// Act
+	New(options).Run(context.Background(),
+		Task{Label: "ok", Run: func(ctx context.Context) error { return nil }},
+		Task{Label: "bad", Run: func(ctx context.Context) error { return errors.New("failed") }},
+		Task{Label: "ok again", Run: func(ctx context.Context) error { return nil }},
+	)
+
+	// Assert
+	require.Len(t, reports, 3)
+	for i, report := range reports {
+		assert.Equal(t, 3, report.Total)
+		assert.Equal(t, i+1, report.Completed+report.Failed)
+	}
+	assert.Equal(t, 2, reports[2].Completed)
+	assert.Equal(t, 1, reports[2].Failed)
+}
+
+func TestRun_CancelledContext(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var started int32
+
+	// Act
+	err := New(Options{}).Run(ctx, Task{Run: func(ctx context.Context) error {
+		atomic.AddInt32(&started, 1)
+		return nil
+	}})
+
+	// Assert
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(0), started)
+}
+
+func TestRun_RecoversPanic(t *testing.T) {
+	// Arrange
+	var calls int32
+
+	// Act
+	err := New(Options{Workers: 1}).Run(context.Background(),
+		Task{Label: "panics", Run: func(ctx context.Context) error { panic("boom") }},
+		Task{Label: "after", Run: func(ctx context.Context) error {
+			atomic.AddInt32(&calls, 1)
+			return nil
+		}},
+	)
+
+	// Assert
+	assert.EqualError(t, err, "panics: task panicked: boom")
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestHostOf(t *testing.T) {
+	// Act
+	host := HostOf("https://nexusmods.com/skyrim/mods/1")
+	invalid := HostOf("://bad")
+
+	// Assert
+	assert.Equal(t, "nexusmods.com", host)
+	assert.Empty(t, invalid)
+}
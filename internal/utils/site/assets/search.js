@@ -0,0 +1,27 @@
+(function () {
+  var input = document.getElementById("search");
+  var results = document.getElementById("search-results");
+  var index = window.SEARCH_INDEX || [];
+  var root = window.SITE_ROOT || "";
+
+  input.addEventListener("input", function () {
+    var terms = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    results.innerHTML = "";
+    if (terms.length === 0) {
+      return;
+    }
+
+    index.filter(function (entry) {
+      return terms.every(function (term) {
+        return entry.text.indexOf(term) !== -1;
+      });
+    }).slice(0, 25).forEach(function (entry) {
+      var item = document.createElement("li");
+      var link = document.createElement("a");
+      link.href = root + entry.url;
+      link.textContent = entry.name + " (" + entry.game + ")";
+      item.appendChild(link);
+      results.appendChild(item);
+    });
+  });
+})();
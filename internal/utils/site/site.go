@@ -0,0 +1,215 @@
+package site
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+)
+
+// assets holds the page templates, stylesheet and search script for the site.
+//
+//go:embed assets/*
+var assets embed.FS
+
+// Game is a game and the saved mods that belong to it.
+type Game struct {
+	Name string
+	Mods []types.ModInfo
+}
+
+// Slug returns the directory name used for the game's pages.
+func (g Game) Slug() string {
+	return strings.ToLower(g.Name)
+}
+
+// Link is a cross-link to another mod, Href is empty when the mod was not saved.
+type Link struct {
+	Name  string
+	Notes string
+	Href  string
+}
+
+// searchEntry is one mod in the client-side search index.
+type searchEntry struct {
+	Name string `json:"name"`
+	Game string `json:"game"`
+	Url  string `json:"url"`
+	Text string `json:"text"`
+}
+
+// page holds the values shared by every page template.
+type page struct {
+	Title     string
+	SiteTitle string
+	Root      string
+	Generated string
+}
+
+// Generate writes a browsable static site for the games into outDir, made up of an
+// index of games, an index per game, a page per mod with its files, changelogs,
+// requirements and tags, and a client-side search index. Returns the number of
+// pages written or an error if the templates fail or a file cannot be written.
+func Generate(outDir, title string, games []Game, ensureDirExistsFunc func(string) error) (int, error) {
+	tmpl, err := template.ParseFS(assets, "assets/*.html")
+	if err != nil {
+		return 0, fmt.Errorf("error parsing site templates: %w", err)
+	}
+
+	if err := ensureDirExistsFunc(outDir); err != nil {
+		return 0, err
+	}
+
+	sort.Slice(games, func(i, j int) bool { return games[i].Slug() < games[j].Slug() })
+	generated := time.Now().Format(time.RFC1123)
+	pages := 0
+
+	if err := renderPage(tmpl, "index.html", filepath.Join(outDir, "index.html"), struct {
+		page
+		Games []Game
+	}{page{title, title, "", generated}, games}); err != nil {
+		return pages, err
+	}
+	pages++
+
+	var index []searchEntry
+	for _, game := range games {
+		gameDir := filepath.Join(outDir, game.Slug())
+		if err := ensureDirExistsFunc(gameDir); err != nil {
+			return pages, err
+		}
+
+		if err := renderPage(tmpl, "game.html", filepath.Join(gameDir, "index.html"), struct {
+			page
+			Game Game
+		}{page{fmt.Sprintf("%s - %s", game.Name, title), title, "../", generated}, game}); err != nil {
+			return pages, err
+		}
+		pages++
+
+		byName := make(map[string]types.ModInfo, len(game.Mods))
+		for _, mod := range game.Mods {
+			byName[strings.ToLower(mod.Name)] = mod
+		}
+
+		for _, mod := range game.Mods {
+			if err := renderPage(tmpl, "mod.html", filepath.Join(gameDir, fmt.Sprintf("%d.html", mod.ModID)), struct {
+				page
+				Game         Game
+				Mod          types.ModInfo
+				Requirements []Link
+				RequiredBy   []Link
+			}{
+				page{fmt.Sprintf("%s - %s", mod.Name, game.Name), title, "../", generated},
+				game, mod, linkRequirements(mod.Dependencies, byName), requiredBy(mod, game.Mods, byName),
+			}); err != nil {
+				return pages, err
+			}
+			pages++
+
+			index = append(index, searchEntry{
+				Name: mod.Name,
+				Game: game.Name,
+				Url:  fmt.Sprintf("%s/%d.html", game.Slug(), mod.ModID),
+				Text: strings.ToLower(strings.Join(append([]string{mod.Name, game.Name, mod.Creator, mod.Uploader, mod.ShortDescription}, mod.Tags...), " ")),
+			})
+		}
+	}
+
+	if err := writeSearchIndex(filepath.Join(outDir, "search-index.js"), index); err != nil {
+		return pages, err
+	}
+
+	for _, asset := range []string{"style.css", "search.js"} {
+		data, err := assets.ReadFile("assets/" + asset)
+		if err != nil {
+			return pages, err
+		}
+		if err := os.WriteFile(filepath.Join(outDir, asset), data, 0644); err != nil {
+			return pages, fmt.Errorf("error saving file: %s - %v", asset, err)
+		}
+	}
+
+	return pages, nil
+}
+
+// renderPage executes the named template with data into the file at path.
+func renderPage(tmpl *template.Template, name, path string, data interface{}) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error saving file: %s - %v", path, err)
+	}
+	defer file.Close()
+
+	if err := tmpl.ExecuteTemplate(file, name, data); err != nil {
+		return fmt.Errorf("error rendering page: %s - %v", path, err)
+	}
+
+	return nil
+}
+
+// writeSearchIndex writes the search index as a script assigning window.SEARCH_INDEX,
+// which unlike a JSON file can also be loaded when the site is opened from disk.
+func writeSearchIndex(path string, index []searchEntry) error {
+	if index == nil {
+		index = []searchEntry{}
+	}
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("error formatting data: %s - %v", path, err)
+	}
+
+	if err := os.WriteFile(path, []byte(fmt.Sprintf("window.SEARCH_INDEX = %s;\n", data)), 0644); err != nil {
+		return fmt.Errorf("error saving file: %s - %v", path, err)
+	}
+
+	return nil
+}
+
+// linkRequirements turns requirements into links, pointing at the mod's page when a
+// mod with the same name was saved for the game.
+func linkRequirements(requirements []types.Requirement, byName map[string]types.ModInfo) []Link {
+	links := make([]Link, 0, len(requirements))
+	for _, requirement := range requirements {
+		link := Link{Name: requirement.Name, Notes: requirement.Notes}
+		if mod, ok := byName[strings.ToLower(requirement.Name)]; ok {
+			link.Href = fmt.Sprintf("%d.html", mod.ModID)
+		}
+		links = append(links, link)
+	}
+	return links
+}
+
+// requiredBy lists the mods that depend on mod, combining the mods scraped as using
+// it with every saved mod that names it as a requirement.
+func requiredBy(mod types.ModInfo, mods []types.ModInfo, byName map[string]types.ModInfo) []Link {
+	links := linkRequirements(mod.ModsUsing, byName)
+
+	seen := make(map[string]bool, len(links))
+	for _, link := range links {
+		seen[strings.ToLower(link.Name)] = true
+	}
+
+	for _, other := range mods {
+		if other.ModID == mod.ModID || seen[strings.ToLower(other.Name)] {
+			continue
+		}
+		for _, dependency := range other.Dependencies {
+			if strings.EqualFold(dependency.Name, mod.Name) {
+				links = append(links, Link{Name: other.Name, Href: fmt.Sprintf("%d.html", other.ModID)})
+				seen[strings.ToLower(other.Name)] = true
+				break
+			}
+		}
+	}
+
+	return links
+}
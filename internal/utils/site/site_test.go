@@ -0,0 +1,92 @@
+package site
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var siteGames = []Game{
+	{
+		Name: "skyrim",
+		Mods: []types.ModInfo{
+			{
+				ModID:         1,
+				Name:          "Base Framework",
+				Creator:       "Author",
+				Tags:          []string{"Utilities"},
+				ModsUsing:     []types.Requirement{{Name: "Unsaved Mod"}},
+				ChangeLogs:    []types.ChangeLog{{Version: "1.0", Notes: []string{"Initial <release>"}}},
+				Files:         []types.File{{Name: "Main File", Version: "1.0", FileSize: "1MB"}},
+				LatestVersion: "1.0",
+			},
+			{
+				ModID:        2,
+				Name:         "Armour Pack",
+				Dependencies: []types.Requirement{{Name: "base framework", Notes: "Required"}, {Name: "Elsewhere"}},
+			},
+		},
+	},
+}
+
+func readFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestGenerate_WritesPages(t *testing.T) {
+	// Arrange
+	outDir := t.TempDir()
+
+	// Act
+	pages, err := Generate(outDir, "Modpack", siteGames, func(dir string) error { return os.MkdirAll(dir, 0755) })
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 4, pages)
+
+	index := readFile(t, filepath.Join(outDir, "index.html"))
+	assert.Contains(t, index, `<a href="skyrim/index.html">skyrim</a>`)
+	assert.Contains(t, index, `href="style.css"`)
+
+	game := readFile(t, filepath.Join(outDir, "skyrim", "index.html"))
+	assert.Contains(t, game, `<a href="1.html">Base Framework</a>`)
+	assert.Contains(t, game, `href="../style.css"`)
+
+	base := readFile(t, filepath.Join(outDir, "skyrim", "1.html"))
+	assert.Contains(t, base, "Initial &lt;release&gt;")
+	assert.Contains(t, base, "<td>Main File</td>")
+	assert.Contains(t, base, `<span class="tag">Utilities</span>`)
+	assert.Contains(t, base, "<li>Unsaved Mod</li>")
+	assert.Contains(t, base, `<a href="2.html">Armour Pack</a>`)
+
+	armour := readFile(t, filepath.Join(outDir, "skyrim", "2.html"))
+	assert.Contains(t, armour, `<a href="1.html">base framework</a> <span class="notes">Required</span>`)
+	assert.Contains(t, armour, "<li>Elsewhere</li>")
+
+	search := readFile(t, filepath.Join(outDir, "search-index.js"))
+	assert.True(t, strings.HasPrefix(search, "window.SEARCH_INDEX = ["))
+	assert.Contains(t, search, `"url":"skyrim/2.html"`)
+	assert.FileExists(t, filepath.Join(outDir, "search.js"))
+	assert.FileExists(t, filepath.Join(outDir, "style.css"))
+}
+
+func TestGenerate_EnsureDirExistsError(t *testing.T) {
+	// Act
+	_, err := Generate(t.TempDir(), "Modpack", siteGames, func(string) error { return errors.New("directory error") })
+
+	// Assert
+	assert.EqualError(t, err, "directory error")
+}
+
+func TestGame_Slug(t *testing.T) {
+	// Assert
+	assert.Equal(t, "skyrimspecialedition", Game{Name: "SkyrimSpecialEdition"}.Slug())
+}
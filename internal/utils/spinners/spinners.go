@@ -2,56 +2,167 @@ package spinners
 
 import (
 	"fmt"
+	"io"
 	"os"
-	"os/signal"
-	"syscall"
+	"sync"
 	"time"
 
 	"github.com/theckman/yacspin"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/terminal"
+)
+
+// Spinner shows the progress of a step, from its start message until it stops with
+// its stop or failure message.
+type Spinner interface {
+	Start() error
+	Stop() error
+	StopFail() error
+	StopMessage(message string)
+	StopFailMessage(message string)
+}
+
+// Output configures where spinners write and how they look.
+type Output struct {
+	// Writer is where the progress is written, stderr by default so the results
+	// on stdout can be piped.
+	Writer io.Writer
+	// Interactive animates the spinners with cursor control. Otherwise each message
+	// is written once on its own line.
+	Interactive bool
+	// Color colors the spinners.
+	Color bool
+}
+
+var (
+	// outputMu guards output.
+	outputMu sync.RWMutex
+	// output is where spinners write and how they look, animated and colored only
+	// when stderr is a terminal unless changed with SetOutput.
+	output = Output{
+		Writer:      os.Stderr,
+		Interactive: terminal.IsTerminal(os.Stderr),
+		Color:       terminal.Color(os.Stderr, false),
+	}
 )
 
-// CreateSpinner initializes and returns a yacspin spinner with the provided
-// start and stop messages, characters, and failure configurations.
-func CreateSpinner(startMessage, stopCharacter, stopMessage, stopFailCharacter, stopFailMessage string) *yacspin.Spinner {
+// SetOutput sets where the spinners created afterwards write and how they look.
+func SetOutput(o Output) {
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	if o.Writer == nil {
+		o.Writer = os.Stderr
+	}
+	output = o
+}
+
+// CreateSpinner returns a spinner with the provided start and stop messages,
+// characters, and failure configurations. It is an animated yacspin spinner when the
+// output is interactive, and otherwise writes each message on its own line without
+// cursor control, as it also does if the spinner cannot be created.
+func CreateSpinner(startMessage, stopCharacter, stopMessage, stopFailCharacter, stopFailMessage string) Spinner {
+	outputMu.RLock()
+	o := output
+	outputMu.RUnlock()
+
+	lines := &lineSpinner{
+		writer:            o.Writer,
+		message:           startMessage,
+		stopCharacter:     stopCharacter,
+		stopMessage:       stopMessage,
+		stopFailCharacter: stopFailCharacter,
+		stopFailMessage:   stopFailMessage,
+	}
+	if !o.Interactive {
+		return lines
+	}
+
 	cfg := yacspin.Config{
+		Writer:            o.Writer,
+		TerminalMode:      yacspin.ForceTTYMode | yacspin.ForceSmartTerminalMode,
 		Frequency:         100 * time.Millisecond,
-		Colors:            []string{"fgHiBlue"},
 		CharSet:           yacspin.CharSets[14],
 		Suffix:            " ",
 		SuffixAutoColon:   true,
 		Message:           startMessage,
 		StopCharacter:     stopCharacter,
-		StopColors:        []string{"fgHiGreen"},
 		StopMessage:       stopMessage,
 		StopFailCharacter: stopFailCharacter,
-		StopFailColors:    []string{"fgHiRed"},
 		StopFailMessage:   stopFailMessage,
 	}
+	if o.Color {
+		cfg.Colors = []string{"fgHiBlue"}
+		cfg.StopColors = []string{"fgHiGreen"}
+		cfg.StopFailColors = []string{"fgHiRed"}
+	}
 
 	s, err := yacspin.New(cfg)
 	if err != nil {
-		fmt.Printf("failed to create spinner: %v\n", err)
-		os.Exit(1)
+		return lines
 	}
 
 	return s
 }
 
-// stopOnSignal stops the spinner with a failure message if an interrupt or
-// termination signal is received, ensuring proper cleanup before exiting.
-func stopOnSignal(spinner *yacspin.Spinner) {
-	// ensure we stop the spinner before exiting, otherwise cursor will remain
-	// hidden and terminal will require a `reset`
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-sigCh
+// lineSpinner is a Spinner for output that is not a terminal, writing the start
+// message when it starts and the stop or failure message when it stops.
+type lineSpinner struct {
+	writer            io.Writer
+	message           string
+	stopCharacter     string
+	stopMessage       string
+	stopFailCharacter string
+	stopFailMessage   string
+
+	mu      sync.Mutex
+	running bool
+}
+
+// Start writes the start message.
+func (s *lineSpinner) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return nil
+	}
+	s.running = true
+	_, err := fmt.Fprintf(s.writer, "%s\n", s.message)
+	return err
+}
+
+// Stop writes the stop message, if the spinner is running.
+func (s *lineSpinner) Stop() error {
+	return s.stop(s.stopCharacter, s.stopMessage)
+}
 
-		spinner.StopFailMessage("interrupted")
+// StopFail writes the failure message, if the spinner is running.
+func (s *lineSpinner) StopFail() error {
+	return s.stop(s.stopFailCharacter, s.stopFailMessage)
+}
 
-		// ignoring error intentionally
-		_ = spinner.StopFail()
+// StopMessage sets the message written when the spinner stops.
+func (s *lineSpinner) StopMessage(message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopMessage = message
+}
 
-		os.Exit(0)
-	}()
+// StopFailMessage sets the message written when the spinner stops with a failure.
+func (s *lineSpinner) StopFailMessage(message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopFailMessage = message
+}
+
+// stop writes the character and message when the spinner is running, so stopping
+// it again writes nothing.
+func (s *lineSpinner) stop(character, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.running {
+		return nil
+	}
+	s.running = false
+	_, err := fmt.Fprintf(s.writer, "%s %s\n", character, message)
+	return err
 }
@@ -1,12 +1,10 @@
 package spinners
 
 import (
-	"os"
-
-	"os/signal"
-	"time"
-
+	"bytes"
 	"testing"
+
+	"github.com/theckman/yacspin"
 )
 
 func TestCreateSpinner_StartAndStop(t *testing.T) {
@@ -38,33 +36,45 @@ func TestCreateSpinner_StartAndStop(t *testing.T) {
 	}
 }
 
-func TestStopOnSignal_Interrupt(t *testing.T) {
+func TestCreateSpinner_NotInteractive(t *testing.T) {
 	// Arrange
-	spinner := CreateSpinner("Starting...", "✔", "Completed", "✘", "Failed")
+	var out bytes.Buffer
+	SetOutput(Output{Writer: &out})
+	t.Cleanup(func() { SetOutput(Output{}) })
 
-	// Start the spinner to ensure it is running
-	err := spinner.Start()
-	if err != nil {
-		t.Fatalf("Expected spinner to start successfully, but got error: %v", err)
-	}
+	saving := CreateSpinner("Saving results", "✓", "Results saved", "✗", "Failed to save results")
+	failing := CreateSpinner("Checking session", "✓", "Session is logged in", "✗", "Session check failed")
 
-	// Simulate handling signal
-	go stopOnSignal(spinner)
+	// Act
+	_ = saving.Start()
+	saving.StopMessage("Saved successfully to /mods/skyrim/mod 1.json")
+	_ = saving.Stop()
+	_ = saving.Stop()
+	_ = failing.Start()
+	failing.StopFailMessage("Error checking session: expired")
+	_ = failing.StopFail()
 
-	// Act: Send an interrupt signal
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, os.Interrupt)
-	go func() {
-		time.Sleep(100 * time.Millisecond)
-		sigCh <- os.Interrupt
-	}()
+	// Assert
+	expected := "Saving results\n" +
+		"✓ Saved successfully to /mods/skyrim/mod 1.json\n" +
+		"Checking session\n" +
+		"✗ Error checking session: expired\n"
+	if out.String() != expected {
+		t.Errorf("Expected the messages on their own lines without escape codes, got %q", out.String())
+	}
+}
 
-	// Allow time for the signal to be processed and the spinner to stop
-	time.Sleep(200 * time.Millisecond)
+func TestCreateSpinner_Interactive(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+	SetOutput(Output{Writer: &out, Interactive: true})
+	t.Cleanup(func() { SetOutput(Output{}) })
 
-	// Assert: Check that StopFail was called and no errors occurred
-	err = spinner.StopFail()
-	if err != nil {
-		t.Errorf("Expected spinner to stop with failure, but got error: %v", err)
+	// Act
+	spinner := CreateSpinner("Starting...", "✔", "Completed", "✘", "Failed")
+
+	// Assert
+	if _, ok := spinner.(*yacspin.Spinner); !ok {
+		t.Errorf("Expected an animated spinner, got %T", spinner)
 	}
 }
@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/logging"
+)
+
+// LoadSavedResults reads every saved mod JSON file in the given game directory
+// and returns the decoded results sorted by mod ID. Files that are not mod
+// results, such as session cookies, are skipped, as are files that cannot be
+// read or decoded, which are logged to the logger when it is not nil. Returns an
+// error if the directory cannot be read.
+func LoadSavedResults(dir string, logger *slog.Logger) ([]types.Results, error) {
+	if logger == nil {
+		logger = logging.Discard()
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory: %s - %w", dir, err)
+	}
+
+	var saved []types.Results
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".json") {
+			continue
+		}
+
+		fullPath := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(fullPath)
+		if err != nil {
+			logger.Warn("skipped saved file", "path", fullPath, "error", fmt.Errorf("error reading file: %w", err))
+			continue
+		}
+
+		var results types.Results
+		if err := json.Unmarshal(data, &results); err != nil {
+			logger.Warn("skipped saved file", "path", fullPath, "error", fmt.Errorf("error decoding file: %w", err))
+			continue
+		}
+
+		// Only files holding a scraped mod are results
+		if results.Mods.ModID == 0 {
+			continue
+		}
+
+		saved = append(saved, results)
+	}
+
+	sort.SliceStable(saved, func(i, j int) bool {
+		return saved[i].Mods.ModID < saved[j].Mods.ModID
+	})
+
+	return saved, nil
+}
+
+// ListSavedGames returns the sorted names of the game directories under the
+// given output directory. Returns an error if the directory cannot be read.
+func ListSavedGames(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory: %s - %w", dir, err)
+	}
+
+	var games []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			games = append(games, entry.Name())
+		}
+	}
+
+	sort.Strings(games)
+	return games, nil
+}
+
+// LoadSavedMod returns the saved results for the mod with the given ID in the game
+// directory, read from the files saved for the mod ID in the save format. When a
+// renamed mod left several files, the one checked last wins. Files that cannot be
+// read or parsed are skipped and logged to the logger when it is not nil. The boolean
+// is false when the directory or the mod does not exist. Returns an error if the save
+// format is unknown or cannot be loaded.
+func LoadSavedMod(dir string, modID int64, saveFormat string, logger *slog.Logger) (types.Results, bool, error) {
+	if logger == nil {
+		logger = logging.Discard()
+	}
+
+	format, err := formatters.LookupFormat(saveFormat)
+	if err != nil {
+		return types.Results{}, false, err
+	}
+	if format.Parse == nil {
+		return types.Results{}, false, fmt.Errorf("saved %s results cannot be loaded", format.Name)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return types.Results{}, false, nil
+	}
+	if err != nil {
+		return types.Results{}, false, fmt.Errorf("error reading directory: %s - %w", dir, err)
+	}
+
+	// Saved files are named "<name> <mod id>.<extension>", the name can change
+	suffix := strings.ToLower(fmt.Sprintf(" %d.%s", modID, format.Extension))
+
+	var found types.Results
+	var foundModTime time.Time
+	ok := false
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(strings.ToLower(entry.Name()), suffix) {
+			continue
+		}
+
+		fullPath := filepath.Join(dir, entry.Name())
+		info, err := entry.Info()
+		if err != nil {
+			logger.Warn("skipped saved file", "path", fullPath, "error", fmt.Errorf("error reading file: %w", err))
+			continue
+		}
+		data, err := os.ReadFile(fullPath)
+		if err != nil {
+			logger.Warn("skipped saved file", "path", fullPath, "error", fmt.Errorf("error reading file: %w", err))
+			continue
+		}
+
+		mod, err := format.Parse(string(data))
+		if err != nil {
+			logger.Warn("skipped saved file", "path", fullPath, "error", fmt.Errorf("error decoding file: %w", err))
+			continue
+		}
+		if mod.ModID != modID {
+			continue
+		}
+
+		if ok && !newerSave(mod.LastChecked, info.ModTime(), found.Mods.LastChecked, foundModTime) {
+			continue
+		}
+		found, foundModTime, ok = types.Results{Mods: mod}, info.ModTime(), true
+	}
+
+	return found, ok, nil
+}
+
+// newerSave reports whether a saved mod is newer than another, comparing when they
+// were checked and then when their files were modified.
+func newerSave(checked, modTime, otherChecked, otherModTime time.Time) bool {
+	if !checked.Equal(otherChecked) {
+		return checked.After(otherChecked)
+	}
+	return modTime.After(otherModTime)
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSavedResults_Success(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "second 20.json"), []byte(`{"Mods":{"ModID":20,"Name":"Second"}}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "first 10.json"), []byte(`{"Mods":{"ModID":10,"Name":"First"}}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "session-cookies.json"), []byte(`{"nexusmods_session":"1234"}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0644))
+
+	// Act
+	saved, err := LoadSavedResults(dir, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	require.Len(t, saved, 2)
+	assert.Equal(t, "First", saved[0].Mods.Name)
+	assert.Equal(t, "Second", saved[1].Mods.Name)
+}
+
+func TestLoadSavedResults_SkipsInvalidJson(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken 1.json"), []byte(`{`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "first 10.json"), []byte(`{"Mods":{"ModID":10,"Name":"First"}}`), 0644))
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	// Act
+	saved, err := LoadSavedResults(dir, logger)
+
+	// Assert
+	assert.NoError(t, err)
+	require.Len(t, saved, 1)
+	assert.Equal(t, "First", saved[0].Mods.Name)
+	assert.Contains(t, logs.String(), "skipped saved file")
+	assert.Contains(t, logs.String(), "broken 1.json")
+	assert.Contains(t, logs.String(), "error decoding file")
+}
+
+func TestLoadSavedResults_MissingDirectory(t *testing.T) {
+	// Act
+	_, err := LoadSavedResults(filepath.Join(t.TempDir(), "missing"), nil)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "error reading directory")
+}
+
+func TestListSavedGames(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "skyrim"), 0755))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "fallout4"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "session-cookies.json"), []byte("{}"), 0644))
+
+	// Act
+	games, err := ListSavedGames(dir)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"fallout4", "skyrim"}, games)
+}
+
+func TestLoadSavedMod(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "first 10.json"), []byte(`{"Mods":{"ModID":10,"Name":"First","LatestVersion":"1.0"}}`), 0644))
+
+	// Act
+	found, ok, err := LoadSavedMod(dir, 10, "json", nil)
+	_, missingOk, missingErr := LoadSavedMod(dir, 20, "json", nil)
+	_, noDirOk, noDirErr := LoadSavedMod(filepath.Join(dir, "missing"), 10, "json", nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "1.0", found.Mods.LatestVersion)
+	assert.NoError(t, missingErr)
+	assert.False(t, missingOk)
+	assert.NoError(t, noDirErr)
+	assert.False(t, noDirOk)
+}
+
+func TestLoadSavedMod_RenamedAndInvalid(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "old name 10.json"), []byte(`{"Mods":{"ModID":10,"Name":"Old Name","LatestVersion":"1.0","LastChecked":"2024-01-01T00:00:00Z"}}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new name 10.json"), []byte(`{"Mods":{"ModID":10,"Name":"New Name","LatestVersion":"2.0","LastChecked":"2024-02-01T00:00:00Z"}}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken 10.json"), []byte(`{`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other 110.json"), []byte(`{"Mods":{"ModID":110,"LastChecked":"2025-01-01T00:00:00Z"}}`), 0644))
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	// Act
+	found, ok, err := LoadSavedMod(dir, 10, "json", logger)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "New Name", found.Mods.Name)
+	assert.Equal(t, "2.0", found.Mods.LatestVersion)
+	assert.Contains(t, logs.String(), "skipped saved file")
+	assert.Contains(t, logs.String(), "broken 10.json")
+}
+
+func TestLoadSavedMod_SaveFormat(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "first 10.yaml"), []byte("ModID: 10\nName: First\nLatestVersion: \"1.0\"\n"), 0644))
+
+	// Act
+	found, ok, err := LoadSavedMod(dir, 10, "yaml", nil)
+	_, _, markdownErr := LoadSavedMod(dir, 10, "markdown", nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "1.0", found.Mods.LatestVersion)
+	assert.EqualError(t, markdownErr, "saved markdown results cannot be loaded")
+}
@@ -0,0 +1,283 @@
+package stores
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+
+	_ "modernc.org/sqlite"
+)
+
+const (
+	// dependencyKind marks a requirements row that came from ModInfo.Dependencies.
+	dependencyKind string = "dependency"
+	// modsUsingKind marks a requirements row that came from ModInfo.ModsUsing.
+	modsUsingKind string = "mods_using"
+)
+
+// sqliteSchema creates the normalised tables used to store scraped mods. Every
+// child table is keyed by (game, mod_id) so rows can be replaced wholesale when
+// a mod is scraped again.
+var sqliteSchema = []string{
+	`CREATE TABLE IF NOT EXISTS mods (
+		game              TEXT    NOT NULL,
+		mod_id            INTEGER NOT NULL,
+		name              TEXT,
+		creator           TEXT,
+		uploader          TEXT,
+		short_description TEXT,
+		description       TEXT,
+		last_updated      TEXT,
+		original_upload   TEXT,
+		latest_version    TEXT,
+		url               TEXT,
+		virus_status      TEXT,
+		last_checked      TEXT,
+		PRIMARY KEY (game, mod_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS files (
+		game             TEXT    NOT NULL,
+		mod_id           INTEGER NOT NULL,
+		position         INTEGER NOT NULL,
+		name             TEXT,
+		version          TEXT,
+		upload_date      TEXT,
+		file_size        TEXT,
+		unique_downloads TEXT,
+		total_downloads  TEXT,
+		description      TEXT,
+		PRIMARY KEY (game, mod_id, position)
+	)`,
+	`CREATE TABLE IF NOT EXISTS changelogs (
+		game     TEXT    NOT NULL,
+		mod_id   INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		version  TEXT,
+		notes    TEXT,
+		PRIMARY KEY (game, mod_id, position)
+	)`,
+	`CREATE TABLE IF NOT EXISTS tags (
+		game   TEXT    NOT NULL,
+		mod_id INTEGER NOT NULL,
+		tag    TEXT    NOT NULL,
+		PRIMARY KEY (game, mod_id, tag)
+	)`,
+	`CREATE TABLE IF NOT EXISTS requirements (
+		game     TEXT    NOT NULL,
+		mod_id   INTEGER NOT NULL,
+		kind     TEXT    NOT NULL,
+		position INTEGER NOT NULL,
+		name     TEXT,
+		notes    TEXT,
+		PRIMARY KEY (game, mod_id, kind, position)
+	)`,
+	`CREATE TABLE IF NOT EXISTS scrape_history (
+		game           TEXT    NOT NULL,
+		mod_id         INTEGER NOT NULL,
+		last_checked   TEXT    NOT NULL,
+		latest_version TEXT,
+		file_count     INTEGER,
+		PRIMARY KEY (game, mod_id, last_checked)
+	)`,
+}
+
+// OpenSqlite opens (creating if needed) the SQLite database at the given path and
+// ensures the schema exists. Returns the database handle or an error if the file
+// cannot be opened or the schema cannot be created.
+func OpenSqlite(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database: %s - %w", path, err)
+	}
+
+	for _, stmt := range sqliteSchema {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("error creating schema: %s - %w", path, err)
+		}
+	}
+
+	return db, nil
+}
+
+// SaveModInfoToSqlite opens the database at path, creating its directory with
+// ensureDirExistsFunc, and upserts the results for the given game. Returns the
+// database path or an error if any operation fails.
+func SaveModInfoToSqlite(path, game string, results types.Results, ensureDirExistsFunc func(string) error) (string, error) {
+	if err := ensureDirExistsFunc(filepath.Dir(path)); err != nil {
+		return "", err
+	}
+
+	db, err := OpenSqlite(path)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+
+	if err := UpsertModInfo(db, game, results.Mods); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// LoadModInfoFromSqlite reads the stored name, latest version and changelogs of a
+// mod from the database at path. The boolean is false when the database or the mod
+// does not exist. Returns an error if the database cannot be queried.
+func LoadModInfoFromSqlite(path, game string, modID int64) (types.ModInfo, bool, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return types.ModInfo{}, false, nil
+	}
+
+	db, err := OpenSqlite(path)
+	if err != nil {
+		return types.ModInfo{}, false, err
+	}
+	defer db.Close()
+
+	game = strings.ToLower(game)
+	mod := types.ModInfo{ModID: modID}
+	err = db.QueryRow(`SELECT name, latest_version FROM mods WHERE game = ? AND mod_id = ?`, game, modID).Scan(&mod.Name, &mod.LatestVersion)
+	if errors.Is(err, sql.ErrNoRows) {
+		return types.ModInfo{}, false, nil
+	}
+	if err != nil {
+		return types.ModInfo{}, false, fmt.Errorf("error loading mod %d: %w", modID, err)
+	}
+
+	rows, err := db.Query(`SELECT version, notes FROM changelogs WHERE game = ? AND mod_id = ? ORDER BY position`, game, modID)
+	if err != nil {
+		return types.ModInfo{}, false, fmt.Errorf("error loading changelogs for mod %d: %w", modID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version, notes string
+		if err := rows.Scan(&version, &notes); err != nil {
+			return types.ModInfo{}, false, fmt.Errorf("error loading changelogs for mod %d: %w", modID, err)
+		}
+
+		changeLog := types.ChangeLog{Version: version}
+		if err := json.Unmarshal([]byte(notes), &changeLog.Notes); err != nil {
+			return types.ModInfo{}, false, fmt.Errorf("error decoding changelog %q for mod %d: %w", version, modID, err)
+		}
+		mod.ChangeLogs = append(mod.ChangeLogs, changeLog)
+	}
+
+	return mod, true, rows.Err()
+}
+
+// UpsertModInfo writes a mod and its files, changelogs, tags and requirements in a
+// single transaction. The mod row is upserted on (game, mod_id), its child rows are
+// replaced, and a scrape_history row keyed by LastChecked is recorded.
+func UpsertModInfo(db *sql.DB, game string, mod types.ModInfo) error {
+	game = strings.ToLower(game)
+	lastChecked := mod.LastChecked
+	if lastChecked.IsZero() {
+		lastChecked = time.Now()
+	}
+	checked := lastChecked.UTC().Format(time.RFC3339Nano)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT INTO mods (
+			game, mod_id, name, creator, uploader, short_description, description,
+			last_updated, original_upload, latest_version, url, virus_status, last_checked
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (game, mod_id) DO UPDATE SET
+			name = excluded.name,
+			creator = excluded.creator,
+			uploader = excluded.uploader,
+			short_description = excluded.short_description,
+			description = excluded.description,
+			last_updated = excluded.last_updated,
+			original_upload = excluded.original_upload,
+			latest_version = excluded.latest_version,
+			url = excluded.url,
+			virus_status = excluded.virus_status,
+			last_checked = excluded.last_checked`,
+		game, mod.ModID, mod.Name, mod.Creator, mod.Uploader, mod.ShortDescription, mod.Description,
+		mod.LastUpdated, mod.OriginalUpload, mod.LatestVersion, mod.Url, mod.VirusStatus, checked,
+	); err != nil {
+		return fmt.Errorf("error saving mod %d: %w", mod.ModID, err)
+	}
+
+	// Child rows are replaced rather than merged so removed files or tags disappear
+	for _, table := range []string{"files", "changelogs", "tags", "requirements"} {
+		if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE game = ? AND mod_id = ?", table), game, mod.ModID); err != nil {
+			return fmt.Errorf("error clearing %s for mod %d: %w", table, mod.ModID, err)
+		}
+	}
+
+	for i, file := range mod.Files {
+		if _, err := tx.Exec(`INSERT INTO files (
+				game, mod_id, position, name, version, upload_date, file_size,
+				unique_downloads, total_downloads, description
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			game, mod.ModID, i, file.Name, file.Version, file.UploadDate, file.FileSize,
+			file.UniqueDLs, file.TotalDLs, file.Description,
+		); err != nil {
+			return fmt.Errorf("error saving file %q: %w", file.Name, err)
+		}
+	}
+
+	// Each changelog is one row with its notes as a JSON array, so changelogs without
+	// notes and repeated versions survive a round trip
+	for i, changeLog := range mod.ChangeLogs {
+		notes, err := json.Marshal(changeLog.Notes)
+		if err != nil {
+			return fmt.Errorf("error encoding changelog %q: %w", changeLog.Version, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO changelogs (game, mod_id, position, version, notes) VALUES (?, ?, ?, ?, ?)`,
+			game, mod.ModID, i, changeLog.Version, string(notes),
+		); err != nil {
+			return fmt.Errorf("error saving changelog %q: %w", changeLog.Version, err)
+		}
+	}
+
+	for _, tag := range mod.Tags {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO tags (game, mod_id, tag) VALUES (?, ?, ?)`, game, mod.ModID, tag); err != nil {
+			return fmt.Errorf("error saving tag %q: %w", tag, err)
+		}
+	}
+
+	if err := insertRequirements(tx, game, mod.ModID, dependencyKind, mod.Dependencies); err != nil {
+		return err
+	}
+	if err := insertRequirements(tx, game, mod.ModID, modsUsingKind, mod.ModsUsing); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`INSERT OR REPLACE INTO scrape_history (game, mod_id, last_checked, latest_version, file_count) VALUES (?, ?, ?, ?, ?)`,
+		game, mod.ModID, checked, mod.LatestVersion, len(mod.Files),
+	); err != nil {
+		return fmt.Errorf("error saving scrape history for mod %d: %w", mod.ModID, err)
+	}
+
+	return tx.Commit()
+}
+
+// insertRequirements writes the requirements of the given kind for a mod, keeping
+// their scraped order in the position column.
+func insertRequirements(tx *sql.Tx, game string, modID int64, kind string, requirements []types.Requirement) error {
+	for i, requirement := range requirements {
+		if _, err := tx.Exec(`INSERT INTO requirements (game, mod_id, kind, position, name, notes) VALUES (?, ?, ?, ?, ?, ?)`,
+			game, modID, kind, i, requirement.Name, requirement.Notes,
+		); err != nil {
+			return fmt.Errorf("error saving requirement %q: %w", requirement.Name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package stores
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMod(checked time.Time) types.ModInfo {
+	return types.ModInfo{
+		ModID:         1234,
+		Name:          "Test Mod",
+		Creator:       "Creator",
+		LatestVersion: "1.1",
+		LastChecked:   checked,
+		Tags:          []string{"Tag1", "Tag2"},
+		ChangeLogs: []types.ChangeLog{
+			{Version: "1.1", Notes: []string{"Fixed things", "Added things"}},
+			{Version: "1.0", Notes: []string{"Initial release"}},
+		},
+		Dependencies: []types.Requirement{{Name: "Dep Mod", Notes: "Required"}},
+		ModsUsing:    []types.Requirement{{Name: "User Mod"}},
+		Files: []types.File{
+			{Name: "Main File", Version: "1.1", FileSize: "10MB"},
+			{Name: "Old File", Version: "1.0", FileSize: "9MB"},
+		},
+	}
+}
+
+func countRows(t *testing.T, path, query string, args ...interface{}) int {
+	db, err := OpenSqlite(path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var count int
+	require.NoError(t, db.QueryRow(query, args...).Scan(&count))
+	return count
+}
+
+func TestSaveModInfoToSqlite_Success(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "nested", "mods.db")
+	mod := testMod(time.Now())
+
+	// Act
+	returnedPath, err := SaveModInfoToSqlite(path, "SkyrimSpecialEdition", types.Results{Mods: mod}, func(dir string) error { return os.MkdirAll(dir, os.ModePerm) })
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, path, returnedPath)
+	assert.Equal(t, 1, countRows(t, path, "SELECT COUNT(*) FROM mods WHERE game = ? AND mod_id = ?", "skyrimspecialedition", 1234))
+	assert.Equal(t, 2, countRows(t, path, "SELECT COUNT(*) FROM files"))
+	assert.Equal(t, 2, countRows(t, path, "SELECT COUNT(*) FROM changelogs"))
+	assert.Equal(t, 2, countRows(t, path, "SELECT COUNT(*) FROM tags"))
+	assert.Equal(t, 1, countRows(t, path, "SELECT COUNT(*) FROM requirements WHERE kind = ?", dependencyKind))
+	assert.Equal(t, 1, countRows(t, path, "SELECT COUNT(*) FROM requirements WHERE kind = ?", modsUsingKind))
+	assert.Equal(t, 1, countRows(t, path, "SELECT COUNT(*) FROM scrape_history"))
+}
+
+func TestSaveModInfoToSqlite_UpsertsAndKeepsHistory(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "mods.db")
+	first := testMod(time.Now().Add(-time.Hour))
+	second := testMod(time.Now())
+	second.Name = "Renamed Mod"
+	second.LatestVersion = "1.2"
+	second.Files = second.Files[:1]
+	second.Tags = []string{"Tag3"}
+
+	// Act
+	_, err := SaveModInfoToSqlite(path, "game", types.Results{Mods: first}, func(string) error { return nil })
+	require.NoError(t, err)
+	_, err = SaveModInfoToSqlite(path, "game", types.Results{Mods: second}, func(string) error { return nil })
+	require.NoError(t, err)
+
+	// Assert
+	db, err := OpenSqlite(path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var name, version string
+	require.NoError(t, db.QueryRow("SELECT name, latest_version FROM mods WHERE game = 'game' AND mod_id = 1234").Scan(&name, &version))
+	assert.Equal(t, "Renamed Mod", name)
+	assert.Equal(t, "1.2", version)
+	assert.Equal(t, 1, countRows(t, path, "SELECT COUNT(*) FROM mods"))
+	assert.Equal(t, 1, countRows(t, path, "SELECT COUNT(*) FROM files"))
+	assert.Equal(t, 1, countRows(t, path, "SELECT COUNT(*) FROM tags WHERE tag = 'Tag3'"))
+	assert.Equal(t, 2, countRows(t, path, "SELECT COUNT(*) FROM scrape_history"))
+}
+
+func TestSaveModInfoToSqlite_EnsureDirExistsError(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "mods.db")
+
+	// Act
+	_, err := SaveModInfoToSqlite(path, "game", types.Results{}, func(string) error { return errors.New("directory error") })
+
+	// Assert
+	assert.EqualError(t, err, "directory error")
+}
+
+func TestLoadModInfoFromSqlite(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "mods.db")
+	_, err := SaveModInfoToSqlite(path, "SkyrimSpecialEdition", types.Results{Mods: testMod(time.Now())}, func(string) error { return nil })
+	require.NoError(t, err)
+
+	// Act
+	mod, ok, err := LoadModInfoFromSqlite(path, "skyrimspecialedition", 1234)
+	_, missingOk, missingErr := LoadModInfoFromSqlite(path, "skyrimspecialedition", 1)
+	_, noDbOk, noDbErr := LoadModInfoFromSqlite(filepath.Join(t.TempDir(), "missing.db"), "skyrimspecialedition", 1234)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "Test Mod", mod.Name)
+	assert.Equal(t, "1.1", mod.LatestVersion)
+	assert.Equal(t, testMod(time.Time{}).ChangeLogs, mod.ChangeLogs)
+	assert.NoError(t, missingErr)
+	assert.False(t, missingOk)
+	assert.NoError(t, noDbErr)
+	assert.False(t, noDbOk)
+}
+
+func TestLoadModInfoFromSqlite_KeepsEveryChangeLog(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "mods.db")
+	mod := testMod(time.Now())
+	mod.ChangeLogs = []types.ChangeLog{
+		{Version: "1.2", Notes: []string{}},
+		{Version: "1.1", Notes: []string{"Hotfix"}},
+		{Version: "1.1", Notes: []string{"Fixed things", "Added things"}},
+	}
+	_, err := SaveModInfoToSqlite(path, "game", types.Results{Mods: mod}, func(string) error { return nil })
+	require.NoError(t, err)
+
+	// Act
+	loaded, ok, err := LoadModInfoFromSqlite(path, "game", 1234)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, mod.ChangeLogs, loaded.ChangeLogs)
+}
@@ -0,0 +1,46 @@
+package stores
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// JsonStore is the default store kind, saving each mod to its own JSON file.
+	JsonStore string = "json"
+	// SqliteStore is the store kind that saves mods into a SQLite database.
+	SqliteStore string = "sqlite"
+)
+
+// StoreSpec describes where scraped results should be persisted, made up of the
+// store kind and, for stores that need one, the location to write to.
+type StoreSpec struct {
+	Kind string
+	Path string
+}
+
+// ParseStoreSpec parses a store specification of the form "<kind>[:<path>]", for
+// example "json" or "sqlite:/path/to/mods.db". An empty spec resolves to the JSON
+// store. Returns an error if the kind is unknown or a required path is missing.
+func ParseStoreSpec(spec string) (StoreSpec, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return StoreSpec{Kind: JsonStore}, nil
+	}
+
+	kind, path, _ := strings.Cut(spec, ":")
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	path = strings.TrimSpace(path)
+
+	switch kind {
+	case JsonStore:
+		return StoreSpec{Kind: JsonStore, Path: path}, nil
+	case SqliteStore:
+		if path == "" {
+			return StoreSpec{}, fmt.Errorf("store %q requires a database path, e.g. sqlite:mods.db", kind)
+		}
+		return StoreSpec{Kind: SqliteStore, Path: path}, nil
+	default:
+		return StoreSpec{}, fmt.Errorf("unknown store %q, expected one of: %s, %s", kind, JsonStore, SqliteStore)
+	}
+}
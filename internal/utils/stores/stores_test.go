@@ -0,0 +1,42 @@
+package stores
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStoreSpec_DefaultsToJson(t *testing.T) {
+	// Act
+	spec, err := ParseStoreSpec("")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, StoreSpec{Kind: JsonStore}, spec)
+}
+
+func TestParseStoreSpec_Sqlite(t *testing.T) {
+	// Act
+	spec, err := ParseStoreSpec("SQLite:/tmp/mods.db")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, StoreSpec{Kind: SqliteStore, Path: "/tmp/mods.db"}, spec)
+}
+
+func TestParseStoreSpec_SqliteMissingPath(t *testing.T) {
+	// Act
+	_, err := ParseStoreSpec("sqlite")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "requires a database path")
+}
+
+func TestParseStoreSpec_UnknownKind(t *testing.T) {
+	// Act
+	_, err := ParseStoreSpec("postgres:somewhere")
+
+	// Assert
+	assert.EqualError(t, err, `unknown store "postgres", expected one of: json, sqlite`)
+}
@@ -0,0 +1,221 @@
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+)
+
+// examples holds the built-in example templates, named "<name>.<extension>.tmpl".
+//
+//go:embed examples/*.tmpl
+var examples embed.FS
+
+// Template is a parsed output template along with the file extension used when
+// its rendered output is saved.
+type Template struct {
+	Name      string
+	Extension string
+	tmpl      *template.Template
+}
+
+// FuncMap returns the helper functions available to every template, covering
+// humanised file sizes, date formatting, Markdown escaping and joining tags.
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"humanizeSize":   humanizeSize,
+		"formatDate":     formatDate,
+		"escapeMarkdown": formatters.EscapeMarkdown,
+		"joinTags":       joinTags,
+		"join":           strings.Join,
+		"lower":          strings.ToLower,
+		"upper":          strings.ToUpper,
+		"trim":           strings.TrimSpace,
+		"truncate":       truncate,
+		"default":        defaultValue,
+	}
+}
+
+// Examples returns the sorted names of the embedded example templates.
+func Examples() []string {
+	filenames := exampleFiles()
+	names := make([]string, 0, len(filenames))
+	for _, filename := range filenames {
+		name, _ := splitTemplateName(filename)
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// ExampleSource returns the source of the embedded example template with the given
+// name. Returns an error if no example has that name.
+func ExampleSource(name string) (string, error) {
+	filename, ok := exampleFile(name)
+	if !ok {
+		return "", fmt.Errorf("unknown example template %q, expected one of: %s", name, strings.Join(Examples(), ", "))
+	}
+
+	data, err := examples.ReadFile("examples/" + filename)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// Load parses the template at the given path, or the embedded example with that
+// name when no such file exists. Returns an error if the template cannot be read
+// or fails to parse.
+func Load(nameOrPath string) (*Template, error) {
+	data, err := os.ReadFile(nameOrPath)
+	if err == nil {
+		return Parse(filepath.Base(nameOrPath), string(data))
+	}
+
+	filename, ok := exampleFile(nameOrPath)
+	if !ok {
+		return nil, fmt.Errorf("error reading template: %s - %w", nameOrPath, err)
+	}
+
+	source, err := ExampleSource(nameOrPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(filename, source)
+}
+
+// Parse parses template source under the given file name, which also decides the
+// extension used when saving: "card.html.tmpl" and "card.html" save as "html",
+// while "card.tmpl" saves as "txt". Returns an error if the template fails to parse.
+func Parse(filename, source string) (*Template, error) {
+	name, ext := splitTemplateName(filename)
+
+	tmpl, err := template.New(name).Funcs(FuncMap()).Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template: %s - %w", filename, err)
+	}
+
+	return &Template{Name: name, Extension: ext, tmpl: tmpl}, nil
+}
+
+// Render executes the template against the results and returns the output.
+// Returns an error if execution fails.
+func (t *Template) Render(results types.Results) (string, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, results); err != nil {
+		return "", fmt.Errorf("error rendering template: %s - %w", t.Name, err)
+	}
+	return buf.String(), nil
+}
+
+// exampleFiles returns the file names of the embedded examples.
+func exampleFiles() []string {
+	entries, _ := fs.ReadDir(examples, "examples")
+
+	filenames := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		filenames = append(filenames, entry.Name())
+	}
+	return filenames
+}
+
+// exampleFile returns the file name of the embedded example with the given name.
+func exampleFile(name string) (string, bool) {
+	for _, filename := range exampleFiles() {
+		if exampleName, _ := splitTemplateName(filename); exampleName == name {
+			return filename, true
+		}
+	}
+	return "", false
+}
+
+// splitTemplateName splits a template file name into its base name and the
+// extension of the output it produces.
+func splitTemplateName(filename string) (string, string) {
+	name := strings.TrimSuffix(filepath.Base(filename), ".tmpl")
+	ext := strings.TrimPrefix(filepath.Ext(name), ".")
+	if ext == "" {
+		return name, "txt"
+	}
+	return strings.TrimSuffix(name, "."+ext), ext
+}
+
+// humanizeSize formats a file size, either a byte count or a Nexus Mods size such
+// as "12.5MB", using binary units. Unparseable sizes are returned unchanged.
+func humanizeSize(size interface{}) string {
+	switch v := size.(type) {
+	case int:
+		return formatters.HumanizeBytes(int64(v))
+	case int64:
+		return formatters.HumanizeBytes(v)
+	case string:
+		bytes, err := formatters.ParseFileSize(v)
+		if err != nil {
+			return v
+		}
+		return formatters.HumanizeBytes(bytes)
+	default:
+		return fmt.Sprint(size)
+	}
+}
+
+// formatDate formats a time or a Nexus Mods date string with the given Go layout.
+// Unparseable dates are returned unchanged.
+func formatDate(layout string, value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(layout)
+	case string:
+		parsed, err := formatters.ParseNexusDate(v)
+		if err != nil {
+			return v
+		}
+		return parsed.Format(layout)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// joinTags joins tags with the separator, skipping empty tags.
+func joinTags(sep string, tags []string) string {
+	kept := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			kept = append(kept, tag)
+		}
+	}
+	return strings.Join(kept, sep)
+}
+
+// truncate shortens s to at most n runes, adding an ellipsis when it is cut.
+func truncate(n int, s string) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
+
+// defaultValue returns fallback when value is empty.
+func defaultValue(fallback, value string) string {
+	if strings.TrimSpace(value) == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,157 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var templateResults = types.Results{
+	Mods: types.ModInfo{
+		ModID:         42,
+		Name:          "Better_Things",
+		Creator:       "Creator",
+		LatestVersion: "1.2",
+		LastUpdated:   "23 Oct 2024, 2:18PM",
+		Url:           "https://nexusmods.com/game/mods/42",
+		Tags:          []string{"Armour", " ", "Weapons"},
+		ChangeLogs:    []types.ChangeLog{{Version: "1.2", Notes: []string{"Fixed *bold* bug"}}},
+		Files:         []types.File{{Name: "Main", Version: "1.2", FileSize: "1536KB"}},
+	},
+}
+
+func TestParse_RendersHelpers(t *testing.T) {
+	// Arrange
+	source := `{{ escapeMarkdown .Mods.Name }}|{{ humanizeSize (index .Mods.Files 0).FileSize }}|{{ formatDate "2006-01-02" .Mods.LastUpdated }}|{{ joinTags ", " .Mods.Tags }}|{{ truncate 4 .Mods.Creator }}|{{ default "none" .Mods.Uploader }}`
+	tmpl, err := Parse("post.tmpl", source)
+	require.NoError(t, err)
+
+	// Act
+	out, err := tmpl.Render(templateResults)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, `Better\_Things|1.5 MB|2024-10-23|Armour, Weapons|Crea…|none`, out)
+	assert.Equal(t, "post", tmpl.Name)
+	assert.Equal(t, "txt", tmpl.Extension)
+}
+
+func TestParse_Error(t *testing.T) {
+	// Act
+	_, err := Parse("broken.tmpl", "{{ .Mods.Name")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "error parsing template: broken.tmpl")
+}
+
+func TestRender_Error(t *testing.T) {
+	// Arrange
+	tmpl, err := Parse("missing.tmpl", "{{ .Mods.Missing }}")
+	require.NoError(t, err)
+
+	// Act
+	_, err = tmpl.Render(templateResults)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "error rendering template: missing")
+}
+
+func TestLoad_File(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "card.html.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte("<b>{{ html .Mods.Name }}</b>"), 0644))
+
+	// Act
+	tmpl, err := Load(path)
+	require.NoError(t, err)
+	out, err := tmpl.Render(templateResults)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "html", tmpl.Extension)
+	assert.Equal(t, "<b>Better_Things</b>", out)
+}
+
+func TestLoad_Missing(t *testing.T) {
+	// Act
+	_, err := Load(filepath.Join(t.TempDir(), "missing.tmpl"))
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "error reading template")
+}
+
+func TestExamples_RenderAll(t *testing.T) {
+	// Assert
+	assert.Equal(t, []string{"bbcode", "html-card", "markdown-changelog"}, Examples())
+
+	expected := map[string]struct {
+		extension string
+		contains  string
+	}{
+		"bbcode":             {"bbcode", "[*]Main v1.2 (1.5 MB)"},
+		"html-card":          {"html", `<dd>2024-10-23</dd>`},
+		"markdown-changelog": {"md", `- Fixed \*bold\* bug`},
+	}
+
+	for name, want := range expected {
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := Load(name)
+			require.NoError(t, err)
+
+			out, err := tmpl.Render(templateResults)
+			assert.NoError(t, err)
+			assert.Equal(t, want.extension, tmpl.Extension)
+			assert.Contains(t, out, want.contains)
+		})
+	}
+}
+
+func TestExamples_HtmlCardEscapesUnparsedFields(t *testing.T) {
+	// Arrange
+	tmpl, err := Load("html-card")
+	require.NoError(t, err)
+	results := types.Results{Mods: types.ModInfo{
+		LastUpdated: "<script>date</script>",
+		Files:       []types.File{{Name: "Main", FileSize: "<b>size</b>"}},
+	}}
+
+	// Act
+	out, err := tmpl.Render(results)
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotContains(t, out, "<script>")
+	assert.NotContains(t, out, "<b>")
+	assert.Contains(t, out, "&lt;script&gt;date&lt;/script&gt;")
+	assert.Contains(t, out, "&lt;b&gt;size&lt;/b&gt;")
+}
+
+func TestExampleSource_Unknown(t *testing.T) {
+	// Act
+	_, err := ExampleSource("wiki")
+
+	// Assert
+	assert.EqualError(t, err, `unknown example template "wiki", expected one of: bbcode, html-card, markdown-changelog`)
+}
+
+func TestFormatDate_Time(t *testing.T) {
+	// Assert
+	assert.Equal(t, "2024-01-02", formatDate("2006-01-02", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "", formatDate("2006-01-02", time.Time{}))
+	assert.Equal(t, "someday", formatDate("2006-01-02", "someday"))
+}
+
+func TestHumanizeSize_Types(t *testing.T) {
+	// Assert
+	assert.Equal(t, "2.0 KB", humanizeSize(2048))
+	assert.Equal(t, "1.0 KB", humanizeSize(int64(1024)))
+	assert.Equal(t, "big", humanizeSize("big"))
+}
@@ -0,0 +1,41 @@
+package terminal
+
+import (
+	"io"
+	"os"
+
+	"github.com/savioxavier/termlink"
+	"golang.org/x/term"
+)
+
+// NoColorEnv is the environment variable that turns colors off when set to any
+// value, see https://no-color.org.
+const NoColorEnv = "NO_COLOR"
+
+// isTerminal is a variable that holds a reference to the function reporting whether
+// a file descriptor is a terminal.
+var isTerminal = term.IsTerminal
+
+// IsTerminal reports whether w is a terminal, false for pipes, files and buffers.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && isTerminal(int(f.Fd()))
+}
+
+// Color reports whether output written to w is colored: w is a terminal, and colors
+// are not turned off with noColor or the NO_COLOR environment variable.
+func Color(w io.Writer, noColor bool) bool {
+	if noColor || os.Getenv(NoColorEnv) != "" {
+		return false
+	}
+	return IsTerminal(w)
+}
+
+// Link returns the path as a green, clickable link when color is set, and as is
+// otherwise so piped output holds no escape codes.
+func Link(path string, color bool) string {
+	if !color {
+		return path
+	}
+	return termlink.ColorLink(path, path, "green")
+}
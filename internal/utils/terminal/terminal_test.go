@@ -0,0 +1,63 @@
+package terminal
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// useTerminal makes every file a terminal, or none, for the test.
+func useTerminal(t *testing.T, terminal bool) {
+	t.Helper()
+	original := isTerminal
+	t.Cleanup(func() { isTerminal = original })
+	isTerminal = func(int) bool { return terminal }
+}
+
+func TestIsTerminal(t *testing.T) {
+	// Arrange
+	useTerminal(t, true)
+
+	// Act & Assert
+	assert.True(t, IsTerminal(os.Stdout))
+	assert.False(t, IsTerminal(&bytes.Buffer{}), "only files can be terminals")
+}
+
+func TestColor(t *testing.T) {
+	tests := map[string]struct {
+		terminal bool
+		noColor  bool
+		env      string
+		expected bool
+	}{
+		"terminal":              {terminal: true, expected: true},
+		"not a terminal":        {terminal: false},
+		"no-color flag":         {terminal: true, noColor: true},
+		"NO_COLOR env":          {terminal: true, env: "1"},
+		"NO_COLOR env is empty": {terminal: true, env: "", expected: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			useTerminal(t, tt.terminal)
+			t.Setenv(NoColorEnv, tt.env)
+
+			// Act & Assert
+			assert.Equal(t, tt.expected, Color(os.Stdout, tt.noColor))
+		})
+	}
+}
+
+func TestLink(t *testing.T) {
+	// Act
+	plain := Link("/mods/skyrim/mod 1.json", false)
+	colored := Link("/mods/skyrim/mod 1.json", true)
+
+	// Assert
+	assert.Equal(t, "/mods/skyrim/mod 1.json", plain)
+	assert.Contains(t, colored, "\u001b[")
+	assert.Contains(t, colored, "/mods/skyrim/mod 1.json")
+}
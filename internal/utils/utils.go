@@ -1,37 +1,61 @@
 package utils
 
 import (
+	"context"
+	"fmt"
 	"os"
-	"sync"
-)
 
-// ConcurrentFetch runs multiple tasks concurrently, returning the first error
-// encountered. If all tasks succeed, it returns nil.
-func ConcurrentFetch(tasks ...func() error) error {
-	var wg sync.WaitGroup
-	errChan := make(chan error, len(tasks))
-
-	for _, task := range tasks {
-		wg.Add(1)
-		go func(t func() error) {
-			defer wg.Done()
-			if err := t(); err != nil {
-				errChan <- err
-			}
-		}(task)
-	}
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/scheduler"
+)
 
-	wg.Wait()
-	close(errChan)
+// FetchOptions configures the pool of workers a fetch function from
+// NewConcurrentFetch runs its tasks on.
+type FetchOptions struct {
+	// Concurrency is the number of tasks run at once, defaulting to 4.
+	Concurrency int
+	// PerHost limits the tasks run at once for Host, 0 means no limit.
+	PerHost int
+	// Host is the host the tasks request.
+	Host string
+	// Labels names the tasks by position in errors and progress reports, tasks
+	// without a label are named by their position.
+	Labels []string
+}
 
-	// Return the first error encountered, if any
-	for err := range errChan {
-		if err != nil {
-			return err
+// NewConcurrentFetch returns a function running tasks concurrently on a pool sized by
+// options, with a context that is cancelled as soon as one of them fails so the
+// others can stop early. Earlier tasks are given a higher priority, so a mod's page
+// is requested before its tabs when the pool is smaller than the tasks. It waits for
+// every task and returns scheduler.Errors holding each failed task with its label,
+// or ctx's error when it was cancelled first. If all tasks succeed, it returns nil.
+func NewConcurrentFetch(options FetchOptions) func(ctx context.Context, tasks ...func(ctx context.Context) error) error {
+	return func(ctx context.Context, tasks ...func(ctx context.Context) error) error {
+		scheduled := make([]scheduler.Task, len(tasks))
+		for i, task := range tasks {
+			label := fmt.Sprintf("task %d", i+1)
+			if i < len(options.Labels) {
+				label = options.Labels[i]
+			}
+			scheduled[i] = scheduler.Task{
+				Label:    label,
+				Host:     options.Host,
+				Priority: len(tasks) - i,
+				Run:      task,
+			}
 		}
+
+		return scheduler.New(scheduler.Options{
+			Workers:  options.Concurrency,
+			PerHost:  options.PerHost,
+			FailFast: true,
+		}).Run(ctx, scheduled...)
 	}
+}
 
-	return nil
+// ConcurrentFetch runs tasks like a fetch function from NewConcurrentFetch with the
+// default options.
+func ConcurrentFetch(ctx context.Context, tasks ...func(ctx context.Context) error) error {
+	return NewConcurrentFetch(FetchOptions{})(ctx, tasks...)
 }
 
 // EnsureDirExists checks if a directory exists at the given path and creates it
@@ -1,22 +1,27 @@
 package utils
 
 import (
+	"context"
 	"errors"
 	"os"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/scheduler"
 )
 
 func TestConcurrentFetch_FirstTaskFails(t *testing.T) {
 	// Arrange
 	expectedErr := errors.New("task1 failed")
-	task1 := func() error { return expectedErr }
-	task2 := func() error { return nil }
+	task1 := func(context.Context) error { return expectedErr }
+	task2 := func(context.Context) error { return nil }
 
 	// Act
-	err := ConcurrentFetch(task1, task2)
+	err := ConcurrentFetch(context.Background(), task1, task2)
 
 	// Assert
-	if err != expectedErr {
+	if !errors.Is(err, expectedErr) {
 		t.Errorf("Expected error %v, got %v", expectedErr, err)
 	}
 }
@@ -24,14 +29,14 @@ func TestConcurrentFetch_FirstTaskFails(t *testing.T) {
 func TestConcurrentFetch_SecondTaskFails(t *testing.T) {
 	// Arrange
 	expectedErr := errors.New("task2 failed")
-	task1 := func() error { return nil }
-	task2 := func() error { return expectedErr }
+	task1 := func(context.Context) error { return nil }
+	task2 := func(context.Context) error { return expectedErr }
 
 	// Act
-	err := ConcurrentFetch(task1, task2)
+	err := ConcurrentFetch(context.Background(), task1, task2)
 
 	// Assert
-	if err != expectedErr {
+	if !errors.Is(err, expectedErr) {
 		t.Errorf("Expected error %v, got %v", expectedErr, err)
 	}
 }
@@ -40,15 +45,125 @@ func TestConcurrentFetch_MultipleTasksFail(t *testing.T) {
 	// Arrange
 	task1Err := errors.New("task1 failed")
 	task2Err := errors.New("task2 failed")
-	task1 := func() error { return task1Err }
-	task2 := func() error { return task2Err }
+
+	// Both tasks are running before either fails, so neither is cancelled unstarted
+	var started sync.WaitGroup
+	started.Add(2)
+	task1 := func(context.Context) error {
+		started.Done()
+		started.Wait()
+		return task1Err
+	}
+	task2 := func(context.Context) error {
+		started.Done()
+		started.Wait()
+		return task2Err
+	}
+
+	// Act
+	err := ConcurrentFetch(context.Background(), task1, task2)
+
+	// Assert
+	if !errors.Is(err, task1Err) || !errors.Is(err, task2Err) {
+		t.Errorf("Expected error to join %v and %v, got %v", task1Err, task2Err, err)
+	}
+}
+
+func TestNewConcurrentFetch_Concurrency(t *testing.T) {
+	// Arrange
+	var order []string
+	record := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			order = append(order, name)
+			return nil
+		}
+	}
+	fetch := NewConcurrentFetch(FetchOptions{Concurrency: 1, PerHost: 1, Host: "nexusmods.com"})
+
+	// Act
+	err := fetch(context.Background(), record("mod page"), record("files tab"))
+
+	// Assert
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(order) != 2 || order[0] != "mod page" || order[1] != "files tab" {
+		t.Errorf("Expected the mod page before the files tab, got %v", order)
+	}
+}
+
+func TestNewConcurrentFetch_LabelsErrors(t *testing.T) {
+	// Arrange
+	filesErr := errors.New("files failed")
+	fetch := NewConcurrentFetch(FetchOptions{Labels: []string{"mod page", "files tab"}})
+
+	// Act
+	err := fetch(context.Background(),
+		func(context.Context) error { return nil },
+		func(context.Context) error { return filesErr },
+	)
+
+	// Assert
+	var errs scheduler.Errors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Label != "files tab" {
+		t.Fatalf("Expected the files tab failure, got %v", err)
+	}
+	if err.Error() != "files tab: files failed" || !errors.Is(err, filesErr) {
+		t.Errorf("Expected the labelled files tab error, got %v", err)
+	}
+}
+
+func TestConcurrentFetch_RecoversPanic(t *testing.T) {
+	// Arrange
+	task := func(context.Context) error { panic("boom") }
+
+	// Act
+	err := ConcurrentFetch(context.Background(), task)
+
+	// Assert
+	if err == nil || err.Error() != "task 1: task panicked: boom" {
+		t.Errorf("Expected the panic as an error, got %v", err)
+	}
+}
+
+func TestConcurrentFetch_FailureCancelsOthers(t *testing.T) {
+	// Arrange
+	expectedErr := errors.New("task1 failed")
+	task1 := func(context.Context) error { return expectedErr }
+	task2 := func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+			return nil
+		}
+	}
+	start := time.Now()
+
+	// Act
+	err := ConcurrentFetch(context.Background(), task1, task2)
+
+	// Assert
+	if !errors.Is(err, expectedErr) {
+		t.Errorf("Expected error %v, got %v", expectedErr, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the waiting task to be cancelled, took %v", elapsed)
+	}
+}
+
+func TestConcurrentFetch_ParentCancelled(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	task := func(ctx context.Context) error { return ctx.Err() }
 
 	// Act
-	err := ConcurrentFetch(task1, task2)
+	err := ConcurrentFetch(ctx, task)
 
 	// Assert
-	if err != task1Err && err != task2Err {
-		t.Errorf("Expected error to be either %v or %v, got %v", task1Err, task2Err, err)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
 	}
 }
 
@@ -2,10 +2,12 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"runtime"
 
 	sCli "github.com/ondrovic/common/utils/cli"
 	"github.com/ondrovic/nexus-mods-scraper/cmd/cli"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/terminal"
 )
 
 type clearScreenFunc func(interface{}) error
@@ -28,6 +30,15 @@ func executeMain(clearScreen clearScreenFunc, executeFunc func() error) {
 	}
 }
 
+// interactiveClearScreen returns clearScreen when stdout is a terminal, and otherwise
+// a function that leaves the output alone, so piped output holds no escape codes.
+func interactiveClearScreen(clearScreen clearScreenFunc, interactive bool) clearScreenFunc {
+	if interactive {
+		return clearScreen
+	}
+	return func(interface{}) error { return nil }
+}
+
 func main() {
-	executeMain(sCli.ClearTerminalScreen, cli.RootCmd.Execute)
+	executeMain(interactiveClearScreen(sCli.ClearTerminalScreen, terminal.IsTerminal(os.Stdout)), cli.Execute)
 }
@@ -59,3 +59,19 @@ func TestExecuteMain_FailureOnExecute(t *testing.T) {
 	// No panics/errors should occur, and the execution error should be gracefully handled
 	assert.True(t, true, "executeMain should handle the execution error gracefully")
 }
+
+func TestInteractiveClearScreen(t *testing.T) {
+	// Arrange
+	var cleared int
+	clearScreen := func(_ interface{}) error {
+		cleared++
+		return nil
+	}
+
+	// Act
+	assert.NoError(t, interactiveClearScreen(clearScreen, false)("linux"))
+	assert.NoError(t, interactiveClearScreen(clearScreen, true)("linux"))
+
+	// Assert
+	assert.Equal(t, 1, cleared)
+}
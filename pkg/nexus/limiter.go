@@ -0,0 +1,55 @@
+package nexus
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// limiter spaces requests at least interval apart across every goroutine sharing it.
+type limiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// newLimiter creates a limiter, an interval of 0 never waits.
+func newLimiter(interval time.Duration) *limiter {
+	return &limiter{interval: interval}
+}
+
+// Wait blocks until the next request may start, reserving its slot. Returns the
+// context's error if ctx is done first.
+func (l *limiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if l.interval <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	start := l.next
+	if start.Before(now) {
+		start = now
+	}
+	l.next = start.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(start)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
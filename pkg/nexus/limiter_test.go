@@ -0,0 +1,48 @@
+package nexus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_SpacesRequests(t *testing.T) {
+	// Arrange
+	l := newLimiter(20 * time.Millisecond)
+	start := time.Now()
+
+	// Act
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, l.Wait(context.Background()))
+	}
+
+	// Assert
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
+
+func TestLimiter_NoInterval(t *testing.T) {
+	// Arrange
+	l := newLimiter(0)
+
+	// Act
+	err := l.Wait(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestLimiter_ContextDone(t *testing.T) {
+	// Arrange
+	l := newLimiter(time.Hour)
+	assert.NoError(t, l.Wait(context.Background()))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// Act
+	err := l.Wait(ctx)
+
+	// Assert
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,377 @@
+// Package nexus is a Go client for scraping mod information from Nexus Mods.
+//
+// A Scraper owns its HTTP client, session cookies, options, logger and rate
+// limiter, so several independent sessions can run in one process:
+//
+//	scraper, err := nexus.New(nexus.WithCookieFile(dir, "session-cookies.json"))
+//	if err != nil {
+//		return err
+//	}
+//	results, err := scraper.ScrapeMod(ctx, "skyrimspecialedition", 12345)
+package nexus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/browserutils/kooky"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/extractors"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/logging"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/scheduler"
+)
+
+type (
+	// Results holds a scraped mod under the "Mods" key, as saved by the CLI.
+	Results = types.Results
+	// ModInfo is the information scraped for a mod.
+	ModInfo = types.ModInfo
+	// File is a file listed on a mod's files tab.
+	File = types.File
+	// ChangeLog is the changelog for one version of a mod.
+	ChangeLog = types.ChangeLog
+	// Requirement is a mod that another mod requires or is used by.
+	Requirement = types.Requirement
+	// CookieStoreInfo describes a browser cookie store and the valid cookies it holds.
+	CookieStoreInfo = extractors.StoreInfo
+)
+
+const (
+	// DefaultBaseURL is the Nexus Mods site scraped when no base URL is set.
+	DefaultBaseURL = "https://nexusmods.com"
+)
+
+// DefaultValidCookies are the session cookie names extracted from browsers.
+var DefaultValidCookies = []string{"nexusmods_session", "nexusmods_session_refresh"}
+
+// DocumentFetcher fetches and parses the HTML page at a URL.
+type DocumentFetcher func(ctx context.Context, targetURL string) (*goquery.Document, error)
+
+// ModFetcher scrapes a mod's page and files tab using fetchDocument, running the
+// page fetches through concurrentFetch. It matches the scraping pipeline used by
+// the CLI, and is replaceable for tests and alternative sources.
+type ModFetcher func(ctx context.Context, baseUrl, game string, modId int64, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (Results, error)
+
+// Scraper scrapes mods from Nexus Mods with its own HTTP client and session
+// cookies. A Scraper is safe for concurrent use once created.
+type Scraper struct {
+	baseURL        string
+	client         *http.Client
+	cookies        []*http.Cookie
+	validCookies   []string
+	cookieStores   func() []kooky.CookieStore
+	browser        string
+	profile        string
+	logger         *slog.Logger
+	limiter        *limiter
+	concurrency    int
+	perHost        int
+	requestTimeout time.Duration
+	cookieDir      string
+	cookieFile     string
+	refreshSession bool
+	refreshPath    string
+	onRefresh      func(cookies []*http.Cookie) error
+	fetchDocument  DocumentFetcher
+	fetchMod       ModFetcher
+}
+
+// New creates a Scraper with the given options. Without options it scrapes
+// DefaultBaseURL with no session cookies and no rate limit. Returns an error if
+// an option is invalid, such as an unreadable cookie file. Requests have no
+// timeout unless one is set, but are always cancelled with their context.
+func New(opts ...Option) (*Scraper, error) {
+	s := &Scraper{
+		baseURL:      DefaultBaseURL,
+		validCookies: DefaultValidCookies,
+		cookieStores: kooky.FindAllCookieStores,
+		logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		limiter:      newLimiter(0),
+		fetchMod:     fetchers.FetchModInfoConcurrent,
+	}
+
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.client == nil {
+		s.client = &http.Client{}
+	}
+	if s.client.Jar == nil {
+		jar, err := httpclient.NewJar()
+		if err != nil {
+			return nil, err
+		}
+		s.client.Jar = jar
+	}
+	if len(s.cookies) > 0 {
+		if err := s.SetCookies(s.cookies); err != nil {
+			return nil, err
+		}
+	}
+	if s.refreshSession {
+		s.client = s.withSessionRefresh(s.client)
+	}
+	if s.fetchDocument == nil {
+		s.fetchDocument = func(ctx context.Context, targetURL string) (*goquery.Document, error) {
+			return fetchers.FetchDocumentWithClient(ctx, s.client, targetURL)
+		}
+	}
+
+	return s, nil
+}
+
+// BaseURL returns the Nexus Mods site the scraper fetches from.
+func (s *Scraper) BaseURL() string {
+	return s.baseURL
+}
+
+// Client returns the scraper's HTTP client, including its cookie jar.
+func (s *Scraper) Client() *http.Client {
+	return s.client
+}
+
+// Cookies returns the session cookies the scraper sends to the base URL.
+func (s *Scraper) Cookies() []*http.Cookie {
+	u, err := url.Parse(s.baseURL)
+	if err != nil {
+		return nil
+	}
+	return s.client.Jar.Cookies(u)
+}
+
+// StoredCookies returns every cookie in the scraper's jar with its domain, path,
+// expiry and flags, including those set by responses, for saving to a cookie file.
+// Cookies the site deleted are returned as expired. When the jar was given with
+// WithHTTPClient and does not keep attributes, the base URL's cookies are returned.
+func (s *Scraper) StoredCookies() []*http.Cookie {
+	if jar, ok := s.client.Jar.(*httpclient.Jar); ok {
+		return jar.All()
+	}
+
+	u, err := url.Parse(s.baseURL)
+	if err != nil {
+		return nil
+	}
+	cookies := s.client.Jar.Cookies(u)
+	for _, cookie := range cookies {
+		cookie.Domain = u.Hostname()
+	}
+	return cookies
+}
+
+// SaveCookies writes the scraper's cookies back to the cookie file loaded with
+// WithCookieFile, keeping cookies set by the site during the run and pruning
+// expired ones. Returns an error if no cookie file was loaded or it cannot be written.
+func (s *Scraper) SaveCookies() error {
+	if s.cookieFile == "" {
+		return errors.New("no cookie file to save to, load one with WithCookieFile")
+	}
+	if err := httpclient.SaveCookies(s.cookieDir, s.cookieFile, s.StoredCookies()); err != nil {
+		return err
+	}
+	s.logger.Info("saved cookies", "path", filepath.Join(s.cookieDir, s.cookieFile))
+	return nil
+}
+
+// SetCookies adds session cookies for the base URL to the scraper's cookie jar.
+// Returns an error if the base URL is invalid.
+func (s *Scraper) SetCookies(cookies []*http.Cookie) error {
+	u, err := url.Parse(s.baseURL)
+	if err != nil {
+		return fmt.Errorf("error parsing domain: %w", err)
+	}
+	s.client.Jar.SetCookies(u, cookies)
+	return nil
+}
+
+// ScrapeMod scrapes the mod's page and files tab for the game. Returns an error if
+// a page cannot be fetched, the session cookies do not allow viewing the mod, or
+// ctx is cancelled. Fields that could not be extracted are logged as warnings.
+func (s *Scraper) ScrapeMod(ctx context.Context, game string, modID int64) (Results, error) {
+	s.logger.Debug("scraping mod", "game", game, "mod_id", modID)
+	ctx = logging.NewContext(ctx, s.logger)
+
+	concurrentFetch := utils.NewConcurrentFetch(utils.FetchOptions{
+		Concurrency: s.concurrency,
+		PerHost:     s.perHost,
+		Host:        scheduler.HostOf(s.baseURL),
+		Labels:      fetchers.ModTaskLabels,
+	})
+	results, err := s.fetchMod(ctx, s.baseURL, game, modID, concurrentFetch, s.fetch)
+	if err != nil {
+		s.logger.Warn("scraping mod failed", "game", game, "mod_id", modID, "error", err)
+		return Results{}, err
+	}
+
+	return results, nil
+}
+
+// ScrapeFiles scrapes only the files tab of the mod for the game. Returns an error
+// if the page cannot be fetched or ctx is cancelled.
+func (s *Scraper) ScrapeFiles(ctx context.Context, game string, modID int64) ([]File, error) {
+	filesTabURL := fmt.Sprintf("%s?tab=files", s.ModURL(game, modID))
+	s.logger.Debug("scraping files", "game", game, "mod_id", modID)
+
+	doc, err := s.fetch(ctx, filesTabURL)
+	if err != nil {
+		return nil, err
+	}
+
+	files := extractors.ExtractFileInfo(doc)
+	for _, warning := range extractors.CheckFileInfo(doc, files) {
+		s.logger.Warn("extraction warning", "url", filesTabURL, "field", warning.Field, "selector", warning.Selector, "reason", warning.Reason)
+	}
+	return files, nil
+}
+
+// ModURL returns the URL of the mod's page for the game.
+func (s *Scraper) ModURL(game string, modID int64) string {
+	return fmt.Sprintf("%s/%s/mods/%d", strings.TrimRight(s.baseURL, "/"), game, modID)
+}
+
+// ExtractCookies reads the scraper's valid session cookies for the base URL from
+// the local browsers' cookie stores and adds them to its cookie jar, keeping their
+// attributes for StoredCookies. Only the browser and profile chosen with WithBrowser
+// and WithProfile are read, and when several stores hold cookies they are all taken
+// from the store holding the newest one. Returns the extracted cookie names and
+// values, or an error if none are found.
+func (s *Scraper) ExtractCookies() (map[string]string, error) {
+	cookies, err := extractors.BrowserCookies(formatters.CookieDomain(s.baseURL), s.validCookies, s.selectedStores())
+	if err != nil {
+		if s.browser != "" || s.profile != "" {
+			return nil, fmt.Errorf("%w in %s", err, s.storeSelection())
+		}
+		return nil, err
+	}
+	if err := s.SetCookies(cookies); err != nil {
+		return nil, err
+	}
+
+	extracted := make(map[string]string, len(cookies))
+	for _, cookie := range cookies {
+		extracted[cookie.Name] = cookie.Value
+	}
+
+	s.logger.Debug("extracted cookies", "count", len(extracted))
+	return extracted, nil
+}
+
+// CookieStores lists the browser cookie stores ExtractCookies reads, limited to the
+// browser and profile chosen with WithBrowser and WithProfile, and the valid cookies
+// each holds for the base URL.
+func (s *Scraper) CookieStores() []CookieStoreInfo {
+	return extractors.ListStores(formatters.CookieDomain(s.baseURL), s.validCookies, s.selectedStores())
+}
+
+// selectedStores returns the store provider limited to the chosen browser and profile.
+func (s *Scraper) selectedStores() func() []kooky.CookieStore {
+	return extractors.FilterStores(s.cookieStores, s.browser, s.profile)
+}
+
+// storeSelection describes the chosen browser and profile for error messages.
+func (s *Scraper) storeSelection() string {
+	var selection []string
+	if s.browser != "" {
+		selection = append(selection, "browser "+s.browser)
+	}
+	if s.profile != "" {
+		selection = append(selection, "profile "+s.profile)
+	}
+	return strings.Join(selection, ", ")
+}
+
+// ImportCookies adds the scraper's valid session cookies for the base URL from
+// cookies, such as those read from a cookies.txt or HAR file, to its cookie jar, as
+// ExtractCookies does for the browsers' stores. Returns the imported cookie names
+// and values, or an error if none match.
+func (s *Scraper) ImportCookies(cookies []*http.Cookie) (map[string]string, error) {
+	domain := formatters.CookieDomain(s.baseURL)
+
+	var matching []*http.Cookie
+	for _, cookie := range cookies {
+		if cookie.Domain != "" && !strings.Contains(cookie.Domain, domain) {
+			continue
+		}
+		if slices.Contains(s.validCookies, cookie.Name) {
+			matching = append(matching, cookie)
+		}
+	}
+	if len(matching) == 0 {
+		return nil, errors.New("no matching cookies found")
+	}
+	if err := s.SetCookies(matching); err != nil {
+		return nil, err
+	}
+
+	imported := make(map[string]string, len(matching))
+	for _, cookie := range matching {
+		imported[cookie.Name] = cookie.Value
+	}
+
+	s.logger.Debug("imported cookies", "count", len(imported))
+	return imported, nil
+}
+
+// withSessionRefresh returns a copy of the client that renews the session when it
+// expires, leaving the client it was given unchanged. Without an onRefresh option,
+// the cookies are saved to the cookie file after each refresh.
+func (s *Scraper) withSessionRefresh(client *http.Client) *http.Client {
+	onRefresh := s.onRefresh
+	if onRefresh == nil && s.cookieFile != "" {
+		onRefresh = func([]*http.Cookie) error {
+			return s.SaveCookies()
+		}
+	}
+
+	var expires time.Time
+	for _, cookie := range s.cookies {
+		if cookie.Name == httpclient.SessionCookie {
+			expires = cookie.Expires
+			if expires.IsZero() {
+				expires = httpclient.TokenExpiry(cookie.Value)
+			}
+		}
+	}
+
+	refresher := httpclient.NewSessionRefresher(s.baseURL, client.Jar, client.Transport, expires, onRefresh)
+	refresher.RefreshPath = s.refreshPath
+
+	refreshing := *client
+	refreshing.Transport = refresher
+	return &refreshing
+}
+
+// fetch waits on the rate limiter and fetches the page at targetURL, cancelling the
+// request if it takes longer than the request timeout.
+func (s *Scraper) fetch(ctx context.Context, targetURL string) (*goquery.Document, error) {
+	if err := s.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	if s.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.requestTimeout)
+		defer cancel()
+	}
+
+	s.logger.Debug("fetching document", "url", targetURL)
+	return s.fetchDocument(ctx, targetURL)
+}
@@ -0,0 +1,349 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/browserutils/kooky"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const modPage = `<html><body><div id="pagetitle"><h1>Test Mod</h1></div></body></html>`
+
+const filesPage = `<html><body>
+<div class="file-expander-header"><p>Main File</p>
+<div class="stat-version"><div class="stat">1.2</div></div>
+<div class="stat-filesize"><div class="stat">10MB</div></div>
+</div></body></html>`
+
+// newTestSite serves a mod page and files tab that are only shown to the session
+// with the given cookie value, and counts the requests it receives.
+func newTestSite(t *testing.T, session string, requests *int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		cookie, err := r.Cookie("nexusmods_session")
+		if err != nil || cookie.Value != session {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Query().Get("tab") == "files" {
+			w.Write([]byte(filesPage))
+			return
+		}
+		w.Write([]byte(modPage))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestScraper_ScrapeMod(t *testing.T) {
+	// Arrange
+	var requests int
+	site := newTestSite(t, "abc", &requests)
+	scraper, err := New(WithBaseURL(site.URL), WithCookies(&http.Cookie{Name: "nexusmods_session", Value: "abc"}))
+	require.NoError(t, err)
+
+	// Act
+	results, err := scraper.ScrapeMod(context.Background(), "skyrim", 1234)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Test Mod", results.Mods.Name)
+	assert.Equal(t, int64(1234), results.Mods.ModID)
+	assert.Equal(t, "1.2", results.Mods.LatestVersion)
+	require.Len(t, results.Mods.Files, 1)
+	assert.Equal(t, 2, requests)
+}
+
+func TestScraper_IndependentSessions(t *testing.T) {
+	// Arrange
+	var requests int
+	site := newTestSite(t, "abc", &requests)
+	loggedIn, err := New(WithBaseURL(site.URL), WithCookies(&http.Cookie{Name: "nexusmods_session", Value: "abc"}))
+	require.NoError(t, err)
+	loggedOut, err := New(WithBaseURL(site.URL))
+	require.NoError(t, err)
+
+	// Act
+	_, loggedInErr := loggedIn.ScrapeFiles(context.Background(), "skyrim", 1234)
+	_, loggedOutErr := loggedOut.ScrapeFiles(context.Background(), "skyrim", 1234)
+
+	// Assert
+	assert.NoError(t, loggedInErr)
+	assert.Error(t, loggedOutErr)
+	assert.Contains(t, loggedOutErr.Error(), "returned 403")
+}
+
+func TestScraper_ScrapeFiles(t *testing.T) {
+	// Arrange
+	var requests int
+	site := newTestSite(t, "abc", &requests)
+	scraper, err := New(WithBaseURL(site.URL), WithCookies(&http.Cookie{Name: "nexusmods_session", Value: "abc"}))
+	require.NoError(t, err)
+
+	// Act
+	files, err := scraper.ScrapeFiles(context.Background(), "skyrim", 1234)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []File{{Name: "Main File", Version: "1.2", FileSize: "10MB"}}, files)
+	assert.Equal(t, 1, requests)
+}
+
+func TestScraper_CancelledContext(t *testing.T) {
+	// Arrange
+	fetched := false
+	scraper, err := New(WithDocumentFetcher(func(ctx context.Context, targetURL string) (*goquery.Document, error) {
+		fetched = true
+		return goquery.NewDocumentFromReader(strings.NewReader(filesPage))
+	}))
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	_, err = scraper.ScrapeFiles(ctx, "skyrim", 1234)
+
+	// Assert
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, fetched)
+}
+
+func TestScraper_RequestTimeout(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	scraper, err := New(WithBaseURL(server.URL), WithRequestTimeout(20*time.Millisecond))
+	require.NoError(t, err)
+
+	// Act
+	_, err = scraper.ScrapeMod(context.Background(), "skyrim", 1234)
+
+	// Assert
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestScraper_WithModFetcher(t *testing.T) {
+	// Arrange
+	scraper, err := New(WithModFetcher(func(ctx context.Context, baseUrl, game string, modId int64, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (Results, error) {
+		return Results{Mods: ModInfo{Name: game, ModID: modId}}, nil
+	}))
+	require.NoError(t, err)
+
+	// Act
+	results, err := scraper.ScrapeMod(context.Background(), "skyrim", 1)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, ModInfo{Name: "skyrim", ModID: 1}, results.Mods)
+}
+
+func TestScraper_WithConcurrency(t *testing.T) {
+	// Arrange
+	var order []string
+	scraper, err := New(WithConcurrency(1, 1), WithModFetcher(func(ctx context.Context, baseUrl, game string, modId int64, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (Results, error) {
+		record := func(name string) func(ctx context.Context) error {
+			return func(ctx context.Context) error {
+				order = append(order, name)
+				return nil
+			}
+		}
+		return Results{}, concurrentFetch(ctx, record("mod page"), record("files tab"))
+	}))
+	require.NoError(t, err)
+
+	// Act
+	_, err = scraper.ScrapeMod(context.Background(), "skyrim", 1)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mod page", "files tab"}, order)
+}
+
+func TestScraper_ModFetcherError(t *testing.T) {
+	// Arrange
+	scraper, err := New(WithModFetcher(func(ctx context.Context, baseUrl, game string, modId int64, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (Results, error) {
+		return Results{}, errors.New("adult content detected, cookies not working")
+	}))
+	require.NoError(t, err)
+
+	// Act
+	_, err = scraper.ScrapeMod(context.Background(), "skyrim", 1)
+
+	// Assert
+	assert.EqualError(t, err, "adult content detected, cookies not working")
+}
+
+func TestNew_WithCookieFile(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "session-cookies.json"), []byte(`{"nexusmods_session":"abc"}`), 0644))
+
+	// Act
+	scraper, err := New(WithCookieFile(dir, "session-cookies.json"))
+	_, missingErr := New(WithCookieFile(dir, "missing.json"))
+
+	// Assert
+	require.NoError(t, err)
+	cookies := scraper.Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "abc", cookies[0].Value)
+	assert.Error(t, missingErr)
+}
+
+func TestScraper_SaveCookies(t *testing.T) {
+	// Arrange
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "nexusmods_session", Value: "rotated", Path: "/"})
+		w.Write([]byte(modPage))
+	}))
+	defer site.Close()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "session-cookies.json"), []byte(`{"nexusmods_session":"abc","other":"kept"}`), 0644))
+	scraper, err := New(WithBaseURL(site.URL), WithCookieFile(dir, "session-cookies.json"))
+	require.NoError(t, err)
+	withoutFile, err := New()
+	require.NoError(t, err)
+
+	// Act
+	_, err = scraper.ScrapeFiles(context.Background(), "skyrim", 1)
+	require.NoError(t, err)
+	saveErr := scraper.SaveCookies()
+
+	// Assert
+	require.NoError(t, saveErr)
+	reloaded, err := New(WithBaseURL(site.URL), WithCookieFile(dir, "session-cookies.json"))
+	require.NoError(t, err)
+	values := map[string]string{}
+	for _, cookie := range reloaded.Cookies() {
+		values[cookie.Name] = cookie.Value
+	}
+	assert.Equal(t, map[string]string{"nexusmods_session": "rotated", "other": "kept"}, values)
+	assert.ErrorContains(t, withoutFile.SaveCookies(), "no cookie file")
+}
+
+func TestNew_InvalidOptions(t *testing.T) {
+	for name, opt := range map[string]Option{
+		"empty base url":   WithBaseURL(""),
+		"nil client":       WithHTTPClient(nil),
+		"nil logger":       WithLogger(nil),
+		"negative limit":   WithRateLimit(-1),
+		"negative timeout": WithRequestTimeout(-1),
+		"negative workers": WithConcurrency(-1, 0),
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			_, err := New(opt)
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNew_WithHTTPClientAddsJar(t *testing.T) {
+	// Arrange
+	client := &http.Client{}
+
+	// Act
+	scraper, err := New(WithHTTPClient(client))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Same(t, client, scraper.Client())
+	assert.NotNil(t, client.Jar)
+}
+
+// fakeCookieStore is a browser cookie store holding fixed cookies.
+type fakeCookieStore struct {
+	cookies []*kooky.Cookie
+}
+
+func (f *fakeCookieStore) SetCookies(u *url.URL, cookies []*http.Cookie) {}
+func (f *fakeCookieStore) Cookies(u *url.URL) []*http.Cookie             { return nil }
+func (f *fakeCookieStore) SubJar(filters ...kooky.Filter) (http.CookieJar, error) {
+	return nil, nil
+}
+func (f *fakeCookieStore) ReadCookies(filters ...kooky.Filter) ([]*kooky.Cookie, error) {
+	return kooky.FilterCookies(f.cookies, filters...), nil
+}
+func (f *fakeCookieStore) Browser() string        { return "fake" }
+func (f *fakeCookieStore) Profile() string        { return "default" }
+func (f *fakeCookieStore) IsDefaultProfile() bool { return true }
+func (f *fakeCookieStore) FilePath() string       { return "" }
+func (f *fakeCookieStore) Close() error           { return nil }
+
+func TestScraper_ExtractCookies(t *testing.T) {
+	// Arrange
+	store := &fakeCookieStore{cookies: []*kooky.Cookie{
+		{Cookie: http.Cookie{Name: "nexusmods_session", Value: "abc", Domain: ".nexusmods.com", Expires: time.Now().Add(time.Hour)}},
+		{Cookie: http.Cookie{Name: "other", Value: "ignored", Domain: ".nexusmods.com", Expires: time.Now().Add(time.Hour)}},
+	}}
+	scraper, err := New(WithCookieStores(func() []kooky.CookieStore { return []kooky.CookieStore{store} }))
+	require.NoError(t, err)
+
+	// Act
+	extracted, err := scraper.ExtractCookies()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"nexusmods_session": "abc"}, extracted)
+	require.Len(t, scraper.Cookies(), 1)
+	assert.Equal(t, "abc", scraper.Cookies()[0].Value)
+}
+
+func TestScraper_ExtractCookies_BrowserNotFound(t *testing.T) {
+	// Arrange
+	store := &fakeCookieStore{cookies: []*kooky.Cookie{
+		{Cookie: http.Cookie{Name: "nexusmods_session", Value: "abc", Domain: ".nexusmods.com", Expires: time.Now().Add(time.Hour)}},
+	}}
+	stores := func() []kooky.CookieStore { return []kooky.CookieStore{store} }
+	scraper, err := New(WithCookieStores(stores), WithBrowser("firefox"), WithProfile("work"))
+	require.NoError(t, err)
+	matching, err := New(WithCookieStores(stores), WithBrowser("FAKE"))
+	require.NoError(t, err)
+
+	// Act
+	_, err = scraper.ExtractCookies()
+	listed := scraper.CookieStores()
+	matchingListed := matching.CookieStores()
+
+	// Assert
+	assert.EqualError(t, err, "no cookie stores found in browser firefox, profile work")
+	assert.Empty(t, listed)
+	require.Len(t, matchingListed, 1)
+	assert.Equal(t, []string{"nexusmods_session"}, matchingListed[0].Cookies)
+}
+
+func TestScraper_ImportCookies(t *testing.T) {
+	// Arrange
+	scraper, err := New(WithBaseURL("https://nexusmods.com"))
+	require.NoError(t, err)
+
+	// Act
+	imported, err := scraper.ImportCookies([]*http.Cookie{
+		{Name: "nexusmods_session", Value: "abc", Domain: ".nexusmods.com"},
+		{Name: "nexusmods_session_refresh", Value: "def"},
+		{Name: "nexusmods_session", Value: "foreign", Domain: ".example.com"},
+		{Name: "tracking", Value: "ignored", Domain: ".nexusmods.com"},
+	})
+	_, noneErr := scraper.ImportCookies([]*http.Cookie{{Name: "tracking", Value: "ignored"}})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"nexusmods_session": "abc", "nexusmods_session_refresh": "def"}, imported)
+	assert.Len(t, scraper.Cookies(), 2)
+	assert.EqualError(t, noneErr, "no matching cookies found")
+}
@@ -0,0 +1,174 @@
+package nexus
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/browserutils/kooky"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+)
+
+// Option configures a Scraper created with New.
+type Option func(*Scraper) error
+
+// WithBaseURL sets the Nexus Mods site to scrape, defaulting to DefaultBaseURL.
+func WithBaseURL(baseURL string) Option {
+	return func(s *Scraper) error {
+		if baseURL == "" {
+			return errors.New("base url must not be empty")
+		}
+		s.baseURL = baseURL
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for every request. A cookie jar is
+// added when the client has none.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Scraper) error {
+		if client == nil {
+			return errors.New("http client must not be nil")
+		}
+		s.client = client
+		return nil
+	}
+}
+
+// WithCookies sets the session cookies sent to the base URL.
+func WithCookies(cookies ...*http.Cookie) Option {
+	return func(s *Scraper) error {
+		s.cookies = append(s.cookies, cookies...)
+		return nil
+	}
+}
+
+// WithCookieFile loads the session cookies saved by the extract command from the
+// file in dir.
+func WithCookieFile(dir, filename string) Option {
+	return func(s *Scraper) error {
+		cookies, err := httpclient.LoadCookies(dir, filename)
+		if err != nil {
+			return err
+		}
+		s.cookies = append(s.cookies, cookies...)
+		s.cookieDir, s.cookieFile = dir, filename
+		return nil
+	}
+}
+
+// WithValidCookies sets the cookie names ExtractCookies reads from browsers,
+// defaulting to DefaultValidCookies.
+func WithValidCookies(names ...string) Option {
+	return func(s *Scraper) error {
+		s.validCookies = names
+		return nil
+	}
+}
+
+// WithCookieStores sets the function listing the browser cookie stores used by
+// ExtractCookies, defaulting to every store kooky can find.
+func WithCookieStores(storeProvider func() []kooky.CookieStore) Option {
+	return func(s *Scraper) error {
+		s.cookieStores = storeProvider
+		return nil
+	}
+}
+
+// WithBrowser limits ExtractCookies to the cookie stores of the browser, such as
+// "firefox", "chrome" or "edge", ignoring case.
+func WithBrowser(browser string) Option {
+	return func(s *Scraper) error {
+		s.browser = browser
+		return nil
+	}
+}
+
+// WithProfile limits ExtractCookies to the cookie stores of the browser profile with
+// the name, ignoring case.
+func WithProfile(profile string) Option {
+	return func(s *Scraper) error {
+		s.profile = profile
+		return nil
+	}
+}
+
+// WithLogger sets the structured logger, by default nothing is logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Scraper) error {
+		if logger == nil {
+			return errors.New("logger must not be nil")
+		}
+		s.logger = logger
+		return nil
+	}
+}
+
+// WithRateLimit sets the minimum time between page requests, 0 disables the limit.
+func WithRateLimit(interval time.Duration) Option {
+	return func(s *Scraper) error {
+		if interval < 0 {
+			return errors.New("rate limit interval must not be negative")
+		}
+		s.limiter = newLimiter(interval)
+		return nil
+	}
+}
+
+// WithConcurrency sets how many of a mod's pages ScrapeMod requests at once, 0 for
+// the default, and limits the requests at once to the base URL's host to perHost,
+// 0 for no limit.
+func WithConcurrency(workers, perHost int) Option {
+	return func(s *Scraper) error {
+		if workers < 0 || perHost < 0 {
+			return errors.New("concurrency must not be negative")
+		}
+		s.concurrency = workers
+		s.perHost = perHost
+		return nil
+	}
+}
+
+// WithRequestTimeout sets how long a single page request may take, including
+// reading its body, 0 means no limit.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(s *Scraper) error {
+		if timeout < 0 {
+			return errors.New("request timeout must not be negative")
+		}
+		s.requestTimeout = timeout
+		return nil
+	}
+}
+
+// WithSessionRefresh renews the session with the refresh cookie when it expires, at
+// refreshPath on the base URL or DefaultRefreshPath when empty. The renewed cookies
+// are passed to onRefresh, or when it is nil saved back to the file loaded with
+// WithCookieFile.
+func WithSessionRefresh(refreshPath string, onRefresh func(cookies []*http.Cookie) error) Option {
+	return func(s *Scraper) error {
+		s.refreshPath = refreshPath
+		s.onRefresh = onRefresh
+		s.refreshSession = true
+		return nil
+	}
+}
+
+// WithDocumentFetcher replaces how pages are fetched, for example to read saved
+// HTML instead of making requests. Rate limiting still applies.
+func WithDocumentFetcher(fetch DocumentFetcher) Option {
+	return func(s *Scraper) error {
+		s.fetchDocument = fetch
+		return nil
+	}
+}
+
+// WithModFetcher replaces the scraping pipeline used by ScrapeMod.
+func WithModFetcher(fetch ModFetcher) Option {
+	return func(s *Scraper) error {
+		s.fetchMod = fetch
+		return nil
+	}
+}
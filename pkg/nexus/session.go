@@ -0,0 +1,158 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/extractors"
+)
+
+// SessionPath is the page loaded to check the session. It is small and only shown
+// to logged-in accounts, anonymous visitors are sent to sign in.
+const SessionPath = "/users/myaccount"
+
+// DefaultRefreshPath is the endpoint WithSessionRefresh exchanges the refresh cookie
+// at for a new session when no other path is given.
+const DefaultRefreshPath = httpclient.DefaultRefreshPath
+
+// ErrNotLoggedIn is returned when the session cookies do not log in to an account.
+var ErrNotLoggedIn = errors.New("not logged in, the session cookies are missing, invalid or expired; run extract to refresh them")
+
+// Account is the Nexus Mods account a session is logged in to.
+type Account = types.Account
+
+// CookieStatus is a cookie and when it expires, zero when unknown. Session marks the
+// scraper's valid session cookies, set with WithValidCookies, which are the only
+// cookies Validate checks.
+type CookieStatus struct {
+	Name    string    `json:"name"`
+	Expires time.Time `json:"expires,omitempty"`
+	Session bool      `json:"session"`
+}
+
+// Expired reports whether the cookie had expired at now.
+func (c CookieStatus) Expired(now time.Time) bool {
+	return !c.Expires.IsZero() && now.After(c.Expires)
+}
+
+// Session is the login state of a scraper's session cookies.
+type Session struct {
+	LoggedIn bool           `json:"loggedIn"`
+	Account  Account        `json:"account"`
+	Cookies  []CookieStatus `json:"cookies"`
+}
+
+// Validate returns an error describing why the session cannot be used to scrape,
+// either because a session cookie had expired at now or because it is not logged in.
+// Other cookies, such as tracking ones, are not checked.
+func (s Session) Validate(now time.Time) error {
+	for _, cookie := range s.Cookies {
+		if cookie.Session && cookie.Expired(now) {
+			return fmt.Errorf("session cookie %s expired at %s, run extract to refresh it", cookie.Name, cookie.Expires.Format(time.RFC3339))
+		}
+	}
+	if !s.LoggedIn {
+		return ErrNotLoggedIn
+	}
+	return nil
+}
+
+// CheckSession loads the account page to find whether the session cookies are logged
+// in, and to which account. A session that is refused or sent to sign in is reported
+// as logged out rather than as an error. Returns an error if the page cannot be
+// fetched for another reason or ctx is cancelled. The cookies are listed after the
+// page is loaded, so cookies renewed by a session refresh are the ones shown.
+func (s *Scraper) CheckSession(ctx context.Context) (Session, error) {
+	session, err := s.checkAccount(ctx)
+	session.Cookies = s.cookieStatuses()
+	return session, err
+}
+
+// checkAccount loads the account page and returns the account the session is logged
+// in to, without its cookies.
+func (s *Scraper) checkAccount(ctx context.Context) (Session, error) {
+	session := Session{}
+
+	doc, err := s.fetch(ctx, strings.TrimRight(s.baseURL, "/")+SessionPath)
+	if err != nil {
+		var statusErr *fetchers.StatusError
+		if errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden) {
+			return session, nil
+		}
+		return session, err
+	}
+	if doc.Url != nil && s.isSignInPage(doc.Url) {
+		return session, nil
+	}
+
+	session.Account = extractors.ExtractAccount(doc)
+	session.LoggedIn = session.Account.Username != ""
+	s.logger.Debug("checked session", "logged_in", session.LoggedIn, "premium", session.Account.Premium)
+
+	return session, nil
+}
+
+// isSignInPage reports whether a request was redirected away from the site or to a
+// sign in page.
+func (s *Scraper) isSignInPage(u *url.URL) bool {
+	base, err := url.Parse(s.baseURL)
+	if err != nil {
+		return false
+	}
+
+	path := strings.ToLower(u.Path)
+	return !strings.EqualFold(strings.TrimPrefix(u.Hostname(), "www."), strings.TrimPrefix(base.Hostname(), "www.")) ||
+		strings.Contains(path, "login") || strings.Contains(path, "sign_in")
+}
+
+// cookieStatuses lists the session cookies with their expiry, taken from the cookie
+// or, for token cookies, from the token's expiry claim. The cookies in the jar come
+// first, as they hold any renewed session, followed by the given cookies the jar
+// no longer sends, such as expired ones.
+func (s *Scraper) cookieStatuses() []CookieStatus {
+	statuses := []CookieStatus{}
+	seen := map[string]bool{}
+
+	// The jar only returns names and values, so the expiry comes from its stored copy
+	stored := append(s.StoredCookies(), s.cookies...)
+	for _, cookie := range s.Cookies() {
+		if seen[cookie.Name] {
+			continue
+		}
+		expires := time.Time{}
+		for _, attributes := range stored {
+			if attributes.Name == cookie.Name && attributes.Value == cookie.Value && !attributes.Expires.IsZero() {
+				expires = attributes.Expires
+				break
+			}
+		}
+		statuses = append(statuses, s.cookieStatus(cookie.Name, cookie.Value, expires))
+		seen[cookie.Name] = true
+	}
+	for _, cookie := range s.cookies {
+		if !seen[cookie.Name] {
+			statuses = append(statuses, s.cookieStatus(cookie.Name, cookie.Value, cookie.Expires))
+			seen[cookie.Name] = true
+		}
+	}
+
+	return statuses
+}
+
+// cookieStatus returns the status of a cookie, taking the expiry from the token's
+// expiry claim when the cookie has none, and marking the valid session cookies.
+func (s *Scraper) cookieStatus(name, value string, expires time.Time) CookieStatus {
+	if expires.IsZero() {
+		expires = httpclient.TokenExpiry(value)
+	}
+	return CookieStatus{Name: name, Expires: expires, Session: slices.Contains(s.validCookies, name)}
+}
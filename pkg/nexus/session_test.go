@@ -0,0 +1,198 @@
+package nexus
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const accountPage = `<html><body><div id="user-dropdown"><span class="username">Dovahkiin</span><span class="premium">Premium</span></div></body></html>`
+
+// newAccountSite serves the account page to the session with the given cookie value,
+// and otherwise responds as anonymous visitors are, with the given status.
+func newAccountSite(t *testing.T, session string, anonymous func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			w.Write([]byte(`<html><body><a id="login">Log in</a></body></html>`))
+			return
+		}
+		if cookie, err := r.Cookie("nexusmods_session"); err == nil && cookie.Value == session && r.URL.Path == SessionPath {
+			w.Write([]byte(accountPage))
+			return
+		}
+		anonymous(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// token returns a JSON web token expiring at expires.
+func token(expires time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"sub":"1","exp":%d}`, expires.Unix())))
+	return "eyJhbGciOiJIUzI1NiJ9." + payload + ".c2lnbmF0dXJl"
+}
+
+func TestScraper_CheckSession_LoggedIn(t *testing.T) {
+	// Arrange
+	site := newAccountSite(t, "abc", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusForbidden) })
+	expires := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	scraper, err := New(WithBaseURL(site.URL), WithCookies(
+		&http.Cookie{Name: "nexusmods_session", Value: "abc", Expires: expires},
+		&http.Cookie{Name: "nexusmods_session_refresh", Value: token(expires)},
+	))
+	require.NoError(t, err)
+
+	// Act
+	session, err := scraper.CheckSession(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, session.LoggedIn)
+	assert.Equal(t, Account{Username: "Dovahkiin", Premium: true}, session.Account)
+	require.Len(t, session.Cookies, 2)
+	assert.True(t, expires.Equal(session.Cookies[0].Expires))
+	assert.True(t, expires.Equal(session.Cookies[1].Expires))
+	assert.NoError(t, session.Validate(time.Now()))
+}
+
+func TestScraper_CheckSession_Anonymous(t *testing.T) {
+	tests := map[string]func(w http.ResponseWriter, r *http.Request){
+		"forbidden":        func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusForbidden) },
+		"sent to sign in":  func(w http.ResponseWriter, r *http.Request) { http.Redirect(w, r, "/login", http.StatusFound) },
+		"no account shown": func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(`<html><body></body></html>`)) },
+	}
+
+	for name, anonymous := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			site := newAccountSite(t, "abc", anonymous)
+			scraper, err := New(WithBaseURL(site.URL), WithCookies(&http.Cookie{Name: "nexusmods_session", Value: "stale"}))
+			require.NoError(t, err)
+
+			// Act
+			session, err := scraper.CheckSession(context.Background())
+
+			// Assert
+			require.NoError(t, err)
+			assert.False(t, session.LoggedIn)
+			assert.ErrorIs(t, session.Validate(time.Now()), ErrNotLoggedIn)
+		})
+	}
+}
+
+func TestScraper_CheckSession_ServerError(t *testing.T) {
+	// Arrange
+	site := newAccountSite(t, "abc", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusInternalServerError) })
+	scraper, err := New(WithBaseURL(site.URL))
+	require.NoError(t, err)
+
+	// Act
+	_, err = scraper.CheckSession(context.Background())
+
+	// Assert
+	assert.ErrorContains(t, err, "returned 500")
+}
+
+func TestScraper_WithSessionRefresh_SavesRenewedCookies(t *testing.T) {
+	// Arrange
+	site := newAccountSite(t, "fresh", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/users/session/refresh" {
+			http.SetCookie(w, &http.Cookie{Name: "nexusmods_session", Value: "fresh"})
+			return
+		}
+		http.Redirect(w, r, "/login", http.StatusFound)
+	})
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "session-cookies.json"), []byte(`{"nexusmods_session":"stale","nexusmods_session_refresh":"refresh"}`), 0644))
+	scraper, err := New(WithBaseURL(site.URL), WithCookieFile(dir, "session-cookies.json"), WithSessionRefresh("", nil))
+	require.NoError(t, err)
+
+	// Act
+	session, err := scraper.CheckSession(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, session.LoggedIn)
+	data, err := os.ReadFile(filepath.Join(dir, "session-cookies.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":2,"cookies":[
+		{"name":"nexusmods_session","value":"fresh","domain":"127.0.0.1","path":"/"},
+		{"name":"nexusmods_session_refresh","value":"refresh","domain":"127.0.0.1","path":"/"}
+	]}`, string(data))
+}
+
+func TestScraper_CheckSession_ShowsRefreshedCookies(t *testing.T) {
+	// Arrange
+	now := time.Now().Truncate(time.Second)
+	renewed := now.Add(48 * time.Hour)
+	site := newAccountSite(t, "fresh", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/users/session/refresh" {
+			http.SetCookie(w, &http.Cookie{Name: "nexusmods_session", Value: "fresh", Path: "/", Expires: renewed})
+			return
+		}
+		http.Redirect(w, r, "/login", http.StatusFound)
+	})
+	scraper, err := New(WithBaseURL(site.URL), WithSessionRefresh("", func(cookies []*http.Cookie) error { return nil }), WithCookies(
+		&http.Cookie{Name: "nexusmods_session", Value: "stale", Expires: now.Add(time.Hour)},
+		&http.Cookie{Name: "nexusmods_session_refresh", Value: "refresh", Expires: renewed},
+	))
+	require.NoError(t, err)
+
+	// Act
+	session, err := scraper.CheckSession(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, session.LoggedIn)
+	require.Len(t, session.Cookies, 2)
+	for _, cookie := range session.Cookies {
+		assert.True(t, renewed.Equal(cookie.Expires), cookie.Name)
+	}
+	assert.NoError(t, session.Validate(now.Add(2*time.Hour)))
+}
+
+func TestSession_Validate_ExpiredCookie(t *testing.T) {
+	// Arrange
+	expired := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	session := Session{
+		LoggedIn: true,
+		Cookies:  []CookieStatus{{Name: "nexusmods_session", Session: true}, {Name: "nexusmods_session_refresh", Expires: expired, Session: true}},
+	}
+
+	// Act
+	err := session.Validate(expired.Add(time.Minute))
+
+	// Assert
+	assert.EqualError(t, err, "session cookie nexusmods_session_refresh expired at 2024-01-01T00:00:00Z, run extract to refresh it")
+}
+
+func TestScraper_CheckSession_IgnoresExpiredTrackingCookie(t *testing.T) {
+	// Arrange
+	now := time.Now().Truncate(time.Second)
+	site := newAccountSite(t, "abc", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusForbidden) })
+	scraper, err := New(WithBaseURL(site.URL), WithCookies(
+		&http.Cookie{Name: "nexusmods_session", Value: "abc", Expires: now.Add(24 * time.Hour)},
+		&http.Cookie{Name: "_ga", Value: "tracking", Expires: now.Add(time.Hour)},
+	))
+	require.NoError(t, err)
+
+	// Act
+	session, err := scraper.CheckSession(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, session.Cookies, 2)
+	assert.True(t, session.Cookies[0].Session)
+	assert.False(t, session.Cookies[1].Session)
+	assert.NoError(t, session.Validate(now.Add(2*time.Hour)))
+	assert.Error(t, session.Validate(now.Add(48*time.Hour)))
+}